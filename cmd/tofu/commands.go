@@ -147,6 +147,12 @@ func initCommands(
 			}, nil
 		},
 
+		"convert": func() (cli.Command, error) {
+			return &command.ConvertCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"destroy": func() (cli.Command, error) {
 			return &command.ApplyCommand{
 				Meta:    meta,
@@ -448,6 +454,34 @@ func initCommands(
 			}, nil
 		},
 
+		"state apply-moves": func() (cli.Command, error) {
+			return &command.StateApplyMovesCommand{
+				StateMeta: command.StateMeta{
+					Meta: meta,
+				},
+			}, nil
+		},
+
+		"state diff": func() (cli.Command, error) {
+			return &command.StateDiffCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"state history": func() (cli.Command, error) {
+			return &command.StateHistoryCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"state rollback": func() (cli.Command, error) {
+			return &command.StateRollbackCommand{
+				StateMeta: command.StateMeta{
+					Meta: meta,
+				},
+			}, nil
+		},
+
 		"state replace-provider": func() (cli.Command, error) {
 			return &command.StateReplaceProviderCommand{
 				StateMeta: command.StateMeta{
@@ -30,6 +30,7 @@ func TestInit_backend(t *testing.T) {
 		{"inmem", "*inmem.Backend", "inmem"},
 		{"pg", "*pg.Backend", "pg"},
 		{"s3", "*s3.Backend", "s3"},
+		{"sqlite", "*sqlite.Backend", "sqlite"},
 	}
 
 	// Make sure we get the requested backend
@@ -259,6 +259,9 @@ func maybeWriteGeneratedConfig(plan *plans.Plan, out string) (wroteConfig bool,
 				return false, diags.Append(moreDiags)
 			}
 		}
+		if moreDiags := genconfig.CloseConfigWriter(writer, out); moreDiags.HasErrors() {
+			return false, diags.Append(moreDiags)
+		}
 	}
 
 	if wroteConfig {
@@ -13,12 +13,14 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/lease"
 	"github.com/hashicorp/go-uuid"
 	"github.com/opentofu/opentofu/internal/states/remote"
@@ -29,11 +31,22 @@ const (
 	lockInfoMetaKey = "terraformlockid"
 )
 
+const (
+	versionIDPrefix  = "version:"
+	snapshotIDPrefix = "snapshot:"
+	currentID        = "current"
+)
+
 type RemoteClient struct {
 	blobClient *blockblob.Client
 	leaseID    *string
 	snapshot   bool
 	timeout    time.Duration
+
+	// containerClient and blobName are used to list the versions and
+	// snapshots of the blob.
+	containerClient azureClient
+	blobName        string
 }
 
 func (c *RemoteClient) Get(ctx context.Context) (*remote.Payload, error) {
@@ -68,6 +81,110 @@ func (c *RemoteClient) Get(ctx context.Context) (*remote.Payload, error) {
 	return payload, nil
 }
 
+// Versions is part of our implementation of remote.ClientVersioner. It
+// returns the blob versions retained when versioning is enabled on the storage
+// account, along with the blob snapshots created when the backend's
+// "snapshot" argument is set.
+func (c *RemoteClient) Versions(ctx context.Context, limit int) ([]*remote.PayloadVersion, error) {
+	ctx, ctxCancel := c.getContextWithTimeout(ctx)
+	defer ctxCancel()
+	versions, err := blobVersions(ctx, c.containerClient, c.blobName)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(versions) > limit {
+		versions = versions[:limit]
+	}
+	return versions, nil
+}
+
+// GetVersion is part of our implementation of remote.ClientVersioner.
+func (c *RemoteClient) GetVersion(ctx context.Context, id string) (*remote.Payload, error) {
+	var blobClient *blockblob.Client
+	var err error
+	switch {
+	case id == currentID:
+		return c.Get(ctx)
+	case strings.HasPrefix(id, versionIDPrefix):
+		blobClient, err = c.blobClient.WithVersionID(strings.TrimPrefix(id, versionIDPrefix))
+	case strings.HasPrefix(id, snapshotIDPrefix):
+		blobClient, err = c.blobClient.WithSnapshot(strings.TrimPrefix(id, snapshotIDPrefix))
+	default:
+		return nil, fmt.Errorf("invalid state version %q", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, ctxCancel := c.getContextWithTimeout(ctx)
+	defer ctxCancel()
+	resp, err := blobClient.DownloadStream(ctx, nil)
+	if err != nil {
+		if notFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error downloading azure blob %s: %w", id, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading azure blob %s: %w", id, err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return &remote.Payload{Data: data}, nil
+}
+
+// blobVersions lists the versions and snapshots of the named blob, newest
+// first.
+func blobVersions(ctx context.Context, client azureClient, name string) ([]*remote.PayloadVersion, error) {
+	pager := client.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{
+		Prefix: &name,
+		Include: container.ListBlobsInclude{
+			Snapshots: true,
+			Versions:  true,
+		},
+	})
+
+	var ret []*remote.PayloadVersion
+	for pager.More() {
+		resp, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing blob versions: %w", err)
+		}
+
+		for _, item := range resp.Segment.BlobItems {
+			if item.Name == nil || *item.Name != name || (item.Deleted != nil && *item.Deleted) {
+				continue
+			}
+
+			var id string
+			switch {
+			case item.Snapshot != nil && *item.Snapshot != "":
+				id = snapshotIDPrefix + *item.Snapshot
+			case item.VersionID != nil && *item.VersionID != "":
+				id = versionIDPrefix + *item.VersionID
+			default:
+				// Without versioning the base blob has no version ID.
+				id = currentID
+			}
+
+			version := &remote.PayloadVersion{ID: id}
+			if item.Properties != nil && item.Properties.LastModified != nil {
+				version.Time = *item.Properties.LastModified
+			}
+			ret = append(ret, version)
+		}
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Time.After(ret[j].Time)
+	})
+	return ret, nil
+}
+
 func (c *RemoteClient) Put(ctx context.Context, data []byte) error {
 	ctx, ctxCancel := c.getContextWithTimeout(ctx)
 	defer ctxCancel()
@@ -6,12 +6,16 @@
 package azure
 
 import (
+	"context"
 	"errors"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/google/go-cmp/cmp"
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/backend/remote-state/azure/auth"
 	"github.com/opentofu/opentofu/internal/encryption"
@@ -22,6 +26,61 @@ import (
 func TestRemoteClient_impl(t *testing.T) {
 	var _ remote.Client = new(RemoteClient)
 	var _ remote.ClientLocker = new(RemoteClient)
+	var _ remote.ClientVersioner = new(RemoteClient)
+}
+
+type mockVersionsClient struct {
+	items []*container.BlobItem
+}
+
+func (c mockVersionsClient) NewListBlobsFlatPager(*container.ListBlobsFlatOptions) *runtime.Pager[container.ListBlobsFlatResponse] {
+	return runtime.NewPager(runtime.PagingHandler[container.ListBlobsFlatResponse]{
+		More: func(container.ListBlobsFlatResponse) bool {
+			return false
+		},
+		Fetcher: func(context.Context, *container.ListBlobsFlatResponse) (container.ListBlobsFlatResponse, error) {
+			return container.ListBlobsFlatResponse{
+				ListBlobsFlatSegmentResponse: container.ListBlobsFlatSegmentResponse{
+					Segment: &container.BlobFlatListSegment{BlobItems: c.items},
+				},
+			}, nil
+		},
+	})
+}
+
+func TestBlobVersions(t *testing.T) {
+	now := time.Now()
+	item := func(name, snapshot, versionID string, age time.Duration) *container.BlobItem {
+		modified := now.Add(-age)
+		return &container.BlobItem{
+			Name:       &name,
+			Snapshot:   &snapshot,
+			VersionID:  &versionID,
+			Properties: &container.BlobProperties{LastModified: &modified},
+		}
+	}
+	client := mockVersionsClient{
+		items: []*container.BlobItem{
+			item("state.tfstate", "", "v1", 2*time.Minute),
+			item("state.tfstate", "", "v2", time.Minute),
+			item("state.tfstate", "s1", "", 3*time.Minute),
+			item("state.tfstate.other", "", "v3", 0),
+			item("state.tfstate", "", "", 0),
+		},
+	}
+
+	versions, err := blobVersions(t.Context(), client, "state.tfstate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range versions {
+		got = append(got, v.ID)
+	}
+	want := []string{"current", "version:v2", "version:v1", "snapshot:s1"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong versions\n%s", diff)
+	}
 }
 
 func TestPutMaintainsMetadata(t *testing.T) {
@@ -115,6 +115,33 @@ func TestRemoteClientWithEncryption(t *testing.T) {
 	remote.TestClient(t, rs.Client)
 }
 
+func TestRemoteClientVersions(t *testing.T) {
+	t.Parallel()
+
+	bucket := bucketName(t)
+	be := setupBackend(t, bucket, noPrefix, noEncryptionKey, noKmsKeyName)
+	defer teardownBackend(t, be, noPrefix)
+
+	_, err := be.(*Backend).storageClient.Bucket(bucket).Update(t.Context(), storage.BucketAttrsToUpdate{
+		VersioningEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("enabling versioning on %q failed: %v", bucket, err)
+	}
+
+	ss, err := be.StateMgr(t.Context(), backend.DefaultStateName)
+	if err != nil {
+		t.Fatalf("be.StateMgr(%q) = %v", backend.DefaultStateName, err)
+	}
+
+	rs, ok := ss.(*remote.State)
+	if !ok {
+		t.Fatalf("be.StateMgr(): got a %T, want a *remote.State", ss)
+	}
+
+	remote.TestClientVersions(t, rs.Client.(remote.ClientVersioner))
+}
+
 func TestRemoteLocks(t *testing.T) {
 	t.Parallel()
 
@@ -388,10 +415,10 @@ func teardownBackend(t *testing.T, be backend.Backend, prefix string) {
 	ctx := t.Context()
 
 	bucket := gcsBE.storageClient.Bucket(gcsBE.bucketName)
-	objs := bucket.Objects(ctx, nil)
+	objs := bucket.Objects(ctx, &storage.Query{Versions: true})
 
 	for o, err := objs.Next(); err == nil; o, err = objs.Next() {
-		if err := bucket.Object(o.Name).Delete(ctx); err != nil {
+		if err := bucket.Object(o.Name).Generation(o.Generation).Delete(ctx); err != nil {
 			log.Printf("Error trying to delete object: %s %s\n\n", o.Name, err)
 		} else {
 			log.Printf("Object deleted: %s", o.Name)
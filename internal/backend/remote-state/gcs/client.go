@@ -11,12 +11,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 
 	"cloud.google.com/go/storage"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/opentofu/opentofu/internal/states/remote"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
+	"google.golang.org/api/iterator"
 )
 
 // remoteClient is used by "state/remote".State to read and write
@@ -60,6 +62,69 @@ func (c *remoteClient) Get(ctx context.Context) (payload *remote.Payload, err er
 	return result, nil
 }
 
+// Versions is part of our implementation of remote.ClientVersioner. The
+// noncurrent versions are only retained when object versioning is enabled on
+// the bucket; the version IDs are the object generation numbers.
+func (c *remoteClient) Versions(ctx context.Context, limit int) ([]*remote.PayloadVersion, error) {
+	var found []*storage.ObjectAttrs
+	objs := c.storageClient.Bucket(c.bucketName).Objects(ctx, &storage.Query{
+		Prefix:   c.stateFilePath,
+		Versions: true,
+	})
+	for {
+		attrs, err := objs.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list versions of state file %v: %w", c.stateFileURL(), err)
+		}
+		if attrs.Name == c.stateFilePath {
+			found = append(found, attrs)
+		}
+	}
+
+	// Generation numbers increase with each write, so we can order by them.
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Generation > found[j].Generation
+	})
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+
+	ret := make([]*remote.PayloadVersion, len(found))
+	for i, attrs := range found {
+		ret[i] = &remote.PayloadVersion{
+			ID:   strconv.FormatInt(attrs.Generation, 10),
+			Time: attrs.Created,
+		}
+	}
+	return ret, nil
+}
+
+// GetVersion is part of our implementation of remote.ClientVersioner.
+func (c *remoteClient) GetVersion(ctx context.Context, id string) (*remote.Payload, error) {
+	gen, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("State version should be an object generation number, got '%s'", id)
+	}
+
+	r, err := c.stateFile().Generation(gen).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Failed to open state file at %v#%d: %w", c.stateFileURL(), gen, err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read state file from %v#%d: %w", c.stateFileURL(), gen, err)
+	}
+	return &remote.Payload{Data: data}, nil
+}
+
 func (c *remoteClient) Put(ctx context.Context, data []byte) error {
 	err := func() error {
 		stateFileWriter := c.stateFile().NewWriter(ctx)
@@ -15,6 +15,8 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -24,6 +26,7 @@ import (
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/legacy/helper/schema"
 	"github.com/opentofu/opentofu/internal/logging"
+	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/remote"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
 )
@@ -121,6 +124,30 @@ func New(enc encryption.StateEncryption) backend.Backend {
 				DefaultFunc: schema.EnvDefaultFunc("TF_HTTP_CLIENT_PRIVATE_KEY_PEM", ""),
 				Description: "A PEM-encoded private key, required if client_certificate_pem is specified.",
 			},
+			"workspace_address": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TF_HTTP_WORKSPACE_ADDRESS", nil),
+				Description: "The address of the REST endpoint for non-default workspaces, with {workspace} in place of the workspace name",
+			},
+			"workspace_lock_address": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TF_HTTP_WORKSPACE_LOCK_ADDRESS", nil),
+				Description: "The address of the lock REST endpoint for non-default workspaces, with {workspace} in place of the workspace name",
+			},
+			"workspace_unlock_address": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TF_HTTP_WORKSPACE_UNLOCK_ADDRESS", nil),
+				Description: "The address of the unlock REST endpoint for non-default workspaces, with {workspace} in place of the workspace name",
+			},
+			"workspaces_address": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TF_HTTP_WORKSPACES_ADDRESS", nil),
+				Description: "The address of the REST endpoint that lists the non-default workspaces as a JSON array of names",
+			},
 			"headers": &schema.Schema{
 				Type:     schema.TypeMap,
 				Elem:     &schema.Schema{Type: schema.TypeString},
@@ -160,8 +187,19 @@ type Backend struct {
 	encryption encryption.StateEncryption
 
 	client *httpClient
+
+	// The workspace address templates are only set when the server supports
+	// workspaces, in which case workspacesURL is also set.
+	workspaceAddress       string
+	workspaceLockAddress   string
+	workspaceUnlockAddress string
+	workspacesURL          *url.URL
 }
 
+// workspacePlaceholder is replaced by the workspace name in the workspace
+// address templates.
+const workspacePlaceholder = "{workspace}"
+
 // configureTLS configures TLS when needed; if there are no conditions requiring TLS, no change is made.
 func (b *Backend) configureTLS(client *retryablehttp.Client, data *schema.ResourceData) error {
 	// If there are no conditions needing to configure TLS, leave the client untouched
@@ -248,6 +286,10 @@ func (b *Backend) configure(ctx context.Context) error {
 
 	unlockMethod := data.Get("unlock_method").(string)
 
+	if err := b.configureWorkspaces(data); err != nil {
+		return err
+	}
+
 	username := data.Get("username").(string)
 	password := data.Get("password").(string)
 
@@ -303,18 +345,218 @@ func (b *Backend) configure(ctx context.Context) error {
 	return nil
 }
 
-func (b *Backend) StateMgr(_ context.Context, name string) (statemgr.Full, error) {
-	if name != backend.DefaultStateName {
+// configureWorkspaces configures the optional workspace protocol, in which
+// the state of each non-default workspace is stored at an address derived
+// from a template, and the server lists the workspaces that exist.
+func (b *Backend) configureWorkspaces(data *schema.ResourceData) error {
+	workspaceAddress := data.Get("workspace_address").(string)
+	workspaceLockAddress := data.Get("workspace_lock_address").(string)
+	workspaceUnlockAddress := data.Get("workspace_unlock_address").(string)
+	workspacesAddress := data.Get("workspaces_address").(string)
+
+	if workspaceAddress == "" {
+		if workspaceLockAddress != "" || workspaceUnlockAddress != "" || workspacesAddress != "" {
+			return fmt.Errorf("workspace_lock_address, workspace_unlock_address and workspaces_address require workspace_address to be set")
+		}
+		return nil
+	}
+	if workspacesAddress == "" {
+		return fmt.Errorf("workspaces_address must be set when workspace_address is set")
+	}
+
+	// Each workspace is locked separately, so the workspaces must be locked
+	// whenever the default workspace is, rather than silently not at all.
+	if data.Get("lock_address").(string) != "" && workspaceLockAddress == "" {
+		return fmt.Errorf("workspace_lock_address must be set when lock_address and workspace_address are set")
+	}
+	if data.Get("unlock_address").(string) != "" && workspaceUnlockAddress == "" {
+		return fmt.Errorf("workspace_unlock_address must be set when unlock_address and workspace_address are set")
+	}
+
+	for name, template := range map[string]string{
+		"workspace_address":        workspaceAddress,
+		"workspace_lock_address":   workspaceLockAddress,
+		"workspace_unlock_address": workspaceUnlockAddress,
+	} {
+		if template == "" {
+			continue
+		}
+		if !strings.Contains(template, workspacePlaceholder) {
+			return fmt.Errorf("%s must contain %s", name, workspacePlaceholder)
+		}
+		if _, err := workspaceURL(template, backend.DefaultStateName); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	workspacesURL, err := url.Parse(workspacesAddress)
+	if err != nil {
+		return fmt.Errorf("failed to parse workspacesAddress URL: %w", err)
+	}
+	if workspacesURL.Scheme != "http" && workspacesURL.Scheme != "https" {
+		return fmt.Errorf("workspacesAddress must be HTTP or HTTPS")
+	}
+
+	b.workspaceAddress = workspaceAddress
+	b.workspaceLockAddress = workspaceLockAddress
+	b.workspaceUnlockAddress = workspaceUnlockAddress
+	b.workspacesURL = workspacesURL
+	return nil
+}
+
+// workspaceURL returns the URL for the given workspace from an address
+// template.
+func workspaceURL(template string, name string) (*url.URL, error) {
+	u, err := url.Parse(strings.ReplaceAll(template, workspacePlaceholder, url.PathEscape(name)))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("address must be HTTP or HTTPS")
+	}
+	return u, nil
+}
+
+// workspaceClient returns a client for the state of the given non-default
+// workspace. Each workspace has its own client so that locks are held
+// separately for each of them.
+func (b *Backend) workspaceClient(name string) (*httpClient, error) {
+	client := &httpClient{
+		UpdateMethod: b.client.UpdateMethod,
+		LockMethod:   b.client.LockMethod,
+		UnlockMethod: b.client.UnlockMethod,
+
+		Headers:  b.client.Headers,
+		Username: b.client.Username,
+		Password: b.client.Password,
+
+		Client: b.client.Client,
+	}
+
+	var err error
+	if client.URL, err = workspaceURL(b.workspaceAddress, name); err != nil {
+		return nil, err
+	}
+	if b.workspaceLockAddress != "" {
+		if client.LockURL, err = workspaceURL(b.workspaceLockAddress, name); err != nil {
+			return nil, err
+		}
+	}
+	if b.workspaceUnlockAddress != "" {
+		if client.UnlockURL, err = workspaceURL(b.workspaceUnlockAddress, name); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+func (b *Backend) StateMgr(ctx context.Context, name string) (statemgr.Full, error) {
+	if name == backend.DefaultStateName {
+		return remote.NewState(b.client, b.encryption), nil
+	}
+	if b.workspacesURL == nil {
 		return nil, backend.ErrWorkspacesNotSupported
 	}
 
-	return remote.NewState(b.client, b.encryption), nil
+	client, err := b.workspaceClient(name)
+	if err != nil {
+		return nil, err
+	}
+	stateMgr := remote.NewState(client, b.encryption)
+
+	// Check to see if this state already exists.
+	// If the state doesn't exist, we have to assume this
+	// is a normal create operation, and take the lock at that point.
+	existing, err := b.Workspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(existing, name) {
+		return stateMgr, nil
+	}
+
+	// Grab a lock, we use this to write an empty state if one doesn't
+	// exist already. We have to write an empty state as a sentinel value
+	// so the server lists the workspace.
+	lockInfo := statemgr.NewLockInfo()
+	lockInfo.Operation = "init"
+	lockId, err := stateMgr.Lock(ctx, lockInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock state for workspace %q: %w", name, err)
+	}
+
+	// Local helper function so we can call it multiple places
+	lockUnlock := func(parent error) error {
+		if err := stateMgr.Unlock(ctx, lockId); err != nil {
+			return fmt.Errorf(strings.TrimSpace(errStateUnlock), lockId, err)
+		}
+		return parent
+	}
+
+	if err := stateMgr.RefreshState(ctx); err != nil {
+		return nil, lockUnlock(err)
+	}
+
+	// If we have no state, we have to create an empty state
+	if v := stateMgr.State(); v == nil {
+		if err := stateMgr.WriteState(states.NewState()); err != nil {
+			return nil, lockUnlock(err)
+		}
+		if err := stateMgr.PersistState(ctx, nil); err != nil {
+			return nil, lockUnlock(err)
+		}
+	}
+
+	// Unlock, the state should now be initialized
+	if err := lockUnlock(nil); err != nil {
+		return nil, err
+	}
+
+	return stateMgr, nil
 }
 
-func (b *Backend) Workspaces(context.Context) ([]string, error) {
-	return nil, backend.ErrWorkspacesNotSupported
+func (b *Backend) Workspaces(ctx context.Context) ([]string, error) {
+	if b.workspacesURL == nil {
+		return nil, backend.ErrWorkspacesNotSupported
+	}
+
+	names, err := b.client.Workspaces(ctx, b.workspacesURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// The default workspace always exists, and is always listed first.
+	result := []string{backend.DefaultStateName}
+	for _, name := range names {
+		if name != backend.DefaultStateName && !slices.Contains(result, name) {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result[1:])
+	return result, nil
 }
 
-func (b *Backend) DeleteWorkspace(context.Context, string, bool) error {
-	return backend.ErrWorkspacesNotSupported
+func (b *Backend) DeleteWorkspace(ctx context.Context, name string, _ bool) error {
+	if b.workspacesURL == nil {
+		return backend.ErrWorkspacesNotSupported
+	}
+	if name == backend.DefaultStateName || name == "" {
+		return fmt.Errorf("can't delete default state")
+	}
+
+	client, err := b.workspaceClient(name)
+	if err != nil {
+		return err
+	}
+	return client.Delete(ctx)
 }
+
+const errStateUnlock = `
+Error unlocking HTTP remote state. Lock ID: %s
+
+Error: %w
+
+You may have to force-unlock this state in order to use it again.
+The HTTP backend acquires a lock during initialization of a new workspace
+to ensure that its empty state is created only once.
+`
@@ -6,6 +6,7 @@
 package http
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -184,3 +185,114 @@ func TestHTTPClientFactoryWithEnv(t *testing.T) {
 		t.Fatalf("Expected retry_wait_max \"%s\", got \"%s\"", 150*time.Second, client.Client.RetryWaitMax)
 	}
 }
+
+func TestHTTPClientFactoryWorkspaces(t *testing.T) {
+	conf := map[string]cty.Value{
+		"address":                cty.StringVal("http://127.0.0.1:8888/foo"),
+		"workspace_address":      cty.StringVal("http://127.0.0.1:8888/foo-{workspace}"),
+		"workspace_lock_address": cty.StringVal("http://127.0.0.1:8888/lock/{workspace}"),
+		"workspaces_address":     cty.StringVal("http://127.0.0.1:8888/workspaces"),
+	}
+	b := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), configs.SynthBody("synth", conf)).(*Backend)
+
+	client, err := b.workspaceClient("my workspace")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := client.URL.String(), "http://127.0.0.1:8888/foo-my%20workspace"; got != want {
+		t.Errorf("wrong address %q; want %q", got, want)
+	}
+	if got, want := client.LockURL.String(), "http://127.0.0.1:8888/lock/my%20workspace"; got != want {
+		t.Errorf("wrong lock address %q; want %q", got, want)
+	}
+	if client.UnlockURL != nil {
+		t.Errorf("unexpected unlock address %q", client.UnlockURL)
+	}
+	if client == b.client {
+		t.Error("workspace client must not be shared with the default workspace")
+	}
+
+	// Workspaces are opt-in, so they're not supported without configuration.
+	conf = map[string]cty.Value{
+		"address": cty.StringVal("http://127.0.0.1:8888/foo"),
+	}
+	b = backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), configs.SynthBody("synth", conf)).(*Backend)
+	if _, err := b.Workspaces(t.Context()); err != backend.ErrWorkspacesNotSupported {
+		t.Errorf("expected ErrWorkspacesNotSupported, got %v", err)
+	}
+	if _, err := b.StateMgr(t.Context(), "foo"); err != backend.ErrWorkspacesNotSupported {
+		t.Errorf("expected ErrWorkspacesNotSupported, got %v", err)
+	}
+}
+
+func TestHTTPClientFactoryWorkspacesInvalid(t *testing.T) {
+	tests := map[string]struct {
+		conf    map[string]cty.Value
+		wantErr string
+	}{
+		"missing workspaces_address": {
+			conf: map[string]cty.Value{
+				"address":           cty.StringVal("http://127.0.0.1:8888/foo"),
+				"workspace_address": cty.StringVal("http://127.0.0.1:8888/foo-{workspace}"),
+			},
+			wantErr: "workspaces_address must be set when workspace_address is set",
+		},
+		"missing workspace_address": {
+			conf: map[string]cty.Value{
+				"address":            cty.StringVal("http://127.0.0.1:8888/foo"),
+				"workspaces_address": cty.StringVal("http://127.0.0.1:8888/workspaces"),
+			},
+			wantErr: "workspace_lock_address, workspace_unlock_address and workspaces_address require workspace_address to be set",
+		},
+		"missing workspace_lock_address": {
+			conf: map[string]cty.Value{
+				"address":                  cty.StringVal("http://127.0.0.1:8888/foo"),
+				"lock_address":             cty.StringVal("http://127.0.0.1:8888/foo"),
+				"unlock_address":           cty.StringVal("http://127.0.0.1:8888/foo"),
+				"workspace_address":        cty.StringVal("http://127.0.0.1:8888/foo-{workspace}"),
+				"workspace_unlock_address": cty.StringVal("http://127.0.0.1:8888/foo-{workspace}"),
+				"workspaces_address":       cty.StringVal("http://127.0.0.1:8888/workspaces"),
+			},
+			wantErr: "workspace_lock_address must be set when lock_address and workspace_address are set",
+		},
+		"missing workspace_unlock_address": {
+			conf: map[string]cty.Value{
+				"address":                cty.StringVal("http://127.0.0.1:8888/foo"),
+				"lock_address":           cty.StringVal("http://127.0.0.1:8888/foo"),
+				"unlock_address":         cty.StringVal("http://127.0.0.1:8888/foo"),
+				"workspace_address":      cty.StringVal("http://127.0.0.1:8888/foo-{workspace}"),
+				"workspace_lock_address": cty.StringVal("http://127.0.0.1:8888/foo-{workspace}"),
+				"workspaces_address":     cty.StringVal("http://127.0.0.1:8888/workspaces"),
+			},
+			wantErr: "workspace_unlock_address must be set when unlock_address and workspace_address are set",
+		},
+		"missing placeholder": {
+			conf: map[string]cty.Value{
+				"address":            cty.StringVal("http://127.0.0.1:8888/foo"),
+				"workspace_address":  cty.StringVal("http://127.0.0.1:8888/foo"),
+				"workspaces_address": cty.StringVal("http://127.0.0.1:8888/workspaces"),
+			},
+			wantErr: "workspace_address must contain {workspace}",
+		},
+		"invalid scheme": {
+			conf: map[string]cty.Value{
+				"address":            cty.StringVal("http://127.0.0.1:8888/foo"),
+				"workspace_address":  cty.StringVal("ftp://127.0.0.1:8888/{workspace}"),
+				"workspaces_address": cty.StringVal("http://127.0.0.1:8888/workspaces"),
+			},
+			wantErr: "invalid workspace_address: address must be HTTP or HTTPS",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, errs := backend.TestBackendConfigWarningsAndErrors(t, New(encryption.StateEncryptionDisabled()), configs.SynthBody("synth", tc.conf))
+			if len(errs) != 1 {
+				t.Fatalf("expected 1 error, got %v", errs)
+			}
+			if !strings.Contains(errs[0].Error(), tc.wantErr) {
+				t.Errorf("wrong error %q; want %q", errs[0], tc.wantErr)
+			}
+		})
+	}
+}
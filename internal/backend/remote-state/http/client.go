@@ -298,6 +298,42 @@ func (c *httpClient) Delete(ctx context.Context) error {
 	}
 }
 
+// Workspaces fetches the names of the workspaces from the given list
+// endpoint, which must respond with a JSON array of names.
+func (c *httpClient) Workspaces(ctx context.Context, listURL *url.URL) ([]string, error) {
+	resp, err := c.httpRequest(ctx, http.MethodGet, listURL, nil, "list workspaces")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// Handled after
+	case http.StatusNoContent, http.StatusNotFound:
+		return nil, nil
+	case http.StatusUnauthorized:
+		log.Printf("[DEBUG] LIST WORKSPACES, Unauthorized: %s", parseResponseBodyForLog(resp))
+		return nil, fmt.Errorf("HTTP remote state endpoint requires auth")
+	case http.StatusForbidden:
+		log.Printf("[DEBUG] LIST WORKSPACES, Forbidden: %s", parseResponseBodyForLog(resp))
+		return nil, fmt.Errorf("HTTP remote state endpoint invalid auth")
+	default:
+		log.Printf("[DEBUG] LIST WORKSPACES, %d: %s", resp.StatusCode, parseResponseBodyForLog(resp))
+		return nil, fmt.Errorf("Unexpected HTTP response code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read workspaces: %w", err)
+	}
+	var names []string
+	if err := json.Unmarshal(body, &names); err != nil {
+		return nil, fmt.Errorf("Failed to parse workspaces, expected a JSON array of names: %w", err)
+	}
+	return names, nil
+}
+
 func (c *httpClient) IsLockingEnabled() bool {
 	return c.UnlockURL != nil
 }
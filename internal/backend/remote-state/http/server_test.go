@@ -86,9 +86,29 @@ func newHttpServer(opts ...httpServerOpt) *httpServer {
 	}
 	s.data["sample"] = sampleState
 	r.HandleFunc("/state/", s.handleState)
+	r.HandleFunc("/workspaces", s.handleWorkspaces)
 	return s
 }
 
+// handleWorkspaces lists the workspaces stored under /state/workspaces/.
+func (h *httpServer) handleWorkspaces(writer http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	names := []string{}
+	for resource := range h.data {
+		if name, ok := strings.CutPrefix(resource, "workspaces/"); ok {
+			names = append(names, name)
+		}
+	}
+	_ = json.NewEncoder(writer).Encode(names)
+}
+
 func (h *httpServer) getResource(req *http.Request) string {
 	switch pathParts := strings.SplitN(req.URL.Path, "/", 3); len(pathParts) {
 	case 3:
@@ -419,6 +439,88 @@ func TestMTLSServer_WithCertPasses(t *testing.T) {
 	}
 }
 
+func TestHTTPBackend_workspaces(t *testing.T) {
+	ts := httptest.NewServer(newHttpServer().handler())
+	defer ts.Close()
+
+	conf := map[string]cty.Value{
+		"address":                  cty.StringVal(ts.URL + "/state/default"),
+		"lock_address":             cty.StringVal(ts.URL + "/state/default"),
+		"unlock_address":           cty.StringVal(ts.URL + "/state/default"),
+		"workspace_address":        cty.StringVal(ts.URL + "/state/workspaces/{workspace}"),
+		"workspace_lock_address":   cty.StringVal(ts.URL + "/state/workspaces/{workspace}"),
+		"workspace_unlock_address": cty.StringVal(ts.URL + "/state/workspaces/{workspace}"),
+		"workspaces_address":       cty.StringVal(ts.URL + "/workspaces"),
+	}
+	b1 := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), configs.SynthBody("synth", conf))
+	b2 := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), configs.SynthBody("synth", conf))
+
+	backend.TestBackendStates(t, b1)
+	backend.TestBackendStateLocks(t, b1, b2)
+	backend.TestBackendStateLocksInWS(t, b1, b2, "foo")
+}
+
+func TestHTTPBackend_workspaceLocking(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockCallback := NewMockHttpServerCallback(ctrl)
+
+	isWorkspace := func(name string) gomock.Matcher {
+		return gomock.Cond(func(req *http.Request) bool {
+			return req.URL.Path == "/state/workspaces/"+name
+		})
+	}
+
+	// Creating the workspace locks it while its empty state is written, which
+	// reads the missing state twice, and the state is then read again by the
+	// caller. Getting the state of the workspace once it exists doesn't lock
+	// or read it.
+	mockCallback.EXPECT().StateLOCK(isWorkspace("staging")).Times(1)
+	mockCallback.EXPECT().StateGET(isWorkspace("staging")).Times(3)
+	mockCallback.EXPECT().StatePOST(isWorkspace("staging")).Times(1)
+	mockCallback.EXPECT().StateUNLOCK(isWorkspace("staging")).Times(1)
+	mockCallback.EXPECT().StateDELETE(isWorkspace("staging")).Times(1)
+
+	ts := httptest.NewServer(newHttpServer(withHttpServerCallback(mockCallback)).handler())
+	defer ts.Close()
+
+	conf := map[string]cty.Value{
+		"address":                  cty.StringVal(ts.URL + "/state/default"),
+		"workspace_address":        cty.StringVal(ts.URL + "/state/workspaces/{workspace}"),
+		"workspace_lock_address":   cty.StringVal(ts.URL + "/state/workspaces/{workspace}"),
+		"workspace_unlock_address": cty.StringVal(ts.URL + "/state/workspaces/{workspace}"),
+		"workspaces_address":       cty.StringVal(ts.URL + "/workspaces"),
+	}
+	b := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), configs.SynthBody("synth", conf))
+
+	sm, err := b.StateMgr(t.Context(), "staging")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := sm.RefreshState(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	workspaces, err := b.Workspaces(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := []string{backend.DefaultStateName, "staging"}; !reflect.DeepEqual(workspaces, want) {
+		t.Errorf("wrong workspaces\ngot:  %#v\nwant: %#v", workspaces, want)
+	}
+
+	if _, err := b.StateMgr(t.Context(), "staging"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := b.DeleteWorkspace(t.Context(), "staging", false); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := b.DeleteWorkspace(t.Context(), backend.DefaultStateName, false); err == nil {
+		t.Error("expected error deleting the default workspace")
+	}
+}
+
 // TestRunServer allows running the server for local debugging; it runs until ctl-c is received
 func TestRunServer(t *testing.T) {
 	if _, ok := os.LookupEnv("TEST_RUN_SERVER"); !ok {
@@ -1639,6 +1639,26 @@ func deleteS3Bucket(ctx context.Context, t *testing.T, s3Client *s3.Client, buck
 		}
 	}
 
+	// versioned buckets also retain earlier versions and delete markers
+	versions, err := s3Client.ListObjectVersions(ctx, &s3.ListObjectVersionsInput{Bucket: &bucketName})
+	if err != nil {
+		t.Logf(warning, err)
+		return
+	}
+	var objs []types.ObjectIdentifier
+	for _, v := range versions.Versions {
+		objs = append(objs, types.ObjectIdentifier{Key: v.Key, VersionId: v.VersionId})
+	}
+	for _, m := range versions.DeleteMarkers {
+		objs = append(objs, types.ObjectIdentifier{Key: m.Key, VersionId: m.VersionId})
+	}
+	for _, obj := range objs {
+		if _, err := s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: &bucketName, Key: obj.Key, VersionId: obj.VersionId}); err != nil {
+			t.Logf(warning, err)
+			return
+		}
+	}
+
 	if _, err := s3Client.DeleteBucket(ctx, &s3.DeleteBucketInput{Bucket: &bucketName}); err != nil {
 		t.Logf(warning, err)
 	}
@@ -196,6 +196,95 @@ func (c *RemoteClient) get(ctx context.Context) (*remote.Payload, error) {
 	return payload, nil
 }
 
+// Versions is part of our implementation of remote.ClientVersioner. When
+// versioning is not enabled on the bucket it returns only the current
+// version, which S3 reports with the ID "null".
+func (c *RemoteClient) Versions(ctx context.Context, limit int) ([]*remote.PayloadVersion, error) {
+	ctx, _ = attachLoggerToContext(ctx)
+
+	var ret []*remote.PayloadVersion
+	input := &s3.ListObjectVersionsInput{
+		Bucket: &c.bucketName,
+		Prefix: &c.path,
+	}
+	for {
+		output, err := c.s3Client.ListObjectVersions(ctx, input, s3optDisableDefaultChecksum(c.skipS3Checksum))
+		if err != nil {
+			var nb *types.NoSuchBucket
+			if errors.As(err, &nb) {
+				return nil, fmt.Errorf(errS3NoSuchBucket, err)
+			}
+			return nil, err
+		}
+
+		for _, version := range output.Versions {
+			// The prefix also matches other objects, such as the lock file.
+			if aws.ToString(version.Key) != c.path {
+				continue
+			}
+			ret = append(ret, &remote.PayloadVersion{
+				ID:   aws.ToString(version.VersionId),
+				Time: aws.ToTime(version.LastModified),
+			})
+			if limit > 0 && len(ret) == limit {
+				// S3 lists the versions of each key newest first, so
+				// there's no need to list the rest.
+				return ret, nil
+			}
+		}
+
+		if !aws.ToBool(output.IsTruncated) {
+			break
+		}
+		input.KeyMarker = output.NextKeyMarker
+		input.VersionIdMarker = output.NextVersionIdMarker
+	}
+
+	// S3 already lists the versions of each key newest first.
+	return ret, nil
+}
+
+// GetVersion is part of our implementation of remote.ClientVersioner.
+func (c *RemoteClient) GetVersion(ctx context.Context, id string) (*remote.Payload, error) {
+	ctx, _ = attachLoggerToContext(ctx)
+
+	input := &s3.GetObjectInput{
+		Bucket:    &c.bucketName,
+		Key:       &c.path,
+		VersionId: aws.String(id),
+	}
+
+	if c.serverSideEncryption && c.customerEncryptionKey != nil {
+		input.SSECustomerKey = aws.String(base64.StdEncoding.EncodeToString(c.customerEncryptionKey))
+		input.SSECustomerAlgorithm = aws.String(s3EncryptionAlgorithm)
+		input.SSECustomerKeyMD5 = aws.String(c.getSSECustomerKeyMD5())
+	}
+
+	output, err := c.s3Client.GetObject(ctx, input, s3optDisableDefaultChecksum(c.skipS3Checksum))
+	if err != nil {
+		var nk *types.NoSuchKey
+		if errors.As(err, &nk) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	buf := bytes.NewBuffer(nil)
+	if _, err := io.Copy(buf, output.Body); err != nil {
+		return nil, fmt.Errorf("Failed to read remote state version %s: %w", id, err)
+	}
+	if buf.Len() == 0 {
+		return nil, nil
+	}
+
+	sum := md5.Sum(buf.Bytes())
+	return &remote.Payload{
+		Data: buf.Bytes(),
+		MD5:  sum[:],
+	}, nil
+}
+
 func (c *RemoteClient) Put(ctx context.Context, data []byte) error {
 	contentLength := int64(len(data))
 
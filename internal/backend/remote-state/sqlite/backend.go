@@ -0,0 +1,120 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	// Registers the pure Go "sqlite" database/sql driver, so that the
+	// backend works in builds without cgo.
+	_ "modernc.org/sqlite"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/legacy/helper/schema"
+)
+
+// busyTimeoutMillis is how long a connection waits for another process to
+// release the database file before failing with SQLITE_BUSY.
+const busyTimeoutMillis = 5000
+
+// New creates a new backend for SQLite state.
+func New(enc encryption.StateEncryption) backend.Backend {
+	s := &schema.Backend{
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path to the SQLite database file that stores all of the workspaces",
+				DefaultFunc: schema.EnvDefaultFunc("TF_SQLITE_PATH", "terraform.tfstate.db"),
+			},
+
+			"history_limit": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Number of earlier state snapshots to keep for each workspace. Zero keeps all of them",
+				Default:     0,
+			},
+		},
+	}
+
+	result := &Backend{Backend: s, encryption: enc}
+	result.Backend.ConfigureFunc = result.configure
+	return result
+}
+
+type Backend struct {
+	*schema.Backend
+	encryption encryption.StateEncryption
+
+	// The fields below are set from configure
+	db           *sql.DB
+	configData   *schema.ResourceData
+	path         string
+	historyLimit int
+}
+
+func (b *Backend) configure(ctx context.Context) error {
+	// Grab the resource data
+	b.configData = schema.FromContextBackendConfig(ctx)
+	data := b.configData
+
+	b.path = data.Get("path").(string)
+	b.historyLimit = data.Get("history_limit").(int)
+	if b.path == "" {
+		return fmt.Errorf("path must not be empty")
+	}
+	if b.historyLimit < 0 {
+		return fmt.Errorf("history_limit must not be negative")
+	}
+
+	// The path is escaped into a "file:" URI, since a "?" or "#" in a plain
+	// file name would otherwise be taken as the start of the query.
+	//
+	// Write transactions take the database lock when they begin rather than
+	// on their first write, so that two processes can't both start a
+	// transaction and then fail to upgrade it.
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)&_txlock=immediate", url.PathEscape(b.path), busyTimeoutMillis)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return err
+	}
+
+	// Every snapshot of every workspace is a row of the states table, and
+	// the current state of a workspace is its most recent row.
+	query := `CREATE TABLE IF NOT EXISTS states (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		data BLOB NOT NULL,
+		created_at INTEGER NOT NULL
+		)`
+	if _, err = db.Exec(query); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to prepare SQLite database %s: %w", b.path, err)
+	}
+
+	query = `CREATE INDEX IF NOT EXISTS states_by_name ON states (name, id)`
+	if _, err = db.Exec(query); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to prepare SQLite database %s: %w", b.path, err)
+	}
+
+	query = `CREATE TABLE IF NOT EXISTS locks (
+		name TEXT NOT NULL PRIMARY KEY,
+		id TEXT NOT NULL,
+		info BLOB NOT NULL
+		)`
+	if _, err = db.Exec(query); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to prepare SQLite database %s: %w", b.path, err)
+	}
+
+	// Assign db after its schema is prepared.
+	b.db = db
+
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package sqlite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/states/remote"
+)
+
+// testConfig returns a backend configuration that uses a new database file
+// in a temporary directory, with the given additional arguments.
+func testConfig(t *testing.T, raw map[string]interface{}) hcl.Body {
+	t.Helper()
+
+	config := map[string]interface{}{
+		"path": filepath.Join(t.TempDir(), "terraform.tfstate.db"),
+	}
+	for k, v := range raw {
+		config[k] = v
+	}
+	return backend.TestWrapConfig(config)
+}
+
+func TestBackend_impl(t *testing.T) {
+	var _ backend.Backend = new(Backend)
+}
+
+func TestBackendConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "terraform.tfstate.db")
+	config := backend.TestWrapConfig(map[string]interface{}{
+		"path":          path,
+		"history_limit": 5,
+	})
+	b := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config).(*Backend)
+
+	if b.path != path {
+		t.Fatalf("wrong path %q; want %q", b.path, path)
+	}
+	if b.historyLimit != 5 {
+		t.Fatalf("wrong history limit %d; want 5", b.historyLimit)
+	}
+
+	s, err := b.StateMgr(t.Context(), backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.(*remote.State).Client.(*RemoteClient)
+	if c.Name != backend.DefaultStateName {
+		t.Fatalf("wrong workspace name %q", c.Name)
+	}
+	if c.HistoryLimit != 5 {
+		t.Fatalf("wrong client history limit %d; want 5", c.HistoryLimit)
+	}
+}
+
+func TestBackendConfig_specialCharacters(t *testing.T) {
+	for _, name := range []string{
+		"state?.db",
+		"state#1.db",
+		"state 100%.db",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, name)
+			config := backend.TestWrapConfig(map[string]interface{}{
+				"path": path,
+			})
+			b := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config)
+			if _, err := b.StateMgr(t.Context(), backend.DefaultStateName); err != nil {
+				t.Fatal(err)
+			}
+
+			// The database must be created under the configured name rather
+			// than a name cut short at the special character.
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Name() != name {
+				t.Fatalf("wrong files in %s: %v", dir, entries)
+			}
+		})
+	}
+}
+
+func TestBackendConfig_invalid(t *testing.T) {
+	config := testConfig(t, map[string]interface{}{
+		"history_limit": -1,
+	})
+	_, _, errs := backend.TestBackendConfigWarningsAndErrors(t, New(encryption.StateEncryptionDisabled()), config)
+	if len(errs) == 0 {
+		t.Fatal("expected an error for a negative history_limit")
+	}
+}
+
+func TestBackendStates(t *testing.T) {
+	config := testConfig(t, nil)
+	b := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config)
+
+	backend.TestBackendStates(t, b)
+}
+
+func TestBackendStateLocks(t *testing.T) {
+	config := testConfig(t, nil)
+
+	b1 := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config)
+	b2 := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config)
+
+	backend.TestBackendStateLocks(t, b1, b2)
+	backend.TestBackendStateForceUnlock(t, b1, b2)
+}
+
+// TestBackendSharedFile tests that two backends using the same database
+// file see each other's workspaces, as two OpenTofu processes would.
+func TestBackendSharedFile(t *testing.T) {
+	config := testConfig(t, nil)
+
+	b1 := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config)
+	b2 := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config)
+
+	if _, err := b1.StateMgr(t.Context(), "shared"); err != nil {
+		t.Fatal(err)
+	}
+
+	workspaces, err := b2.Workspaces(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workspaces) != 2 || workspaces[0] != backend.DefaultStateName || workspaces[1] != "shared" {
+		t.Fatalf("wrong workspaces %#v", workspaces)
+	}
+
+	if err := b2.DeleteWorkspace(t.Context(), "shared", false); err != nil {
+		t.Fatal(err)
+	}
+	workspaces, err = b1.Workspaces(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workspaces) != 1 {
+		t.Fatalf("wrong workspaces after delete %#v", workspaces)
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package sqlite
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	uuid "github.com/hashicorp/go-uuid"
+
+	"github.com/opentofu/opentofu/internal/states/remote"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+)
+
+// RemoteClient is a remote client that stores data in a SQLite database file
+type RemoteClient struct {
+	Client *sql.DB
+	Name   string
+	Path   string
+
+	// HistoryLimit is the number of earlier snapshots of the state that Put
+	// keeps, or zero to keep all of them.
+	HistoryLimit int
+}
+
+var _ remote.ClientVersioner = (*RemoteClient)(nil)
+
+func (c *RemoteClient) Get(ctx context.Context) (*remote.Payload, error) {
+	row := c.Client.QueryRowContext(ctx, `SELECT data FROM states WHERE name = ? ORDER BY id DESC LIMIT 1`, c.Name)
+	return scanPayload(row)
+}
+
+func (c *RemoteClient) Put(ctx context.Context, data []byte) error {
+	tx, err := c.Client.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rolling back has no effect once the transaction is committed.
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO states (name, data, created_at) VALUES (?, ?, ?)`, c.Name, data, time.Now().UnixNano())
+	if err != nil {
+		return err
+	}
+
+	if c.HistoryLimit > 0 {
+		// The new snapshot is the most recent row, so we keep one more
+		// row than the number of earlier snapshots.
+		_, err = tx.ExecContext(ctx, `DELETE FROM states WHERE name = ? AND id NOT IN (
+			SELECT id FROM states WHERE name = ? ORDER BY id DESC LIMIT ?
+			)`, c.Name, c.Name, c.HistoryLimit+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (c *RemoteClient) Delete(ctx context.Context) error {
+	_, err := c.Client.ExecContext(ctx, `DELETE FROM states WHERE name = ?`, c.Name)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *RemoteClient) Versions(ctx context.Context, limit int) ([]*remote.PayloadVersion, error) {
+	if limit <= 0 {
+		// A negative limit means there is no limit in SQLite.
+		limit = -1
+	}
+	rows, err := c.Client.QueryContext(ctx, `SELECT id, created_at FROM states WHERE name = ? ORDER BY id DESC LIMIT ?`, c.Name, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ret []*remote.PayloadVersion
+	for rows.Next() {
+		var id, createdAt int64
+		if err := rows.Scan(&id, &createdAt); err != nil {
+			return nil, err
+		}
+		ret = append(ret, &remote.PayloadVersion{
+			ID:   strconv.FormatInt(id, 10),
+			Time: time.Unix(0, createdAt).UTC(),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (c *RemoteClient) GetVersion(ctx context.Context, id string) (*remote.Payload, error) {
+	rowID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("State version should be a row ID, got '%s'", id)
+	}
+
+	row := c.Client.QueryRowContext(ctx, `SELECT data FROM states WHERE name = ? AND id = ?`, c.Name, rowID)
+	return scanPayload(row)
+}
+
+func (c *RemoteClient) Lock(ctx context.Context, info *statemgr.LockInfo) (string, error) {
+	if info.ID == "" {
+		lockID, err := uuid.GenerateUUID()
+		if err != nil {
+			return "", err
+		}
+		info.ID = lockID
+	}
+	info.Path = c.Path
+
+	result, err := c.Client.ExecContext(ctx, `INSERT INTO locks (name, id, info) VALUES (?, ?, ?) ON CONFLICT (name) DO NOTHING`,
+		c.Name, info.ID, info.Marshal())
+	if err != nil {
+		return "", &statemgr.LockError{Info: info, Err: err}
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return "", &statemgr.LockError{Info: info, Err: err}
+	}
+	if n == 0 {
+		lockErr := &statemgr.LockError{Err: fmt.Errorf("Workspace is already locked: %s", c.Name)}
+		existing, err := c.getLockInfo(ctx)
+		if err != nil {
+			lockErr.Err = errors.Join(lockErr.Err, err)
+		}
+		lockErr.Info = existing
+		return "", lockErr
+	}
+
+	return info.ID, nil
+}
+
+func (c *RemoteClient) Unlock(ctx context.Context, id string) error {
+	lockErr := &statemgr.LockError{}
+
+	existing, err := c.getLockInfo(ctx)
+	if err != nil {
+		lockErr.Err = err
+		return lockErr
+	}
+	if existing == nil {
+		lockErr.Err = fmt.Errorf("Workspace is not locked: %s", c.Name)
+		return lockErr
+	}
+	lockErr.Info = existing
+
+	if existing.ID != id {
+		lockErr.Err = fmt.Errorf("lock id %q does not match existing lock", id)
+		return lockErr
+	}
+
+	_, err = c.Client.ExecContext(ctx, `DELETE FROM locks WHERE name = ? AND id = ?`, c.Name, id)
+	if err != nil {
+		lockErr.Err = err
+		return lockErr
+	}
+	return nil
+}
+
+// getLockInfo returns the lock currently held on the workspace, or nil if
+// there is none.
+func (c *RemoteClient) getLockInfo(ctx context.Context) (*statemgr.LockInfo, error) {
+	var data []byte
+	err := c.Client.QueryRowContext(ctx, `SELECT info FROM locks WHERE name = ?`, c.Name).Scan(&data)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	info := &statemgr.LockInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func scanPayload(row *sql.Row) (*remote.Payload, error) {
+	var data []byte
+	err := row.Scan(&data)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// No existing state returns empty.
+		return nil, nil
+	case err != nil:
+		return nil, err
+	default:
+		md5 := md5.Sum(data)
+		return &remote.Payload{
+			Data: data,
+			MD5:  md5[:],
+		}, nil
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/states/remote"
+)
+
+func TestRemoteClient_impl(t *testing.T) {
+	var _ remote.Client = new(RemoteClient)
+	var _ remote.ClientLocker = new(RemoteClient)
+	var _ remote.ClientVersioner = new(RemoteClient)
+}
+
+func TestRemoteClient(t *testing.T) {
+	config := testConfig(t, nil)
+	b := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config).(*Backend)
+
+	s, err := b.StateMgr(t.Context(), backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote.TestClient(t, s.(*remote.State).Client)
+}
+
+func TestRemoteClientVersions(t *testing.T) {
+	config := testConfig(t, nil)
+	b := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config).(*Backend)
+
+	s, err := b.StateMgr(t.Context(), backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote.TestClientVersions(t, s.(*remote.State).Client.(remote.ClientVersioner))
+}
+
+func TestRemoteClientVersions_historyLimit(t *testing.T) {
+	config := testConfig(t, map[string]interface{}{
+		"history_limit": 1,
+	})
+	b := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config).(*Backend)
+
+	c := &RemoteClient{
+		Client:       b.db,
+		Name:         "limited",
+		Path:         b.path,
+		HistoryLimit: b.historyLimit,
+	}
+	for _, data := range []string{"one", "two", "three"} {
+		if err := c.Put(t.Context(), []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := c.Versions(t.Context(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected the current state and one earlier version, got %d versions", len(versions))
+	}
+	for i, want := range []string{"three", "two"} {
+		p, err := c.GetVersion(t.Context(), versions[i].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p == nil || string(p.Data) != want {
+			t.Fatalf("wrong data for version %s\nwant: %q", versions[i].ID, want)
+		}
+	}
+}
+
+func TestRemoteLocks(t *testing.T) {
+	config := testConfig(t, nil)
+
+	b1 := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config).(*Backend)
+	s1, err := b1.StateMgr(t.Context(), backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b2 := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config).(*Backend)
+	s2, err := b2.StateMgr(t.Context(), backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote.TestRemoteLocks(t, s1.(*remote.State).Client, s2.(*remote.State).Client)
+}
@@ -30,6 +30,27 @@ type UnparsedVariableValue interface {
 	ParseVariableValue(mode configs.VariableParsingMode) (*tofu.InputValue, tfdiags.Diagnostics)
 }
 
+// UnparsedVariableValueForType is an optional interface for an
+// UnparsedVariableValue whose result depends on the exact type constraint
+// of the variable, and not only on its parsing mode.
+type UnparsedVariableValueForType interface {
+	UnparsedVariableValue
+
+	// ParseVariableValueForType is like ParseVariableValue, but also takes
+	// the type constraint declared for the variable.
+	ParseVariableValueForType(mode configs.VariableParsingMode, ty cty.Type) (*tofu.InputValue, tfdiags.Diagnostics)
+}
+
+// ParseVariableValueForDecl parses the given value for the given variable
+// declaration, using ParseVariableValueForType if the value implements
+// UnparsedVariableValueForType.
+func ParseVariableValueForDecl(rv UnparsedVariableValue, config *configs.Variable) (*tofu.InputValue, tfdiags.Diagnostics) {
+	if typed, ok := rv.(UnparsedVariableValueForType); ok {
+		return typed.ParseVariableValueForType(config.ParsingMode, config.Type)
+	}
+	return rv.ParseVariableValue(config.ParsingMode)
+}
+
 // ParseUndeclaredVariableValues processes a map of unparsed variable values
 // and returns an input values map of the ones not declared in the specified
 // declaration map along with detailed diagnostics about values of undeclared
@@ -112,17 +133,13 @@ func ParseDeclaredVariableValues(vv map[string]UnparsedVariableValue, decls map[
 	ret := make(tofu.InputValues, len(vv))
 
 	for name, rv := range vv {
-		var mode configs.VariableParsingMode
 		config, declared := decls[name]
-
-		if declared {
-			mode = config.ParsingMode
-		} else {
+		if !declared {
 			// Only interested in parsing declared variables
 			continue
 		}
 
-		val, valDiags := rv.ParseVariableValue(mode)
+		val, valDiags := ParseVariableValueForDecl(rv, config)
 		diags = diags.Append(valDiags)
 		if valDiags.HasErrors() {
 			continue
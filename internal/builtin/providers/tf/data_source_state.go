@@ -7,16 +7,24 @@ package tf
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"strings"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/backend/remote"
+	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/encryption/config"
 	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/zclconf/go-cty/cty"
 
@@ -51,10 +59,32 @@ func dataSourceRemoteStateGetSchema() providers.Schema {
 					DescriptionKind: configschema.StringMarkdown,
 					Optional:        true,
 				},
+				"encryption": {
+					Type: cty.String,
+					Description: "An encryption configuration for reading " +
+						"the remote state, in the same HCL or JSON format as " +
+						"the `TF_ENCRYPTION` environment variable. The `state` " +
+						"block of the configuration describes how the remote " +
+						"state is encrypted. When set, this is used instead of " +
+						"any `remote_state_data_sources` configuration.",
+					DescriptionKind: configschema.StringMarkdown,
+					Optional:        true,
+					Sensitive:       true,
+				},
+				"output_names": {
+					Type: cty.Set(cty.String),
+					Description: "The names of the root-level outputs to " +
+						"read from the remote state. When set, only these " +
+						"outputs are decoded, which avoids loading the whole " +
+						"of a large remote state.",
+					DescriptionKind: configschema.StringMarkdown,
+					Optional:        true,
+				},
 				"outputs": {
 					Type: cty.DynamicPseudoType,
 					Description: "An object containing every root-level " +
-						"output in the remote state.",
+						"output in the remote state, or only those selected " +
+						"by `output_names`.",
 					DescriptionKind: configschema.StringMarkdown,
 					Computed:        true,
 				},
@@ -103,12 +133,43 @@ func dataSourceRemoteStateValidate(cfg cty.Value) tfdiags.Diagnostics {
 		}
 	}
 
+	// We only parse the encryption configuration here, because setting it up
+	// may need to contact external key providers.
+	if encVal := cfg.GetAttr("encryption"); encVal.IsKnown() && !encVal.IsNull() {
+		_, moreDiags := parseEncryptionConfig(encVal)
+		diags = diags.Append(moreDiags)
+	}
+
+	if namesVal := cfg.GetAttr("output_names"); namesVal.IsKnown() && !namesVal.IsNull() {
+		for it := namesVal.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			if v.IsNull() {
+				diags = diags.Append(tfdiags.AttributeValue(
+					tfdiags.Error,
+					"Invalid output names",
+					"Output names must not be null.",
+					cty.GetAttrPath("output_names"),
+				))
+				break
+			}
+		}
+	}
+
 	return diags
 }
 
 func dataSourceRemoteStateRead(ctx context.Context, d cty.Value, enc encryption.StateEncryption, path addrs.AbsResourceInstance) (cty.Value, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
+	if encVal := d.GetAttr("encryption"); !encVal.IsNull() {
+		var moreDiags tfdiags.Diagnostics
+		enc, moreDiags = inlineStateEncryption(ctx, encVal)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			return cty.NilVal, diags
+		}
+	}
+
 	b, cfg, moreDiags := getBackend(d, enc)
 	diags = diags.Append(moreDiags)
 	if moreDiags.HasErrors() {
@@ -124,6 +185,8 @@ func dataSourceRemoteStateRead(ctx context.Context, d cty.Value, enc encryption.
 	newState := make(map[string]cty.Value)
 	newState["backend"] = d.GetAttr("backend")
 	newState["config"] = d.GetAttr("config")
+	newState["encryption"] = d.GetAttr("encryption")
+	newState["output_names"] = d.GetAttr("output_names")
 
 	workspaceVal := d.GetAttr("workspace")
 	// This attribute is not computed, so we always have to store the state
@@ -146,7 +209,8 @@ func dataSourceRemoteStateRead(ctx context.Context, d cty.Value, enc encryption.
 		return cty.NilVal, diags
 	}
 
-	if err := state.RefreshState(ctx); err != nil {
+	outputValues, err := readRemoteStateOutputs(ctx, state, d.GetAttr("output_names"))
+	if err != nil && !errors.Is(err, statefile.ErrNoState) {
 		diags = diags.Append(err)
 		return cty.NilVal, diags
 	}
@@ -164,8 +228,7 @@ func dataSourceRemoteStateRead(ctx context.Context, d cty.Value, enc encryption.
 		newState["defaults"] = cty.NullVal(cty.DynamicPseudoType)
 	}
 
-	remoteState := state.State()
-	if remoteState == nil {
+	if outputValues == nil {
 		diags = diags.Append(tfdiags.AttributeValue(
 			tfdiags.Error,
 			"Unable to find remote state",
@@ -175,26 +238,115 @@ func dataSourceRemoteStateRead(ctx context.Context, d cty.Value, enc encryption.
 		newState["outputs"] = cty.EmptyObjectVal
 		return cty.ObjectVal(newState), diags
 	}
+	for k, os := range outputValues {
+		v := os.Value
+
+		if os.Deprecated != "" {
+			v = marks.Deprecated(v, marks.DeprecationCause{
+				By:      path.Resource,
+				Key:     k,
+				Message: os.Deprecated,
+			})
+		}
+
+		outputs[k] = v
+	}
+
+	newState["outputs"] = cty.ObjectVal(outputs)
+
+	return cty.ObjectVal(newState), diags
+}
+
+// readRemoteStateOutputs returns the root module output values from the
+// latest snapshot in the given state manager, or nil if there is no snapshot.
+//
+// If outputNames isn't null then only the named outputs are returned, and
+// for state managers that support it only those outputs are decoded.
+func readRemoteStateOutputs(ctx context.Context, state statemgr.Full, outputNames cty.Value) (map[string]*states.OutputValue, error) {
+	var names []string
+	if !outputNames.IsNull() {
+		names = make([]string, 0, outputNames.LengthInt())
+		for it := outputNames.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			names = append(names, v.AsString())
+		}
+
+		if reader, ok := state.(statemgr.SelectiveOutputReader); ok {
+			log.Printf("[TRACE] reading only outputs %q from the remote state", names)
+			return reader.GetRootOutputValuesByName(ctx, names)
+		}
+	}
+
+	if err := state.RefreshState(ctx); err != nil {
+		return nil, err
+	}
+	remoteState := state.State()
+	if remoteState == nil {
+		return nil, nil
+	}
+
+	outputValues := make(map[string]*states.OutputValue)
 	mod := remoteState.RootModule()
 	if mod != nil { // should always have a root module in any valid state
-		for k, os := range mod.OutputValues {
-			v := os.Value
-
-			if os.Deprecated != "" {
-				v = marks.Deprecated(v, marks.DeprecationCause{
-					By:      path.Resource,
-					Key:     k,
-					Message: os.Deprecated,
-				})
+		for name, os := range mod.OutputValues {
+			if names == nil || slices.Contains(names, name) {
+				outputValues[name] = os
 			}
-
-			outputs[k] = v
 		}
 	}
+	return outputValues, nil
+}
 
-	newState["outputs"] = cty.ObjectVal(outputs)
+// parseEncryptionConfig parses the inline encryption configuration given in
+// the "encryption" argument.
+func parseEncryptionConfig(encVal cty.Value) (*config.EncryptionConfig, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
 
-	return cty.ObjectVal(newState), diags
+	raw := encVal.AsString()
+	if strings.TrimSpace(raw) == "" {
+		diags = diags.Append(tfdiags.AttributeValue(
+			tfdiags.Error,
+			"Invalid encryption configuration",
+			"The encryption configuration must not be empty.",
+			cty.GetAttrPath("encryption"),
+		))
+		return nil, diags
+	}
+
+	cfg, hclDiags := config.LoadConfigFromString("encryption", raw)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return nil, diags
+	}
+	if cfg.State == nil {
+		diags = diags.Append(tfdiags.AttributeValue(
+			tfdiags.Error,
+			"Invalid encryption configuration",
+			"The encryption configuration must include a \"state\" block describing how the remote state is encrypted.",
+			cty.GetAttrPath("encryption"),
+		))
+		return nil, diags
+	}
+	return cfg, diags
+}
+
+// inlineStateEncryption returns the state encryption described by the inline
+// encryption configuration given in the "encryption" argument.
+func inlineStateEncryption(ctx context.Context, encVal cty.Value) (encryption.StateEncryption, tfdiags.Diagnostics) {
+	cfg, diags := parseEncryptionConfig(encVal)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	// The configuration is given as a string, so any references in it have
+	// already been resolved and the static evaluator has nothing to offer.
+	staticEval := configs.NewStaticEvaluator(&configs.Module{}, configs.NewStaticModuleCall(addrs.RootModule, nil, ".", ""))
+	enc, hclDiags := encryption.New(ctx, encryption.DefaultRegistry, cfg, staticEval)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return nil, diags
+	}
+	return enc.State(), diags
 }
 
 func getBackend(cfg cty.Value, enc encryption.StateEncryption) (backend.Backend, cty.Value, tfdiags.Diagnostics) {
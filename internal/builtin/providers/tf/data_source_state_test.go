@@ -9,6 +9,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/zclconf/go-cty-debug/ctydebug"
@@ -19,6 +21,8 @@ import (
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
@@ -47,6 +51,8 @@ func TestState_basic(t *testing.T) {
 				"config": cty.ObjectVal(map[string]cty.Value{
 					"path": cty.StringVal("./testdata/basic.tfstate"),
 				}),
+				"encryption":   cty.NullVal(cty.String),
+				"output_names": cty.NullVal(cty.Set(cty.String)),
 				"outputs": cty.ObjectVal(map[string]cty.Value{
 					"foo": cty.StringVal("bar"),
 				}),
@@ -69,6 +75,8 @@ func TestState_basic(t *testing.T) {
 				"config": cty.ObjectVal(map[string]cty.Value{
 					"path": cty.StringVal("./testdata/basic.tfstate"),
 				}),
+				"encryption":   cty.NullVal(cty.String),
+				"output_names": cty.NullVal(cty.Set(cty.String)),
 				"outputs": cty.ObjectVal(map[string]cty.Value{
 					"foo": cty.StringVal("bar"),
 				}),
@@ -88,6 +96,8 @@ func TestState_basic(t *testing.T) {
 				"config": cty.ObjectVal(map[string]cty.Value{
 					"path": cty.StringVal("./testdata/basic.tfstate"),
 				}),
+				"encryption":   cty.NullVal(cty.String),
+				"output_names": cty.NullVal(cty.Set(cty.String)),
 				"outputs": cty.ObjectVal(map[string]cty.Value{
 					"foo": cty.StringVal("bar"),
 				}),
@@ -108,6 +118,8 @@ func TestState_basic(t *testing.T) {
 				"config": cty.ObjectVal(map[string]cty.Value{
 					"path": cty.StringVal("./testdata/complex_outputs.tfstate"),
 				}),
+				"encryption":   cty.NullVal(cty.String),
+				"output_names": cty.NullVal(cty.Set(cty.String)),
 				"outputs": cty.ObjectVal(map[string]cty.Value{
 					"computed_map": cty.MapVal(map[string]cty.Value{
 						"key1": cty.StringVal("value1"),
@@ -142,6 +154,8 @@ func TestState_basic(t *testing.T) {
 				"config": cty.ObjectVal(map[string]cty.Value{
 					"path": cty.StringVal("./testdata/null_outputs.tfstate"),
 				}),
+				"encryption":   cty.NullVal(cty.String),
+				"output_names": cty.NullVal(cty.Set(cty.String)),
 				"outputs": cty.ObjectVal(map[string]cty.Value{
 					"map":  cty.NullVal(cty.Map(cty.String)),
 					"list": cty.NullVal(cty.List(cty.String)),
@@ -169,6 +183,8 @@ func TestState_basic(t *testing.T) {
 				"defaults": cty.ObjectVal(map[string]cty.Value{
 					"foo": cty.StringVal("bar"),
 				}),
+				"encryption":   cty.NullVal(cty.String),
+				"output_names": cty.NullVal(cty.Set(cty.String)),
 				"outputs": cty.ObjectVal(map[string]cty.Value{
 					"foo": cty.StringVal("bar"),
 				}),
@@ -188,9 +204,11 @@ func TestState_basic(t *testing.T) {
 				"config": cty.ObjectVal(map[string]cty.Value{
 					"path": cty.StringVal("./testdata/missing.tfstate"),
 				}),
-				"defaults":  cty.NullVal(cty.DynamicPseudoType),
-				"outputs":   cty.EmptyObjectVal,
-				"workspace": cty.NullVal(cty.String),
+				"defaults":     cty.NullVal(cty.DynamicPseudoType),
+				"encryption":   cty.NullVal(cty.String),
+				"output_names": cty.NullVal(cty.Set(cty.String)),
+				"outputs":      cty.EmptyObjectVal,
+				"workspace":    cty.NullVal(cty.String),
 			}),
 			true,
 		},
@@ -241,9 +259,11 @@ func TestState_basic(t *testing.T) {
 				"config": cty.MapVal(map[string]cty.Value{
 					"path": cty.StringVal("./testdata/empty.tfstate"),
 				}),
-				"defaults":  cty.NullVal(cty.DynamicPseudoType),
-				"outputs":   cty.EmptyObjectVal,
-				"workspace": cty.NullVal(cty.String),
+				"defaults":     cty.NullVal(cty.DynamicPseudoType),
+				"encryption":   cty.NullVal(cty.String),
+				"output_names": cty.NullVal(cty.Set(cty.String)),
+				"outputs":      cty.EmptyObjectVal,
+				"workspace":    cty.NullVal(cty.String),
 			}),
 			false,
 		},
@@ -260,7 +280,9 @@ func TestState_basic(t *testing.T) {
 				"config": cty.ObjectVal(map[string]cty.Value{
 					"path": cty.StringVal("./testdata/basic.tfstate"),
 				}),
-				"defaults": cty.MapValEmpty(cty.String),
+				"defaults":     cty.MapValEmpty(cty.String),
+				"encryption":   cty.NullVal(cty.String),
+				"output_names": cty.NullVal(cty.Set(cty.String)),
 				"outputs": cty.ObjectVal(map[string]cty.Value{
 					"foo": cty.StringVal("bar"),
 				}),
@@ -286,6 +308,68 @@ func TestState_basic(t *testing.T) {
 			cty.NilVal,
 			true,
 		},
+		"output names": {
+			cty.ObjectVal(map[string]cty.Value{
+				"backend": cty.StringVal("local"),
+				"config": cty.ObjectVal(map[string]cty.Value{
+					"path": cty.StringVal("./testdata/complex_outputs.tfstate"),
+				}),
+				"output_names": cty.SetVal([]cty.Value{
+					cty.StringVal("map"),
+					cty.StringVal("missing"),
+				}),
+				"defaults": cty.ObjectVal(map[string]cty.Value{
+					"missing": cty.StringVal("default"),
+				}),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"backend": cty.StringVal("local"),
+				"config": cty.ObjectVal(map[string]cty.Value{
+					"path": cty.StringVal("./testdata/complex_outputs.tfstate"),
+				}),
+				"encryption": cty.NullVal(cty.String),
+				"output_names": cty.SetVal([]cty.Value{
+					cty.StringVal("map"),
+					cty.StringVal("missing"),
+				}),
+				"outputs": cty.ObjectVal(map[string]cty.Value{
+					"map": cty.MapVal(map[string]cty.Value{
+						"key":  cty.StringVal("test"),
+						"test": cty.StringVal("test"),
+					}),
+					"missing": cty.StringVal("default"),
+				}),
+				"defaults": cty.ObjectVal(map[string]cty.Value{
+					"missing": cty.StringVal("default"),
+				}),
+				"workspace": cty.NullVal(cty.String),
+			}),
+			false,
+		},
+		"output names from missing state": {
+			cty.ObjectVal(map[string]cty.Value{
+				"backend": cty.StringVal("local"),
+				"config": cty.ObjectVal(map[string]cty.Value{
+					"path": cty.StringVal("./testdata/missing.tfstate"),
+				}),
+				"output_names": cty.SetVal([]cty.Value{
+					cty.StringVal("foo"),
+				}),
+			}),
+			cty.NilVal,
+			true,
+		},
+		"invalid encryption": {
+			cty.ObjectVal(map[string]cty.Value{
+				"backend": cty.StringVal("local"),
+				"config": cty.ObjectVal(map[string]cty.Value{
+					"path": cty.StringVal("./testdata/basic.tfstate"),
+				}),
+				"encryption": cty.StringVal(`method "aes_gcm" "example" {}`),
+			}),
+			cty.NilVal,
+			true,
+		},
 		"deprecation marks": {
 			cty.ObjectVal(map[string]cty.Value{
 				"backend": cty.StringVal("local"),
@@ -298,6 +382,8 @@ func TestState_basic(t *testing.T) {
 				"config": cty.ObjectVal(map[string]cty.Value{
 					"path": cty.StringVal("./testdata/deprecation_warnings.tfstate"),
 				}),
+				"encryption":   cty.NullVal(cty.String),
+				"output_names": cty.NullVal(cty.Set(cty.String)),
 				"outputs": cty.ObjectVal(map[string]cty.Value{
 					"foo": marks.Deprecated(cty.StringVal("bar"), marks.DeprecationCause{
 						By: addrs.ResourceInstance{
@@ -357,6 +443,112 @@ func TestState_basic(t *testing.T) {
 	}
 }
 
+func TestState_encryption(t *testing.T) {
+	// The state is encrypted with the same inline configuration that the
+	// data source is given, as it would be by the configuration that owns it.
+	encConfig := `
+key_provider "pbkdf2" "remote" {
+  passphrase = "remote-state-passphrase"
+}
+method "aes_gcm" "remote" {
+  keys = key_provider.pbkdf2.remote
+}
+state {
+  method = method.aes_gcm.remote
+}
+`
+	enc, diags := inlineStateEncryption(t.Context(), cty.StringVal(encConfig))
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags.Err())
+	}
+
+	state := states.NewState()
+	state.RootModule().SetOutputValue("foo", cty.StringVal("bar"), false, "")
+	state.RootModule().SetOutputValue("baz", cty.StringVal("qux"), false, "")
+	statePath := filepath.Join(t.TempDir(), "encrypted.tfstate")
+	f, err := os.Create(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := statefile.Write(statefile.New(state, "", 1), f, enc); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	schema := dataSourceRemoteStateGetSchema().Block
+	path := addrs.AbsResourceInstance{
+		Resource: addrs.ResourceInstance{
+			Resource: addrs.Resource{
+				Mode: addrs.DataResourceMode,
+				Type: "terraform_remote_state",
+				Name: "test",
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		encryption  cty.Value
+		outputNames cty.Value
+		want        cty.Value
+		wantErr     bool
+	}{
+		"without encryption": {
+			encryption:  cty.NullVal(cty.String),
+			outputNames: cty.NullVal(cty.Set(cty.String)),
+			wantErr:     true,
+		},
+		"all outputs": {
+			encryption:  cty.StringVal(encConfig),
+			outputNames: cty.NullVal(cty.Set(cty.String)),
+			want: cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.StringVal("bar"),
+				"baz": cty.StringVal("qux"),
+			}),
+		},
+		"selected outputs": {
+			encryption:  cty.StringVal(encConfig),
+			outputNames: cty.SetVal([]cty.Value{cty.StringVal("foo")}),
+			want: cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.StringVal("bar"),
+			}),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config, err := schema.CoerceValue(cty.ObjectVal(map[string]cty.Value{
+				"backend": cty.StringVal("local"),
+				"config": cty.ObjectVal(map[string]cty.Value{
+					"path": cty.StringVal(statePath),
+				}),
+				"encryption":   test.encryption,
+				"output_names": test.outputNames,
+			}))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			diags := dataSourceRemoteStateValidate(config)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected validation errors: %v", diags.Err())
+			}
+
+			got, diags := dataSourceRemoteStateRead(t.Context(), config, encryption.StateEncryptionDisabled(), path)
+			if test.wantErr {
+				if !diags.HasErrors() {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %v", diags.Err())
+			}
+			if got := got.GetAttr("outputs"); !test.want.RawEquals(got) {
+				t.Errorf("wrong outputs\ngot:  %swant: %s", ctydebug.ValueString(got), ctydebug.ValueString(test.want))
+			}
+		})
+	}
+}
+
 func TestState_validation(t *testing.T) {
 	// The main test TestState_basic covers both validation and reading of
 	// state snapshots, so this additional test is here only to verify that
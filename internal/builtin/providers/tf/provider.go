@@ -242,10 +242,14 @@ type providerFunc interface {
 func getProviderFuncs() map[string]providerFunc {
 	decodeTFVars := &decodeTFVarsFunc{}
 	encodeTFVars := &encodeTFVarsFunc{}
+	decodeTFVarsYAML := &decodeTFVarsYAMLFunc{}
+	encodeTFVarsYAML := &encodeTFVarsYAMLFunc{}
 	encodeExpr := &encodeExprFunc{}
 	return map[string]providerFunc{
-		decodeTFVars.Name(): decodeTFVars,
-		encodeTFVars.Name(): encodeTFVars,
-		encodeExpr.Name():   encodeExpr,
+		decodeTFVars.Name():     decodeTFVars,
+		encodeTFVars.Name():     encodeTFVars,
+		decodeTFVarsYAML.Name(): decodeTFVarsYAML,
+		encodeTFVarsYAML.Name(): encodeTFVarsYAML,
+		encodeExpr.Name():       encodeExpr,
 	}
 }
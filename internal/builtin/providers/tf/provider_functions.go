@@ -6,19 +6,25 @@
 package tf
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 
 	"github.com/hashicorp/hcl/v2/hclwrite"
+	"gopkg.in/yaml.v3"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/opentofu/opentofu/internal/configs/yamlbody"
+	"github.com/opentofu/opentofu/internal/configs/yamlconv"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/zclconf/go-cty/cty"
 )
 
 // "decode_tfvars"
 // "encode_tfvars"
+// "decode_tfvars_yaml"
+// "encode_tfvars_yaml"
 // "encode_expr"
 
 // decodeTFVarsFunc decodes a TFVars file content into a cty object
@@ -135,6 +141,140 @@ func (f *encodeTFVarsFunc) Call(args []cty.Value) (cty.Value, error) {
 	return cty.StringVal(string(b)), nil
 }
 
+// decodeTFVarsYAMLFunc decodes a YAML TFVars file content into a cty object
+type decodeTFVarsYAMLFunc struct{}
+
+func (f *decodeTFVarsYAMLFunc) Name() string {
+	return "decode_tfvars_yaml"
+}
+
+func (f *decodeTFVarsYAMLFunc) GetFunctionSpec() providers.FunctionSpec {
+	params := []providers.FunctionParameterSpec{
+		{
+			Name:              "content",
+			Type:              cty.String,
+			Description:       "YAML TFVars file content to decode",
+			DescriptionFormat: providers.TextFormattingPlain,
+		},
+	}
+	return providers.FunctionSpec{
+		Parameters:        params,
+		Return:            cty.DynamicPseudoType,
+		Summary:           "Decode a YAML TFVars file content into an object",
+		Description:       "provider::terraform::decode_tfvars_yaml decodes a YAML TFVars file content into an object",
+		DescriptionFormat: providers.TextFormattingPlain,
+	}
+}
+
+func (f *decodeTFVarsYAMLFunc) Call(args []cty.Value) (cty.Value, error) {
+	varsFileContent := args[0].AsString()
+	file, diag := yamlbody.Parse([]byte(varsFileContent), "")
+	if file == nil || diag.HasErrors() {
+		return cty.NullVal(cty.DynamicPseudoType), wrapDiagErrors(FailedToDecodeError, diag)
+	}
+	attrs, diag := file.Body.JustAttributes()
+	if attrs == nil || diag.HasErrors() {
+		return cty.NullVal(cty.DynamicPseudoType), wrapDiagErrors(FailedToDecodeError, diag)
+	}
+	vals := make(map[string]cty.Value)
+	for name, attr := range attrs {
+		// As for variable definitions files, there is no evaluation context
+		// here, so template strings are decoded as literal strings.
+		val, diag := attr.Expr.Value(nil)
+		if diag.HasErrors() {
+			return cty.NullVal(cty.DynamicPseudoType), wrapDiagErrors(FailedToDecodeError, diag)
+		}
+		vals[name] = val
+	}
+	return cty.ObjectVal(vals), nil
+}
+
+// encodeTFVarsYAMLFunc encodes an object into a string with the same format as a YAML TFVars file
+type encodeTFVarsYAMLFunc struct{}
+
+func (f *encodeTFVarsYAMLFunc) Name() string {
+	return "encode_tfvars_yaml"
+}
+
+func (f *encodeTFVarsYAMLFunc) GetFunctionSpec() providers.FunctionSpec {
+	params := []providers.FunctionParameterSpec{
+		{
+			Name: "input",
+			// The input type is determined at runtime
+			Type:              cty.DynamicPseudoType,
+			Description:       "Input to encode for YAML TFVars file. Must be an object with key that are valid identifiers",
+			DescriptionFormat: providers.TextFormattingPlain,
+		},
+	}
+	return providers.FunctionSpec{
+		Parameters:        params,
+		Return:            cty.String,
+		Summary:           "Encode an object into a string with the same format as a YAML TFVars file",
+		Description:       "provider::terraform::encode_tfvars_yaml encodes an object into a string with the same format as a YAML TFVars file",
+		DescriptionFormat: providers.TextFormattingPlain,
+	}
+}
+
+func (f *encodeTFVarsYAMLFunc) Call(args []cty.Value) (cty.Value, error) {
+	toEncode := args[0]
+	// null is invalid input
+	if toEncode.IsNull() {
+		return cty.NullVal(cty.String), fmt.Errorf("%w: must not be null", InvalidInputError)
+	}
+	if !toEncode.Type().IsObjectType() {
+		return cty.NullVal(cty.String), fmt.Errorf("%w: must be an object", InvalidInputError)
+	}
+	if !toEncode.IsWhollyKnown() {
+		return cty.NullVal(cty.String), UnknownInputError
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	it := toEncode.ElementIterator()
+	for it.Next() {
+		key, val := it.Element()
+		name := key.AsString()
+		if valid := hclsyntax.ValidIdentifier(name); !valid {
+			return cty.NullVal(cty.String), fmt.Errorf("%w: object key: %s - must be a valid identifier", InvalidInputError, name)
+		}
+		root.Content = append(root.Content, yamlconv.StringNode(name), yamlValueNode(val))
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return cty.NullVal(cty.String), fmt.Errorf("%w: %w", InvalidInputError, err)
+	}
+	if err := enc.Close(); err != nil {
+		return cty.NullVal(cty.String), fmt.Errorf("%w: %w", InvalidInputError, err)
+	}
+	return cty.StringVal(buf.String()), nil
+}
+
+// yamlValueNode converts a known value to a YAML node that decodes back to
+// an equivalent value.
+func yamlValueNode(val cty.Value) *yaml.Node {
+	ty := val.Type()
+	switch {
+	case val.IsNull() || ty.IsPrimitiveType():
+		return yamlconv.LiteralNode(val)
+	case ty.IsObjectType() || ty.IsMapType():
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			node.Content = append(node.Content, yamlconv.StringNode(k.AsString()), yamlValueNode(v))
+		}
+		return node
+	default:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			node.Content = append(node.Content, yamlValueNode(v))
+		}
+		return node
+	}
+}
+
 // encodeExprFunc encodes an expression into a string
 type encodeExprFunc struct{}
 
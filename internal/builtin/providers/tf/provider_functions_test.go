@@ -330,3 +330,208 @@ func TestEncodeExprFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeTFVarsYAMLFunc(t *testing.T) {
+	tests := []test{
+		{
+			name: "basic test",
+			arg:  cty.StringVal("test: 2\n"),
+			want: cty.ObjectVal(map[string]cty.Value{
+				"test": cty.NumberIntVal(2),
+			}),
+		},
+		{
+			name: "empty document",
+			arg:  cty.StringVal(""),
+			want: cty.EmptyObjectVal,
+		},
+		{
+			name: "object and list",
+			arg: cty.StringVal(`
+test:
+  k: v
+list:
+  - i1
+  - 3
+`),
+			want: cty.ObjectVal(map[string]cty.Value{
+				"test": cty.ObjectVal(map[string]cty.Value{
+					"k": cty.StringVal("v"),
+				}),
+				"list": cty.TupleVal([]cty.Value{
+					cty.StringVal("i1"),
+					cty.NumberIntVal(3),
+				}),
+			}),
+		},
+		{
+			name: "template strings are literals",
+			arg:  cty.StringVal("test: ${not_interpolated}\n"),
+			want: cty.ObjectVal(map[string]cty.Value{
+				"test": cty.StringVal("${not_interpolated}"),
+			}),
+		},
+		{
+			name:          "invalid YAML",
+			arg:           cty.StringVal("test: [\n"),
+			want:          cty.NilVal,
+			expectedError: FailedToDecodeError,
+		},
+		{
+			name:          "not a mapping",
+			arg:           cty.StringVal("- test\n"),
+			want:          cty.NilVal,
+			expectedError: FailedToDecodeError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decodeTFVarsYAML := &decodeTFVarsYAMLFunc{}
+			got, err := decodeTFVarsYAML.Call([]cty.Value{tt.arg})
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("Call() error = %v, expected %v", err, tt.expectedError)
+			}
+			if err != nil {
+				return
+			}
+			if !got.RawEquals(tt.want) {
+				t.Errorf("Call() got: %#v, want: %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeTFVarsYAMLFunc(t *testing.T) {
+	tests := []test{
+		{
+			name: "basic test",
+			arg: cty.ObjectVal(map[string]cty.Value{
+				"test":  cty.NumberIntVal(2),
+				"float": cty.NumberFloatVal(1.5),
+				"bool":  cty.True,
+				"null":  cty.NullVal(cty.String),
+			}),
+			want: cty.StringVal("bool: true\nfloat: 1.5\n!literal null: null\ntest: 2\n"),
+		},
+		{
+			name: "nested values",
+			arg: cty.ObjectVal(map[string]cty.Value{
+				"test": cty.ObjectVal(map[string]cty.Value{
+					"k": cty.StringVal("v"),
+				}),
+				"list": cty.ListVal([]cty.Value{
+					cty.StringVal("i1"),
+					cty.StringVal("i2"),
+				}),
+			}),
+			want: cty.StringVal("list:\n  - i1\n  - i2\ntest:\n  k: v\n"),
+		},
+		{
+			name: "strings that look like other types",
+			arg: cty.ObjectVal(map[string]cty.Value{
+				"version": cty.StringVal("1.10"),
+				"enabled": cty.StringVal("true"),
+			}),
+			want: cty.StringVal("enabled: \"true\"\nversion: \"1.10\"\n"),
+		},
+		{
+			name: "null-like strings are literals",
+			arg: cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("null"),
+				"b": cty.StringVal("~"),
+			}),
+			want: cty.StringVal("a: !literal null\nb: !literal ~\n"),
+		},
+		{
+			name: "template strings are literals",
+			arg: cty.ObjectVal(map[string]cty.Value{
+				"test": cty.StringVal("${not_interpolated}"),
+			}),
+			want: cty.StringVal("test: !literal ${not_interpolated}\n"),
+		},
+		{
+			name:          "null input",
+			arg:           cty.NullVal(cty.DynamicPseudoType),
+			want:          cty.NilVal,
+			expectedError: InvalidInputError,
+		},
+		{
+			name:          "invalid input: not an object",
+			arg:           cty.StringVal("test"),
+			want:          cty.NilVal,
+			expectedError: InvalidInputError,
+		},
+		{
+			name:          "invalid input: Object with invalid key",
+			arg:           cty.ObjectVal(map[string]cty.Value{"7*7": cty.StringVal("test")}),
+			want:          cty.NilVal,
+			expectedError: InvalidInputError,
+		},
+		{
+			name:          "unknown input",
+			arg:           cty.ObjectVal(map[string]cty.Value{"test": cty.UnknownVal(cty.String)}),
+			want:          cty.NilVal,
+			expectedError: UnknownInputError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encodeTFVarsYAML := &encodeTFVarsYAMLFunc{}
+			got, err := encodeTFVarsYAML.Call([]cty.Value{tt.arg})
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("Call() error = %v, expected %v", err, tt.expectedError)
+			}
+			if err != nil {
+				return
+			}
+			if !got.RawEquals(tt.want) {
+				t.Errorf("Call() got:\n%s\nwant:\n%s", got.AsString(), tt.want.AsString())
+			}
+		})
+	}
+}
+
+func TestEncodeDecodeTFVarsYAMLRoundTrip(t *testing.T) {
+	tests := map[string]cty.Value{
+		"mixed values": cty.ObjectVal(map[string]cty.Value{
+			"name":      cty.StringVal("example"),
+			"version":   cty.StringVal("1.10"),
+			"template":  cty.StringVal("${var.name}-%{ if true }x%{ endif }"),
+			"multiline": cty.StringVal("first\nsecond\n"),
+			"count":     cty.NumberIntVal(3),
+			"tags": cty.ObjectVal(map[string]cty.Value{
+				"env": cty.StringVal("test"),
+			}),
+		}),
+		"null-like strings": cty.ObjectVal(map[string]cty.Value{
+			"a": cty.StringVal("null"),
+			"b": cty.StringVal("~"),
+			"c": cty.TupleVal([]cty.Value{cty.StringVal("null"), cty.StringVal("~")}),
+		}),
+		"null-like keys": cty.ObjectVal(map[string]cty.Value{
+			"null": cty.StringVal("top-level"),
+			"nested": cty.ObjectVal(map[string]cty.Value{
+				"null": cty.StringVal("a"),
+				"~":    cty.StringVal("b"),
+			}),
+		}),
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := (&encodeTFVarsYAMLFunc{}).Call([]cty.Value{input})
+			if err != nil {
+				t.Fatalf("unexpected encode error: %s", err)
+			}
+			decoded, err := (&decodeTFVarsYAMLFunc{}).Call([]cty.Value{encoded})
+			if err != nil {
+				t.Fatalf("unexpected decode error: %s\nencoded:\n%s", err, encoded.AsString())
+			}
+			if !decoded.RawEquals(input) {
+				t.Errorf("round trip changed the value\ngot:  %#v\nwant: %#v\nencoded:\n%s", decoded, input, encoded.AsString())
+			}
+		})
+	}
+}
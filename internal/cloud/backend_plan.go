@@ -576,6 +576,9 @@ func maybeWriteGeneratedConfig(plan *jsonformat.Plan, out string) (diags tfdiags
 				return diags.Append(moreDiags)
 			}
 		}
+		if moreDiags := genconfig.CloseConfigWriter(writer, out); moreDiags.HasErrors() {
+			return diags.Append(moreDiags)
+		}
 	}
 
 	return diags
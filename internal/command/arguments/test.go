@@ -6,6 +6,8 @@
 package arguments
 
 import (
+	"strings"
+
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
@@ -32,6 +34,43 @@ type Test struct {
 	// human-readable format or JSON for each run step depending on the
 	// ViewType.
 	Verbose bool
+
+	// FileParallelism is the number of test files that can be executed
+	// concurrently. Test files that use the same configuration, and so
+	// would manage the same infrastructure, are never executed concurrently.
+	FileParallelism int
+
+	// JUnitXMLPath, if set, is the path of a file to write a JUnit XML
+	// report of the test results to, in addition to the usual output.
+	JUnitXMLPath string
+
+	// RecordProviders tells the test command to record the interactions with
+	// providers for each run block into fixture files, and ReplayProviders
+	// tells it to serve those interactions from the fixture files instead of
+	// calling the real providers. At most one of these can be set.
+	RecordProviders bool
+	ReplayProviders bool
+
+	// Coverage tells the test command to print a summary of the
+	// configuration objects that the run blocks exercised, and CoveragePath,
+	// if set, is the path of a file to write a detailed JSON coverage report
+	// to. Coverage is only collected if at least one of these is set.
+	Coverage     bool
+	CoveragePath string
+
+	// UpdateSnapshots tells the test command to rewrite the plan snapshots of
+	// run blocks that use them, instead of comparing the plans against them.
+	UpdateSnapshots bool
+
+	// RunOnly, if set, is a test file and the name of a run block within it
+	// separated by a colon. The test command executes only that file, stops
+	// after the named run block, and keeps the resulting states on disk
+	// instead of destroying them.
+	RunOnly string
+
+	// DestroyKeptState tells the test command to destroy the states that were
+	// kept by a previous execution with RunOnly, instead of executing tests.
+	DestroyKeptState bool
 }
 
 func ParseTest(args []string) (*Test, tfdiags.Diagnostics) {
@@ -47,6 +86,15 @@ func ParseTest(args []string) (*Test, tfdiags.Diagnostics) {
 	cmdFlags.StringVar(&test.TestDirectory, "test-directory", configs.DefaultTestDirectory, "test-directory")
 	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
 	cmdFlags.BoolVar(&test.Verbose, "verbose", false, "verbose")
+	cmdFlags.StringVar(&test.JUnitXMLPath, "junit-xml", "", "junit-xml")
+	cmdFlags.IntVar(&test.FileParallelism, "file-parallelism", 1, "file-parallelism")
+	cmdFlags.BoolVar(&test.RecordProviders, "record-providers", false, "record-providers")
+	cmdFlags.BoolVar(&test.ReplayProviders, "replay-providers", false, "replay-providers")
+	cmdFlags.BoolVar(&test.Coverage, "coverage", false, "coverage")
+	cmdFlags.StringVar(&test.CoveragePath, "coverage-out", "", "coverage-out")
+	cmdFlags.BoolVar(&test.UpdateSnapshots, "update-snapshots", false, "update-snapshots")
+	cmdFlags.StringVar(&test.RunOnly, "run-only", "", "run-only")
+	cmdFlags.BoolVar(&test.DestroyKeptState, "destroy-kept-state", false, "destroy-kept-state")
 
 	if err := cmdFlags.Parse(args); err != nil {
 		diags = diags.Append(tfdiags.Sourceless(
@@ -55,6 +103,40 @@ func ParseTest(args []string) (*Test, tfdiags.Diagnostics) {
 			err.Error()))
 	}
 
+	if test.FileParallelism < 1 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid file parallelism",
+			"The -file-parallelism option must be at least 1."))
+		test.FileParallelism = 1
+	}
+
+	if test.RecordProviders && test.ReplayProviders {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command-line flags",
+			"The -record-providers and -replay-providers options are mutually exclusive."))
+		test.ReplayProviders = false
+	}
+
+	if test.RunOnly != "" {
+		if file, run, ok := strings.Cut(test.RunOnly, ":"); !ok || file == "" || run == "" {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid run-only target",
+				"The -run-only option must be a test file and the name of a run block within it, separated by a colon. For example, -run-only=tests/main.tftest.hcl:setup."))
+			test.RunOnly = ""
+		}
+	}
+
+	if test.RunOnly != "" && (len(test.Filter) > 0 || test.DestroyKeptState) {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command-line flags",
+			"The -run-only option cannot be used with the -filter or -destroy-kept-state options."))
+		test.RunOnly = ""
+	}
+
 	switch {
 	case jsonOutput:
 		test.ViewType = ViewJSON
@@ -76,60 +76,230 @@ func TestParseTest(t *testing.T) {
 		"defaults": {
 			args: nil,
 			want: &Test{
-				Filter:        nil,
-				TestDirectory: "tests",
-				ViewType:      ViewHuman,
-				Vars:          &Vars{},
+				Filter:          nil,
+				TestDirectory:   "tests",
+				FileParallelism: 1,
+				ViewType:        ViewHuman,
+				Vars:            &Vars{},
 			},
 			wantDiags: nil,
 		},
 		"with-filters": {
 			args: []string{"-filter=one.tftest.hcl", "-filter=two.tftest.hcl"},
 			want: &Test{
-				Filter:        []string{"one.tftest.hcl", "two.tftest.hcl"},
-				TestDirectory: "tests",
-				ViewType:      ViewHuman,
-				Vars:          &Vars{},
+				Filter:          []string{"one.tftest.hcl", "two.tftest.hcl"},
+				TestDirectory:   "tests",
+				FileParallelism: 1,
+				ViewType:        ViewHuman,
+				Vars:            &Vars{},
 			},
 			wantDiags: nil,
 		},
 		"json": {
 			args: []string{"-json"},
 			want: &Test{
-				Filter:        nil,
-				TestDirectory: "tests",
-				ViewType:      ViewJSON,
-				Vars:          &Vars{},
+				Filter:          nil,
+				TestDirectory:   "tests",
+				FileParallelism: 1,
+				ViewType:        ViewJSON,
+				Vars:            &Vars{},
 			},
 			wantDiags: nil,
 		},
 		"test-directory": {
 			args: []string{"-test-directory=other"},
 			want: &Test{
-				Filter:        nil,
-				TestDirectory: "other",
-				ViewType:      ViewHuman,
-				Vars:          &Vars{},
+				Filter:          nil,
+				TestDirectory:   "other",
+				FileParallelism: 1,
+				ViewType:        ViewHuman,
+				Vars:            &Vars{},
 			},
 			wantDiags: nil,
 		},
 		"verbose": {
 			args: []string{"-verbose"},
 			want: &Test{
-				Filter:        nil,
-				TestDirectory: "tests",
-				ViewType:      ViewHuman,
-				Verbose:       true,
-				Vars:          &Vars{},
+				Filter:          nil,
+				TestDirectory:   "tests",
+				FileParallelism: 1,
+				ViewType:        ViewHuman,
+				Verbose:         true,
+				Vars:            &Vars{},
+			},
+		},
+		"junit-xml": {
+			args: []string{"-junit-xml=results.xml"},
+			want: &Test{
+				Filter:          nil,
+				TestDirectory:   "tests",
+				FileParallelism: 1,
+				ViewType:        ViewHuman,
+				JUnitXMLPath:    "results.xml",
+				Vars:            &Vars{},
+			},
+		},
+		"file-parallelism": {
+			args: []string{"-file-parallelism=4"},
+			want: &Test{
+				Filter:          nil,
+				TestDirectory:   "tests",
+				FileParallelism: 4,
+				ViewType:        ViewHuman,
+				Vars:            &Vars{},
+			},
+		},
+		"invalid file-parallelism": {
+			args: []string{"-file-parallelism=0"},
+			want: &Test{
+				Filter:          nil,
+				TestDirectory:   "tests",
+				FileParallelism: 1,
+				ViewType:        ViewHuman,
+				Vars:            &Vars{},
+			},
+			wantDiags: tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid file parallelism",
+					"The -file-parallelism option must be at least 1.",
+				),
+			},
+		},
+		"coverage": {
+			args: []string{"-coverage", "-coverage-out=coverage.json"},
+			want: &Test{
+				Filter:          nil,
+				TestDirectory:   "tests",
+				FileParallelism: 1,
+				Coverage:        true,
+				CoveragePath:    "coverage.json",
+				ViewType:        ViewHuman,
+				Vars:            &Vars{},
+			},
+			wantDiags: nil,
+		},
+		"update-snapshots": {
+			args: []string{"-update-snapshots"},
+			want: &Test{
+				Filter:          nil,
+				TestDirectory:   "tests",
+				FileParallelism: 1,
+				UpdateSnapshots: true,
+				ViewType:        ViewHuman,
+				Vars:            &Vars{},
+			},
+			wantDiags: nil,
+		},
+		"run-only": {
+			args: []string{"-run-only=tests/main.tftest.hcl:setup"},
+			want: &Test{
+				Filter:          nil,
+				TestDirectory:   "tests",
+				FileParallelism: 1,
+				RunOnly:         "tests/main.tftest.hcl:setup",
+				ViewType:        ViewHuman,
+				Vars:            &Vars{},
+			},
+			wantDiags: nil,
+		},
+		"invalid run-only": {
+			args: []string{"-run-only=setup"},
+			want: &Test{
+				Filter:          nil,
+				TestDirectory:   "tests",
+				FileParallelism: 1,
+				ViewType:        ViewHuman,
+				Vars:            &Vars{},
+			},
+			wantDiags: tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid run-only target",
+					"The -run-only option must be a test file and the name of a run block within it, separated by a colon. For example, -run-only=tests/main.tftest.hcl:setup.",
+				),
+			},
+		},
+		"run-only with destroy-kept-state": {
+			args: []string{"-run-only=main.tftest.hcl:setup", "-destroy-kept-state"},
+			want: &Test{
+				Filter:           nil,
+				TestDirectory:    "tests",
+				FileParallelism:  1,
+				DestroyKeptState: true,
+				ViewType:         ViewHuman,
+				Vars:             &Vars{},
+			},
+			wantDiags: tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Incompatible command-line flags",
+					"The -run-only option cannot be used with the -filter or -destroy-kept-state options.",
+				),
+			},
+		},
+		"destroy-kept-state": {
+			args: []string{"-destroy-kept-state"},
+			want: &Test{
+				Filter:           nil,
+				TestDirectory:    "tests",
+				FileParallelism:  1,
+				DestroyKeptState: true,
+				ViewType:         ViewHuman,
+				Vars:             &Vars{},
+			},
+			wantDiags: nil,
+		},
+		"record-providers": {
+			args: []string{"-record-providers"},
+			want: &Test{
+				Filter:          nil,
+				TestDirectory:   "tests",
+				FileParallelism: 1,
+				RecordProviders: true,
+				ViewType:        ViewHuman,
+				Vars:            &Vars{},
+			},
+			wantDiags: nil,
+		},
+		"replay-providers": {
+			args: []string{"-replay-providers"},
+			want: &Test{
+				Filter:          nil,
+				TestDirectory:   "tests",
+				FileParallelism: 1,
+				ReplayProviders: true,
+				ViewType:        ViewHuman,
+				Vars:            &Vars{},
+			},
+			wantDiags: nil,
+		},
+		"record and replay providers": {
+			args: []string{"-record-providers", "-replay-providers"},
+			want: &Test{
+				Filter:          nil,
+				TestDirectory:   "tests",
+				FileParallelism: 1,
+				RecordProviders: true,
+				ViewType:        ViewHuman,
+				Vars:            &Vars{},
+			},
+			wantDiags: tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Incompatible command-line flags",
+					"The -record-providers and -replay-providers options are mutually exclusive.",
+				),
 			},
 		},
 		"unknown flag": {
 			args: []string{"-boop"},
 			want: &Test{
-				Filter:        nil,
-				TestDirectory: "tests",
-				ViewType:      ViewHuman,
-				Vars:          &Vars{},
+				Filter:          nil,
+				TestDirectory:   "tests",
+				FileParallelism: 1,
+				ViewType:        ViewHuman,
+				Vars:            &Vars{},
 			},
 			wantDiags: tfdiags.Diagnostics{
 				tfdiags.Sourceless(
@@ -23,6 +23,15 @@ var completePredictBoolean = complete.PredictSet("true", "false")
 // we'll probably add one later.
 var completePredictModuleSource = complete.PredictAnything
 
+// For completing the path of a -backend-config file, which can be written
+// in either the native syntax or YAML. The value can also be key=value, but
+// we can't "predict" that.
+var completePredictBackendConfig = complete.PredictOr(
+	complete.PredictFiles("*.tfvars"),
+	complete.PredictFiles("*.yaml"),
+	complete.PredictFiles("*.yml"),
+)
+
 type completePredictSequence []complete.Predictor
 
 func (s completePredictSequence) Predict(a complete.Args) []string {
@@ -0,0 +1,328 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/configs/yamlconv"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// convertExts maps each supported target format to the file extensions that
+// can be converted to it and the extension that each is converted to.
+var convertExts = map[string]map[string]string{
+	"yaml": {
+		".tf":   ".tf.yaml",
+		".tofu": ".tofu.yaml",
+	},
+	"hcl": {
+		".tf.yaml":   ".tf",
+		".tf.yml":    ".tf",
+		".tofu.yaml": ".tofu",
+		".tofu.yml":  ".tofu",
+	},
+}
+
+// ConvertCommand is a Command implementation that converts configuration
+// files between the native syntax and YAML.
+type ConvertCommand struct {
+	Meta
+	to    string
+	list  bool
+	write bool
+}
+
+// convertedFile is a configuration file that has been converted in memory
+// but not yet written.
+type convertedFile struct {
+	from, to string
+	mode     os.FileMode
+	result   []byte
+}
+
+func (c *ConvertCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("convert")
+	cmdFlags.StringVar(&c.to, "to", "yaml", "to")
+	cmdFlags.BoolVar(&c.list, "list", true, "list")
+	cmdFlags.BoolVar(&c.write, "write", true, "write")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	exts, ok := convertExts[c.to]
+	if !ok {
+		c.Ui.Error(fmt.Sprintf("Invalid value for -to: %q. The target format must be either \"yaml\" or \"hcl\".\n", c.to))
+		cmdFlags.Usage()
+		return 1
+	}
+
+	paths := cmdFlags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, diags := c.convert(paths, exts)
+	c.showDiagnostics(diags)
+	if diags.HasErrors() {
+		// We don't write anything unless every file could be converted, so
+		// that a module is never left with a mix of converted and
+		// unconverted files.
+		return 2
+	}
+
+	if !c.write {
+		for _, f := range files {
+			c.Ui.Output(string(f.result))
+		}
+		return 0
+	}
+
+	if err := writeConvertedFiles(files); err != nil {
+		c.showDiagnostics(err)
+		return 1
+	}
+	if c.list {
+		for _, f := range files {
+			c.Ui.Output(fmt.Sprintf("%s -> %s", f.from, f.to))
+		}
+	}
+
+	return 0
+}
+
+// convert converts each of the selected files in memory, returning the
+// results without writing them.
+func (c *ConvertCommand) convert(paths []string, exts map[string]string) ([]convertedFile, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	var files []convertedFile
+	targets := make(map[string]string)
+
+	for _, path := range paths {
+		path = c.normalizePath(path)
+		info, err := os.Stat(path)
+		if err != nil {
+			diags = diags.Append(fmt.Errorf("No file or directory at %s", path))
+			return nil, diags
+		}
+
+		var candidates []string
+		if info.IsDir() {
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				diags = diags.Append(fmt.Errorf("Cannot read directory %s", path))
+				return nil, diags
+			}
+			// We do not recurse into child directories because, as with
+			// "tofu fmt", we operate on one module at a time.
+			for _, entry := range entries {
+				name := entry.Name()
+				if entry.IsDir() || configs.IsIgnoredFile(name) || convertTarget(name, exts) == "" {
+					continue
+				}
+				candidates = append(candidates, filepath.Join(path, name))
+			}
+		} else {
+			if convertTarget(path, exts) == "" {
+				diags = diags.Append(fmt.Errorf("Only %s files can be converted with -to=%s", convertExtList(exts), c.to))
+				continue
+			}
+			candidates = append(candidates, path)
+		}
+
+		for _, from := range candidates {
+			to := convertTarget(from, exts)
+			if other, exists := targets[to]; exists {
+				diags = diags.Append(fmt.Errorf("Both %s and %s would be converted to %s", other, from, to))
+				continue
+			}
+			targets[to] = from
+			if _, err := os.Stat(to); err == nil {
+				diags = diags.Append(fmt.Errorf("Cannot convert %s because %s already exists", from, to))
+				continue
+			}
+
+			f, fileDiags := c.convertFile(from, to)
+			diags = diags.Append(fileDiags)
+			if !fileDiags.HasErrors() {
+				files = append(files, f)
+			}
+		}
+	}
+
+	return files, diags
+}
+
+func (c *ConvertCommand) convertFile(from, to string) (convertedFile, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	log.Printf("[TRACE] tofu convert: Converting %s to %s", from, to)
+
+	info, err := os.Stat(from)
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to read file %s", from))
+		return convertedFile{}, diags
+	}
+	src, err := os.ReadFile(from)
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to read file %s", from))
+		return convertedFile{}, diags
+	}
+
+	// Register this path as a synthetic configuration source, so that any
+	// diagnostics can include the source code snippet
+	c.registerSynthConfigSource(from, src)
+
+	var result []byte
+	var convDiags hcl.Diagnostics
+	if c.to == "yaml" {
+		result, convDiags = yamlconv.ToYAML(src, from)
+	} else {
+		result, convDiags = yamlconv.FromYAML(src, from)
+	}
+	diags = diags.Append(convDiags)
+
+	return convertedFile{
+		from:   from,
+		to:     to,
+		mode:   info.Mode().Perm(),
+		result: result,
+	}, diags
+}
+
+// writeConvertedFiles writes the converted files and then removes the
+// originals, since both would declare the same objects.
+//
+// All of the results are first written to temporary files alongside their
+// targets, which are only renamed into place once every one of them has
+// been written. The original files are only removed once every target is in
+// place, so a failure part way through leaves the module as it was.
+func writeConvertedFiles(files []convertedFile) error {
+	temps := make([]string, 0, len(files))
+	removeTemps := func() {
+		for _, temp := range temps {
+			os.Remove(temp)
+		}
+	}
+
+	for _, f := range files {
+		// The leading dot makes OpenTofu ignore the temporary file if it is
+		// left behind.
+		tmp, err := os.CreateTemp(filepath.Dir(f.to), "."+filepath.Base(f.to)+".*")
+		if err != nil {
+			removeTemps()
+			return fmt.Errorf("Failed to write %s: %w", f.to, err)
+		}
+		temps = append(temps, tmp.Name())
+		_, err = tmp.Write(f.result)
+		if err == nil {
+			err = tmp.Chmod(f.mode)
+		}
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			removeTemps()
+			return fmt.Errorf("Failed to write %s: %w", f.to, err)
+		}
+	}
+
+	for i, f := range files {
+		if err := os.Rename(temps[i], f.to); err != nil {
+			// The targets didn't exist before, so undoing the renames that
+			// have already happened restores the module.
+			for _, done := range files[:i] {
+				os.Remove(done.to)
+			}
+			temps = temps[i:]
+			removeTemps()
+			return fmt.Errorf("Failed to write %s: %w", f.to, err)
+		}
+	}
+
+	for _, f := range files {
+		if err := os.Remove(f.from); err != nil {
+			return fmt.Errorf("Failed to remove %s after writing %s: %w", f.from, f.to, err)
+		}
+	}
+	return nil
+}
+
+// convertTarget returns the path that the given file would be converted to,
+// or an empty string if the file can't be converted.
+func convertTarget(path string, exts map[string]string) string {
+	// Check the longest extensions first so that, for example, ".tf.yaml"
+	// isn't mistaken for some other extension that it ends with.
+	var best string
+	for ext := range exts {
+		if strings.HasSuffix(path, ext) && len(ext) > len(best) {
+			best = ext
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return strings.TrimSuffix(path, best) + exts[best]
+}
+
+func convertExtList(exts map[string]string) string {
+	list := make([]string, 0, len(exts))
+	for ext := range exts {
+		list = append(list, ext)
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
+
+func (c *ConvertCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] convert [options] [target...]
+
+  Converts configuration files between the OpenTofu language native syntax
+  and YAML.
+
+  By default, convert scans the current directory for configuration files.
+  If you provide a directory for the target argument, convert will scan that
+  directory instead. If you provide a file, convert will process just that
+  file. Subdirectories are not processed.
+
+  Each converted file replaces the original: with -to=yaml, main.tf becomes
+  main.tf.yaml, and with -to=hcl, main.tf.yaml becomes main.tf. Test files,
+  variable definitions files and JSON files are not converted.
+
+  Comments are preserved. If any file contains a construct that cannot be
+  represented in the target format without changing its meaning, convert
+  reports an error and no files are changed. Warnings identify results that
+  are valid but that should be reviewed, such as arguments that might have
+  been intended as nested blocks.
+
+Options:
+
+  -to=yaml       The format to convert to, either "yaml" (the default) or
+                 "hcl".
+
+  -list=false    Don't list the files that were converted.
+
+  -write=false   Don't write the converted files, and print the converted
+                 content instead.
+
+  -no-color      If specified, output won't contain any color.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConvertCommand) Synopsis() string {
+	return "Convert configuration files between HCL and YAML"
+}
@@ -0,0 +1,276 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+)
+
+const convertFixtureHCL = `# The name to greet
+variable "name" {
+  type    = string
+  default = "world"
+}
+
+output "greeting" {
+  value = "Hello, ${var.name}!" # interpolated
+}
+`
+
+const convertFixtureYAML = `variable:
+  # The name to greet
+  name:
+    type: !ref string
+    default: world
+
+output:
+  greeting:
+    value: Hello, ${var.name}! # interpolated
+`
+
+func convertFixtureWriteDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func testConvertCommand(ui cli.Ui) *ConvertCommand {
+	return &ConvertCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+}
+
+func TestConvert_toYAML(t *testing.T) {
+	tempDir := convertFixtureWriteDir(t, map[string]string{
+		"main.tf":            convertFixtureHCL,
+		"main.tftest.hcl":    "run \"a\" {}\n",
+		"terraform.tfvars":   "name = \"x\"\n",
+		"existing.tf.yaml":   "locals: {}\n",
+		"override.tf.json":   "{}\n",
+		".hidden.tf":         "not valid",
+		"other.tofu":         "locals {\n  a = 1\n}\n",
+		"other.tofu.example": "",
+	})
+
+	ui := cli.NewMockUi()
+	c := testConvertCommand(ui)
+	if code := c.Run([]string{tempDir}); code != 0 {
+		t.Fatalf("wrong exit code. errors: \n%s", ui.ErrorWriter.String())
+	}
+
+	for _, name := range []string{"main.tf", "other.tofu"} {
+		if _, err := os.Stat(filepath.Join(tempDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", name)
+		}
+	}
+	for _, name := range []string{"main.tftest.hcl", "terraform.tfvars", "override.tf.json", ".hidden.tf"} {
+		if _, err := os.Stat(filepath.Join(tempDir, name)); err != nil {
+			t.Errorf("%s should not have been converted: %s", name, err)
+		}
+	}
+
+	got, err := os.ReadFile(filepath.Join(tempDir, "main.tf.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(convertFixtureYAML, string(got)); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "other.tofu.yaml")); err != nil {
+		t.Errorf("other.tofu was not converted: %s", err)
+	}
+
+	output := ui.OutputWriter.String()
+	for _, want := range []string{"main.tf -> ", "other.tofu -> "} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output does not include %q\n%s", want, output)
+		}
+	}
+}
+
+func TestConvert_toHCL(t *testing.T) {
+	tempDir := convertFixtureWriteDir(t, map[string]string{
+		"main.tf.yaml": convertFixtureYAML,
+	})
+
+	ui := cli.NewMockUi()
+	c := testConvertCommand(ui)
+	if code := c.Run([]string{"-to=hcl", tempDir}); code != 0 {
+		t.Fatalf("wrong exit code. errors: \n%s", ui.ErrorWriter.String())
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "main.tf.yaml")); !os.IsNotExist(err) {
+		t.Errorf("main.tf.yaml was not removed")
+	}
+	got, err := os.ReadFile(filepath.Join(tempDir, "main.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(convertFixtureHCL, string(got)); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestConvert_noWrite(t *testing.T) {
+	tempDir := convertFixtureWriteDir(t, map[string]string{
+		"main.tf": convertFixtureHCL,
+	})
+
+	ui := cli.NewMockUi()
+	c := testConvertCommand(ui)
+	if code := c.Run([]string{"-write=false", filepath.Join(tempDir, "main.tf")}); code != 0 {
+		t.Fatalf("wrong exit code. errors: \n%s", ui.ErrorWriter.String())
+	}
+
+	if got := ui.OutputWriter.String(); !strings.Contains(got, convertFixtureYAML) {
+		t.Errorf("wrong output\ngot:\n%s\nwant:\n%s", got, convertFixtureYAML)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "main.tf")); err != nil {
+		t.Errorf("main.tf should not have been removed: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "main.tf.yaml")); !os.IsNotExist(err) {
+		t.Errorf("main.tf.yaml should not have been written")
+	}
+}
+
+func TestConvert_errors(t *testing.T) {
+	tests := map[string]struct {
+		files map[string]string
+		args  []string
+		want  string
+	}{
+		"syntax error": {
+			files: map[string]string{
+				"main.tf":  convertFixtureHCL,
+				"other.tf": `resource "a" "b" {`,
+			},
+			want: "Unclosed configuration block",
+		},
+		"not round-trippable": {
+			files: map[string]string{
+				"main.tf": convertFixtureHCL,
+				"other.tf": `resource "a" "b" {
+  provisioner "local-exec" {}
+  provisioner "remote-exec" {}
+  provisioner "local-exec" {}
+}
+`,
+			},
+			want: "Block order cannot be preserved",
+		},
+		"destination exists": {
+			files: map[string]string{
+				"main.tf":      convertFixtureHCL,
+				"main.tf.yaml": convertFixtureYAML,
+			},
+			want: "already exists",
+		},
+		"same destination": {
+			files: map[string]string{
+				"main.tf.yaml": convertFixtureYAML,
+				"main.tf.yml":  convertFixtureYAML,
+			},
+			args: []string{"-to=hcl"},
+			want: "would be converted to",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tempDir := convertFixtureWriteDir(t, test.files)
+
+			ui := cli.NewMockUi()
+			c := testConvertCommand(ui)
+			if code := c.Run(append(test.args, tempDir)); code != 2 {
+				t.Fatalf("wrong exit code %d; want 2\n%s", code, ui.ErrorWriter.String())
+			}
+			if got := ui.ErrorWriter.String(); !strings.Contains(got, test.want) {
+				t.Errorf("errors do not include %q\n%s", test.want, got)
+			}
+
+			// No files are changed unless every file can be converted.
+			entries, err := os.ReadDir(tempDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(test.files) {
+				t.Errorf("files were changed despite errors")
+			}
+			for name, want := range test.files {
+				got, err := os.ReadFile(filepath.Join(tempDir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != want {
+					t.Errorf("%s was changed despite errors", name)
+				}
+			}
+		})
+	}
+}
+
+func TestConvert_writeFailure(t *testing.T) {
+	tempDir := convertFixtureWriteDir(t, map[string]string{
+		"main.tf":  convertFixtureHCL,
+		"other.tf": convertFixtureHCL,
+	})
+	files := []convertedFile{
+		{
+			from:   filepath.Join(tempDir, "main.tf"),
+			to:     filepath.Join(tempDir, "main.tf.yaml"),
+			mode:   0644,
+			result: []byte(convertFixtureYAML),
+		},
+		{
+			// The target directory doesn't exist, so this file can't be
+			// written.
+			from:   filepath.Join(tempDir, "other.tf"),
+			to:     filepath.Join(tempDir, "missing", "other.tf.yaml"),
+			mode:   0644,
+			result: []byte(convertFixtureYAML),
+		},
+	}
+
+	if err := writeConvertedFiles(files); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// The module is left exactly as it was.
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if diff := cmp.Diff([]string{"main.tf", "other.tf"}, names); diff != "" {
+		t.Errorf("wrong files after failed write\n%s", diff)
+	}
+}
+
+func TestConvert_invalidTarget(t *testing.T) {
+	ui := cli.NewMockUi()
+	c := testConvertCommand(ui)
+	if code := c.Run([]string{"-to=json"}); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "Invalid value for -to"; !strings.Contains(got, want) {
+		t.Errorf("errors do not include %q\n%s", want, got)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
@@ -142,7 +143,7 @@ func (c *FmtCommand) fmt(paths []string, stdin io.Reader, stdout io.Writer) tfdi
 			diags = diags.Append(dirDiags)
 		} else {
 			fmtd := false
-			for _, ext := range fmtSupportedExts {
+			for _, ext := range slices.Concat(fmtSupportedExts, fmtYAMLSupportedExts) {
 				if strings.HasSuffix(path, ext) {
 					f, err := os.Open(path)
 					if err != nil {
@@ -165,7 +166,7 @@ func (c *FmtCommand) fmt(paths []string, stdin io.Reader, stdout io.Writer) tfdi
 			}
 
 			if !fmtd {
-				diags = diags.Append(fmt.Errorf("Only .tf, .tfvars, and .tftest.hcl files can be processed with tofu fmt"))
+				diags = diags.Append(fmt.Errorf("Only .tf, .tfvars, .tftest.hcl, and their .yaml equivalents can be processed with tofu fmt"))
 				continue
 			}
 		}
@@ -189,16 +190,26 @@ func (c *FmtCommand) processFile(path string, r io.Reader, w io.Writer, isStdout
 	// diagnostic errors can include the source code snippet
 	c.registerSynthConfigSource(path, src)
 
-	// File must be parseable as HCL native syntax before we'll try to format
-	// it. If not, the formatter is likely to make drastic changes that would
-	// be hard for the user to undo.
-	_, syntaxDiags := hclsyntax.ParseConfig(src, path, hcl.Pos{Line: 1, Column: 1})
-	if syntaxDiags.HasErrors() {
-		diags = diags.Append(syntaxDiags)
-		return diags
-	}
+	var result []byte
+	if isFmtYAMLFile(path) {
+		var yamlDiags tfdiags.Diagnostics
+		result, yamlDiags = c.formatYAMLSourceCode(src, path)
+		diags = diags.Append(yamlDiags)
+		if yamlDiags.HasErrors() {
+			return diags
+		}
+	} else {
+		// File must be parseable as HCL native syntax before we'll try to format
+		// it. If not, the formatter is likely to make drastic changes that would
+		// be hard for the user to undo.
+		_, syntaxDiags := hclsyntax.ParseConfig(src, path, hcl.Pos{Line: 1, Column: 1})
+		if syntaxDiags.HasErrors() {
+			diags = diags.Append(syntaxDiags)
+			return diags
+		}
 
-	result := c.formatSourceCode(src, path)
+		result = c.formatSourceCode(src, path)
+	}
 
 	if !bytes.Equal(src, result) {
 		// Something was changed
@@ -270,7 +281,7 @@ func (c *FmtCommand) processDir(path string, stdout io.Writer) tfdiags.Diagnosti
 			continue
 		}
 
-		for _, ext := range fmtSupportedExts {
+		for _, ext := range slices.Concat(fmtSupportedExts, fmtYAMLSupportedExts) {
 			if strings.HasSuffix(name, ext) {
 				f, err := os.Open(subPath)
 				if err != nil {
@@ -562,14 +573,17 @@ Usage: tofu [global options] fmt [options] [target...]
   (.tftest.hcl) are updated. JSON files (.tf.json, .tfvars.json, or 
   .tftest.json) are not modified.
 
+  YAML configuration and testing files (.tf.yaml or .tftest.yaml) are
+  rewritten with consistent indentation and quoting, keeping their comments.
+
   By default, fmt scans the current directory for configuration files. If you
   provide a directory for the target argument, then fmt will scan that
   directory instead. If you provide a file, then fmt will process just that
   file. If you provide a single dash ("-"), then fmt will read from standard
   input (STDIN).
 
-  The content must be in the OpenTofu language native syntax; JSON is not
-  supported.
+  The content must be in the OpenTofu language native syntax or YAML; JSON is
+  not supported. Standard input is always read as native syntax.
 
 Options:
 
@@ -146,6 +146,77 @@ func TestFmt(t *testing.T) {
 	}
 }
 
+func TestFmt_YAMLFiles(t *testing.T) {
+	const inSuffix = "_in.tf.yaml"
+	const outSuffix = "_out.tf.yaml"
+	const gotSuffix = "_got.tf.yaml"
+	entries, err := os.ReadDir("testdata/fmt-yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, info := range entries {
+		if info.IsDir() {
+			continue
+		}
+		filename := info.Name()
+		if !strings.HasSuffix(filename, inSuffix) {
+			continue
+		}
+		testName := filename[:len(filename)-len(inSuffix)]
+		t.Run(testName, func(t *testing.T) {
+			inFile := filepath.Join("testdata", "fmt-yaml", testName+inSuffix)
+			wantFile := filepath.Join("testdata", "fmt-yaml", testName+outSuffix)
+			gotFile := filepath.Join(tmpDir, testName+gotSuffix)
+			input, err := os.ReadFile(inFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(wantFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = os.WriteFile(gotFile, input, 0700)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ui := cli.NewMockUi()
+			c := &FmtCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(testProvider()),
+					Ui:               ui,
+				},
+			}
+			args := []string{gotFile}
+			if code := c.Run(args); code != 0 {
+				t.Fatalf("fmt command was unsuccessful:\n%s", ui.ErrorWriter.String())
+			}
+
+			got, err := os.ReadFile(gotFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(string(want), string(got)); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+
+			// Formatting an already-formatted file must not change it.
+			ui = cli.NewMockUi()
+			c.Meta.Ui = ui
+			if code := c.Run([]string{"-check", gotFile}); code != 0 {
+				t.Errorf("formatted file is not stable:\n%s", ui.OutputWriter.String())
+			}
+		})
+	}
+}
+
 func TestFmt_nonexist(t *testing.T) {
 	tempDir := fmtFixtureWriteDir(t)
 
@@ -200,6 +271,38 @@ a = 1 +
 	}
 }
 
+func TestFmt_syntaxErrorYAML(t *testing.T) {
+	tempDir := testTempDirRealpath(t)
+
+	invalidSrc := `
+resource:
+  aws_instance: "unclosed
+`
+
+	err := os.WriteFile(filepath.Join(tempDir, "invalid.tf.yaml"), []byte(invalidSrc), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &FmtCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+
+	args := []string{tempDir}
+	if code := c.Run(args); code != 2 {
+		t.Fatalf("wrong exit code. errors: \n%s", ui.ErrorWriter.String())
+	}
+
+	expected := "Invalid YAML syntax"
+	if actual := ui.ErrorWriter.String(); !strings.Contains(actual, expected) {
+		t.Fatalf("expected:\n%s\n\nto include: %q", actual, expected)
+	}
+}
+
 func TestFmt_snippetInError(t *testing.T) {
 	tempDir := testTempDirRealpath(t)
 
@@ -442,6 +545,59 @@ func TestFmt_check(t *testing.T) {
 	}
 }
 
+func TestFmt_checkYAML(t *testing.T) {
+	tempDir := testTempDirRealpath(t)
+
+	files := map[string]string{
+		"formatted.tf.yaml":   "locals:\n  foo: bar\n",
+		"unformatted.tf.yaml": "locals:\n    foo: 'bar'\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ui := new(cli.MockUi)
+	c := &FmtCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+
+	args := []string{
+		"-check",
+		"-diff",
+		tempDir,
+	}
+	if code := c.Run(args); code != 3 {
+		t.Fatalf("wrong exit code. expected 3, got %d", code)
+	}
+
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "unformatted.tf.yaml") {
+		t.Errorf("expected unformatted.tf.yaml to be listed in:\n%s", output)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if filepath.Base(line) == "formatted.tf.yaml" {
+			t.Errorf("expected formatted.tf.yaml not to be listed in:\n%s", output)
+		}
+	}
+	if !strings.Contains(output, "+  foo: bar") {
+		t.Errorf("expected diff in output:\n%s", output)
+	}
+
+	// -check must never write the files.
+	got, err := os.ReadFile(filepath.Join(tempDir, "unformatted.tf.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != files["unformatted.tf.yaml"] {
+		t.Errorf("file was modified by -check:\n%s", got)
+	}
+}
+
 func TestFmt_checkStdin(t *testing.T) {
 	input := new(bytes.Buffer)
 	input.Write(fmtFixture.input)
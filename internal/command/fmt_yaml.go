@@ -0,0 +1,236 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+var (
+	fmtYAMLSupportedExts = []string{
+		".tf.yaml",
+		".tf.yml",
+		".tofu.yaml",
+		".tofu.yml",
+		".tftest.yaml",
+		".tftest.yml",
+		".tofutest.yaml",
+		".tofutest.yml",
+	}
+)
+
+// fmtYAMLIndent is the number of spaces used for each level of nesting in
+// formatted YAML files.
+const fmtYAMLIndent = 2
+
+// isFmtYAMLFile returns true if the given path has one of the extensions
+// that tofu fmt formats as YAML rather than as HCL native syntax.
+func isFmtYAMLFile(path string) bool {
+	for _, ext := range fmtYAMLSupportedExts {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatYAMLSourceCode is the YAML equivalent of formatSourceCode.
+//
+// Each document is decoded into a yaml.Node tree, which retains the head,
+// line and foot comments, and is then encoded again with consistent
+// indentation and quoting. Mapping keys are not reordered because the order
+// of some blocks, such as provisioners, is significant.
+//
+// Unlike formatSourceCode, this returns error diagnostics if the source is
+// not valid YAML, or if the formatted result would not decode to the same
+// values as the original.
+func (c *FmtCommand) formatYAMLSourceCode(src []byte, filename string) ([]byte, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	var docs [][]byte
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid YAML syntax",
+				Detail:   fmt.Sprintf("The file %q contains invalid YAML: %s", filename, err),
+			})
+			return nil, diags
+		}
+
+		formatted, err := formatYAMLDocument(&doc, src)
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to format YAML",
+				Detail:   fmt.Sprintf("The file %q could not be formatted: %s", filename, err),
+			})
+			return nil, diags
+		}
+		docs = append(docs, formatted)
+	}
+
+	if len(docs) == 0 {
+		// An empty file, or one containing only comments, which yaml.v3
+		// doesn't give us any node for. We leave these untouched rather
+		// than discarding the comments.
+		return src, diags
+	}
+
+	return bytes.Join(docs, []byte("---\n")), diags
+}
+
+// formatYAMLDocument encodes a single decoded YAML document in the
+// canonical style, preserving the blank lines that separate mapping entries
+// and sequence items in the original source.
+func formatYAMLDocument(doc *yaml.Node, src []byte) ([]byte, error) {
+	var want any
+	if err := doc.Decode(&want); err != nil {
+		return nil, err
+	}
+
+	spaced := yamlBlankLinesBefore(doc, splitLines(src))
+	normalizeYAMLNode(doc)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(fmtYAMLIndent)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	result := buf.Bytes()
+
+	// yaml.v3 doesn't retain blank lines, so we find the corresponding
+	// nodes in the encoded result and reinsert them. The structure of
+	// the result is the same as the original, so we can walk both trees
+	// in parallel.
+	var out yaml.Node
+	if err := yaml.Unmarshal(result, &out); err != nil {
+		return nil, err
+	}
+	var got any
+	if err := out.Decode(&got); err != nil {
+		return nil, err
+	}
+	if !reflect.DeepEqual(want, got) {
+		return nil, errors.New("the formatted result does not have the same content as the original")
+	}
+
+	var insertBefore []int
+	walkYAMLPairs(doc, &out, func(orig, formatted *yaml.Node) {
+		if _, ok := spaced[orig]; ok {
+			insertBefore = append(insertBefore, yamlNodeFirstLine(formatted))
+		}
+	})
+	return insertBlankLines(result, insertBefore), nil
+}
+
+// normalizeYAMLNode resets the presentation details of a node tree that
+// tofu fmt canonicalizes. Scalars are written without quotes unless
+// quoting is required to retain their type or value, while block scalars,
+// flow collections and explicit tags are retained as written.
+func normalizeYAMLNode(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		node.Style &^= yaml.SingleQuotedStyle | yaml.DoubleQuotedStyle
+		if node.ShortTag() == "!!merge" {
+			// yaml.v3 would otherwise write merge keys as "!!merge <<".
+			node.Tag = ""
+		}
+	}
+	for _, child := range node.Content {
+		normalizeYAMLNode(child)
+	}
+}
+
+// yamlBlankLinesBefore returns the set of mapping keys and sequence items
+// in the given tree that are preceded by a blank line in the source.
+func yamlBlankLinesBefore(doc *yaml.Node, lines []string) map[*yaml.Node]struct{} {
+	ret := make(map[*yaml.Node]struct{})
+	walkYAMLPairs(doc, doc, func(node, _ *yaml.Node) {
+		line := yamlNodeFirstLine(node)
+		// Lines are 1-based, so the line before is at index line-2.
+		if line > 1 && line-2 < len(lines) && strings.TrimSpace(lines[line-2]) == "" {
+			ret[node] = struct{}{}
+		}
+	})
+	return ret
+}
+
+// walkYAMLPairs calls fn for each mapping key and sequence item of two
+// node trees with the same structure, except for the first entry of each
+// collection which never has a blank line before it.
+func walkYAMLPairs(a, b *yaml.Node, fn func(a, b *yaml.Node)) {
+	if a == nil || b == nil || a.Kind != b.Kind || len(a.Content) != len(b.Content) {
+		return
+	}
+
+	step := 1
+	if a.Kind == yaml.MappingNode {
+		step = 2
+	}
+	for i, child := range a.Content {
+		if a.Kind != yaml.DocumentNode && i > 0 && i%step == 0 {
+			fn(child, b.Content[i])
+		}
+		walkYAMLPairs(child, b.Content[i], fn)
+	}
+}
+
+// yamlNodeFirstLine returns the line of the first comment or content
+// belonging to the given node.
+func yamlNodeFirstLine(node *yaml.Node) int {
+	line := node.Line
+	if node.HeadComment != "" {
+		line -= strings.Count(node.HeadComment, "\n") + 1
+	}
+	return line
+}
+
+// insertBlankLines inserts an empty line before each of the given 1-based
+// line numbers, unless there's already one there.
+func insertBlankLines(src []byte, before []int) []byte {
+	if len(before) == 0 {
+		return src
+	}
+	slices.Sort(before)
+	before = slices.Compact(before)
+
+	lines := splitLines(src)
+	var buf bytes.Buffer
+	for i, line := range lines {
+		if _, found := slices.BinarySearch(before, i+1); found && i > 0 && strings.TrimSpace(lines[i-1]) != "" {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(line)
+		if i < len(lines)-1 {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+// splitLines splits src into lines without their trailing newlines.
+func splitLines(src []byte) []string {
+	return strings.Split(string(src), "\n")
+}
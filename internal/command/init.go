@@ -1143,7 +1143,11 @@ func (c *InitCommand) backendConfigOverrideBody(flags rawFlags, schema *configsc
 		eq := strings.Index(item.Value, "=")
 
 		if eq == -1 {
-			// The value is interpreted as a filename.
+			// The value is interpreted as a filename. As with configuration
+			// files, the native syntax is used unless the filename ends with
+			// ".json" or with ".yaml" or ".yml", so that the diagnostics for
+			// each format refer to the correct source locations. A YAML
+			// file must contain a single document.
 			newBody, fileDiags := c.loadHCLFile(item.Value)
 			diags = diags.Append(fileDiags)
 			if fileDiags.HasErrors() {
@@ -1212,7 +1216,7 @@ func (c *InitCommand) AutocompleteFlags() complete.Flags {
 	return complete.Flags{
 		"-backend":        completePredictBoolean,
 		"-cloud":          completePredictBoolean,
-		"-backend-config": complete.PredictFiles("*.tfvars"), // can also be key=value, but we can't "predict" that
+		"-backend-config": completePredictBackendConfig,
 		"-force-copy":     complete.PredictNothing,
 		"-from-module":    completePredictModuleSource,
 		"-get":            completePredictBoolean,
@@ -1255,10 +1259,11 @@ Options:
   -backend-config=path    Configuration to be merged with what is in the
                           configuration file's 'backend' block. This can be
                           either a path to an HCL file with key/value
-                          assignments (same format as terraform.tfvars) or a
-                          'key=value' format, and can be specified multiple
-                          times. The backend type must be in the configuration
-                          itself.
+                          assignments (same format as terraform.tfvars), a
+                          path to a YAML file (.yaml or .yml) with the same
+                          keys, or a 'key=value' format, and can be specified
+                          multiple times. The backend type must be in the
+                          configuration itself.
 
   -compact-warnings       If OpenTofu produces any warnings that are not
                           accompanied by errors, show them in a more compact
@@ -382,6 +382,72 @@ func TestInit_backendConfigFile(t *testing.T) {
 		}
 	})
 
+	// backend config files can also be written in YAML
+	t.Run("good-yaml-config-file", func(t *testing.T) {
+		ui := new(cli.MockUi)
+		view, _ := testView(t)
+		c := &InitCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		}
+		args := []string{"-backend-config", "input.yaml", "-reconfigure"}
+		if code := c.Run(args); code != 0 {
+			t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+		}
+
+		state := testDataStateRead(t, filepath.Join(DefaultDataDir, DefaultStateFilename))
+		if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"hello","workspace_dir":null}`; got != want {
+			t.Errorf("wrong config\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+
+	// diagnostics for YAML backend config files refer to the YAML source
+	t.Run("invalid-yaml-config-file", func(t *testing.T) {
+		ui := new(cli.MockUi)
+		view, _ := testView(t)
+		c := &InitCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		}
+		args := []string{"-backend-config", "invalid.yaml"}
+		if code := c.Run(args); code != 1 {
+			t.Fatalf("expected error, got success\n")
+		}
+		got := ui.ErrorWriter.String()
+		for _, want := range []string{"Extraneous YAML property", "on invalid.yaml line 2"} {
+			if !strings.Contains(got, want) {
+				t.Fatalf("error does not include %q: %s", want, got)
+			}
+		}
+	})
+
+	// a YAML file with more than one document is an error
+	t.Run("multi-document-yaml-config-file", func(t *testing.T) {
+		ui := new(cli.MockUi)
+		view, _ := testView(t)
+		c := &InitCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		}
+		args := []string{"-backend-config", "multi.yaml"}
+		if code := c.Run(args); code != 1 {
+			t.Fatalf("expected error, got success\n")
+		}
+		got := ui.ErrorWriter.String()
+		if want := "Multiple YAML documents"; !strings.Contains(got, want) {
+			t.Fatalf("error does not include %q: %s", want, got)
+		}
+	})
+
 	// missing file is an error
 	t.Run("missing-config-file", func(t *testing.T) {
 		ui := new(cli.MockUi)
@@ -17,6 +17,7 @@ import (
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/tofu"
+	"github.com/spf13/afero"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -435,6 +436,42 @@ func TestMarshalModule(t *testing.T) {
 	}
 }
 
+func TestMarshalModule_yamlComments(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	src := `variable:
+  # The name to greet
+  name: {}
+
+output:
+  # The greeting
+  greeting:
+    value: Hello, ${var.name}!
+`
+	if err := afero.WriteFile(fs, "main.tf.yaml", []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mod, diags := configs.NewParser(fs).LoadConfigDir(".", configs.RootModuleCallForTesting())
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	input := &configs.Config{Module: mod}
+	input.Root = input
+
+	got, err := marshalModule(input, &tofu.Schemas{}, addrs.RootModule.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Comments are used as descriptions for YAML variables and outputs, so
+	// that they are available to documentation generators.
+	if got, want := got.Variables["name"].Description, "The name to greet"; got != want {
+		t.Errorf("wrong variable description %q; want %q", got, want)
+	}
+	if got, want := got.Outputs["greeting"].Description, "The greeting"; got != want {
+		t.Errorf("wrong output description %q; want %q", got, want)
+	}
+}
+
 // ptrTo is a helper to compensate for the fact that Go doesn't allow
 // using the '&' operator unless the operand is directly addressable.
 //
@@ -0,0 +1,211 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonformat
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/opentofu/opentofu/internal/command/jsonformat/computed"
+	"github.com/opentofu/opentofu/internal/command/jsonformat/computed/renderers"
+	"github.com/opentofu/opentofu/internal/command/jsonformat/differ"
+	"github.com/opentofu/opentofu/internal/command/jsonformat/structured"
+	"github.com/opentofu/opentofu/internal/command/jsonstate"
+	"github.com/opentofu/opentofu/internal/plans"
+)
+
+// StateDiff describes the differences between two states, each of them in
+// the same form that RenderHumanState accepts.
+type StateDiff struct {
+	Before State
+	After  State
+}
+
+// StateResourceDiff is the difference between the same resource instance
+// object in the two states of a StateDiff. Before or After is nil if the
+// object only exists in one of the states.
+type StateResourceDiff struct {
+	Address string
+	Deposed string
+
+	Before *jsonstate.Resource
+	After  *jsonstate.Resource
+
+	Diff computed.Diff
+}
+
+// StateOutputDiff is the difference between the same root module output
+// value in the two states of a StateDiff. Before or After is nil if the output
+// only exists in one of the states.
+type StateOutputDiff struct {
+	Name string
+
+	Before *jsonstate.Output
+	After  *jsonstate.Output
+
+	Diff computed.Diff
+}
+
+// Resources returns the resource instance objects that differ between the
+// two states, sorted by address. Objects are matched by their address and,
+// for deposed objects, their deposed key.
+func (diff StateDiff) Resources() []StateResourceDiff {
+	type key struct {
+		address, deposed string
+	}
+	pairs := make(map[key]*StateResourceDiff)
+	var keys []key
+
+	var collect func(module jsonstate.Module, set func(*StateResourceDiff, *jsonstate.Resource))
+	collect = func(module jsonstate.Module, set func(*StateResourceDiff, *jsonstate.Resource)) {
+		for i := range module.Resources {
+			resource := &module.Resources[i]
+			if resource.Mode == jsonstate.EphemeralResourceMode {
+				// Ephemeral resources are never stored in the state.
+				continue
+			}
+			k := key{resource.Address, resource.DeposedKey}
+			if pairs[k] == nil {
+				pairs[k] = &StateResourceDiff{Address: resource.Address, Deposed: resource.DeposedKey}
+				keys = append(keys, k)
+			}
+			set(pairs[k], resource)
+		}
+		for _, child := range module.ChildModules {
+			collect(child, set)
+		}
+	}
+	collect(diff.Before.RootModule, func(d *StateResourceDiff, r *jsonstate.Resource) { d.Before = r })
+	collect(diff.After.RootModule, func(d *StateResourceDiff, r *jsonstate.Resource) { d.After = r })
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].address != keys[j].address {
+			return keys[i].address < keys[j].address
+		}
+		return keys[i].deposed < keys[j].deposed
+	})
+
+	var ret []StateResourceDiff
+	for _, k := range keys {
+		d := pairs[k]
+
+		// We prefer the schema of the newer state, since that is the one
+		// the object would have been upgraded to.
+		state, resource := diff.Before, d.Before
+		if d.After != nil {
+			state, resource = diff.After, d.After
+		}
+		schema := state.GetSchema(*resource)
+		d.Diff = differ.ComputeDiffForBlock(structured.FromJsonResources(d.Before, d.After), schema.Block)
+		if d.Diff.Action == plans.NoOp {
+			continue
+		}
+		ret = append(ret, *d)
+	}
+	return ret
+}
+
+// Outputs returns the root module output values that differ between the two
+// states, sorted by name.
+func (diff StateDiff) Outputs() []StateOutputDiff {
+	var names []string
+	for name := range diff.Before.RootModuleOutputs {
+		names = append(names, name)
+	}
+	for name := range diff.After.RootModuleOutputs {
+		if _, exists := diff.Before.RootModuleOutputs[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var ret []StateOutputDiff
+	for _, name := range names {
+		d := StateOutputDiff{Name: name}
+		if output, exists := diff.Before.RootModuleOutputs[name]; exists {
+			d.Before = &output
+		}
+		if output, exists := diff.After.RootModuleOutputs[name]; exists {
+			d.After = &output
+		}
+		d.Diff = differ.ComputeDiffForOutput(structured.FromJsonOutputs(d.Before, d.After))
+		if d.Diff.Action == plans.NoOp {
+			continue
+		}
+		ret = append(ret, d)
+	}
+	return ret
+}
+
+func (renderer Renderer) RenderHumanStateDiff(diff StateDiff) {
+	resources := diff.Resources()
+	outputs := diff.Outputs()
+
+	if len(resources) == 0 && len(outputs) == 0 {
+		renderer.Streams.Println(renderer.Colorize.Color("[reset][bold][green]No differences.[reset][green] Both states contain the same resource instances and output values.[reset]"))
+		return
+	}
+
+	opts := computed.NewRenderHumanOpts(renderer.Colorize, renderer.ShowSensitive)
+
+	counts := make(map[plans.Action]int)
+	for _, resource := range resources {
+		counts[resource.Diff.Action]++
+
+		renderer.Streams.Println()
+		renderer.Streams.Println(fmt.Sprintf("%s%s %s %s",
+			renderer.Colorize.Color(stateResourceDiffComment(resource)),
+			renderer.Colorize.Color(renderers.DiffActionSymbol(resource.Diff.Action)),
+			stateResourceDiffHeader(resource),
+			resource.Diff.RenderHuman(0, opts)))
+	}
+
+	if len(outputs) > 0 {
+		diffs := make(map[string]computed.Diff, len(outputs))
+		for _, output := range outputs {
+			diffs[output.Name] = output.Diff
+		}
+		renderer.Streams.Print("\nChanges to Outputs:\n")
+		renderer.Streams.Printf("%s\n", renderHumanDiffOutputs(renderer, diffs))
+	}
+
+	renderer.Streams.Printf(
+		renderer.Colorize.Color("\n[bold]Summary:[reset] %d added, %d changed, %d removed.\n"),
+		counts[plans.Create],
+		counts[plans.Update],
+		counts[plans.Delete])
+}
+
+func stateResourceDiffComment(diff StateResourceDiff) string {
+	var buf bytes.Buffer
+
+	dispAddr := diff.Address
+	if len(diff.Deposed) != 0 {
+		dispAddr = fmt.Sprintf("%s (deposed object %s)", dispAddr, diff.Deposed)
+	}
+
+	switch diff.Diff.Action {
+	case plans.Create:
+		buf.WriteString(fmt.Sprintf("[bold]  # %s[reset] was added", dispAddr))
+	case plans.Delete:
+		buf.WriteString(fmt.Sprintf("[bold]  # %s[reset] was removed", dispAddr))
+	default:
+		buf.WriteString(fmt.Sprintf("[bold]  # %s[reset] has changed", dispAddr))
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+func stateResourceDiffHeader(diff StateResourceDiff) string {
+	resource := diff.After
+	if resource == nil {
+		resource = diff.Before
+	}
+	mode := "resource"
+	if resource.Mode != jsonstate.ManagedResourceMode {
+		mode = "data"
+	}
+	return fmt.Sprintf("%s \"%s\" \"%s\"", mode, resource.Type, resource.Name)
+}
@@ -0,0 +1,138 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonformat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/colorstring"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/jsonprovider"
+	"github.com/opentofu/opentofu/internal/command/jsonstate"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/internal/terminal"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+func TestStateDiff(t *testing.T) {
+	before := basicState(t)
+	after := basicState(t)
+
+	provider := addrs.AbsProviderConfig{
+		Provider: addrs.NewDefaultProvider("test"),
+		Module:   addrs.RootModule,
+	}
+	rootModule := after.RootModule()
+	rootModule.SetOutputValue("bar", cty.StringVal("new bar value"), false, "")
+	rootModule.SetOutputValue("secret", cty.StringVal("shh"), true, "")
+	rootModule.SetResourceInstanceCurrent(
+		addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_resource", Name: "baz"}.Instance(addrs.IntKey(0)),
+		&states.ResourceInstanceObjectSrc{Status: states.ObjectReady, AttrsJSON: []byte(`{"woozles":"changed"}`)},
+		provider,
+		addrs.NoKey,
+	)
+	rootModule.SetResourceInstanceCurrent(
+		addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_resource", Name: "baz"}.Instance(addrs.IntKey(1)),
+		&states.ResourceInstanceObjectSrc{Status: states.ObjectReady, AttrsJSON: []byte(`{"woozles":"new"}`)},
+		provider,
+		addrs.NoKey,
+	)
+	rootModule.RemoveResource(addrs.Resource{Mode: addrs.DataResourceMode, Type: "test_data_source", Name: "data"})
+
+	diff := StateDiff{
+		Before: testStateDiffState(t, before, testSchemas()),
+		After:  testStateDiffState(t, after, testSchemas()),
+	}
+
+	type change struct {
+		Name   string
+		Action plans.Action
+	}
+	var gotResources []change
+	for _, resource := range diff.Resources() {
+		gotResources = append(gotResources, change{resource.Address, resource.Diff.Action})
+	}
+	wantResources := []change{
+		{"data.test_data_source.data", plans.Delete},
+		{"test_resource.baz[0]", plans.Update},
+		{"test_resource.baz[1]", plans.Create},
+	}
+	if diff := cmp.Diff(wantResources, gotResources); diff != "" {
+		t.Errorf("wrong resource changes\n%s", diff)
+	}
+
+	var gotOutputs []change
+	for _, output := range diff.Outputs() {
+		gotOutputs = append(gotOutputs, change{output.Name, output.Diff.Action})
+	}
+	wantOutputs := []change{
+		{"bar", plans.Update},
+		{"secret", plans.Create},
+	}
+	if diff := cmp.Diff(wantOutputs, gotOutputs); diff != "" {
+		t.Errorf("wrong output changes\n%s", diff)
+	}
+
+	streams, done := terminal.StreamsForTesting(t)
+	renderer := Renderer{
+		Colorize: &colorstring.Colorize{Colors: colorstring.DefaultColors, Disable: true},
+		Streams:  streams,
+	}
+	renderer.RenderHumanStateDiff(diff)
+	result := done(t).All()
+
+	for _, want := range []string{
+		"  # data.test_data_source.data was removed\n  - data \"test_data_source\" \"data\" {",
+		"  # test_resource.baz[0] has changed\n  ~ resource \"test_resource\" \"baz\" {",
+		"~ woozles = \"confuzles\" -> \"changed\"",
+		"  # test_resource.baz[1] was added\n  + resource \"test_resource\" \"baz\" {",
+		"Changes to Outputs:\n  ~ bar    = \"bar value\" -> \"new bar value\"\n  + secret = (sensitive value)\n",
+		"Summary: 1 added, 1 changed, 1 removed.\n",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("output is missing %q\n%s", want, result)
+		}
+	}
+}
+
+func TestStateDiff_noDifferences(t *testing.T) {
+	diff := StateDiff{
+		Before: testStateDiffState(t, basicState(t), testSchemas()),
+		After:  testStateDiffState(t, basicState(t), testSchemas()),
+	}
+
+	streams, done := terminal.StreamsForTesting(t)
+	renderer := Renderer{
+		Colorize: &colorstring.Colorize{Colors: colorstring.DefaultColors, Disable: true},
+		Streams:  streams,
+	}
+	renderer.RenderHumanStateDiff(diff)
+
+	want := "No differences. Both states contain the same resource instances and output values.\n"
+	if diff := cmp.Diff(want, done(t).All()); diff != "" {
+		t.Errorf("wrong output\n%s", diff)
+	}
+}
+
+func testStateDiffState(t *testing.T, state *states.State, schemas *tofu.Schemas) State {
+	t.Helper()
+
+	root, outputs, err := jsonstate.MarshalForRenderer(&statefile.File{State: state}, schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return State{
+		StateFormatVersion:    jsonstate.FormatVersion,
+		RootModule:            root,
+		RootModuleOutputs:     outputs,
+		ProviderFormatVersion: jsonprovider.FormatVersion,
+		ProviderSchemas:       jsonprovider.MarshalForRenderer(schemas),
+	}
+}
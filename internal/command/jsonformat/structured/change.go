@@ -147,6 +147,52 @@ func FromJsonOutput(output jsonstate.Output) Change {
 	}
 }
 
+// FromJsonResources unmarshals the raw values of the same resource instance
+// object in two different states into a change from before to after. Either
+// may be nil, if the object only exists in one of the states.
+func FromJsonResources(before, after *jsonstate.Resource) Change {
+	change := Change{
+		// States don't have any unknown values.
+		Unknown: false,
+
+		// We don't display replacement data for resources, and all attributes
+		// are relevant.
+		ReplacePaths:       attribute_path.Empty(false),
+		RelevantAttributes: attribute_path.AlwaysMatcher(),
+	}
+	if before != nil {
+		change.Before = unwrapAttributeValues(before.AttributeValues)
+		change.BeforeSensitive = UnmarshalGeneric(before.SensitiveValues)
+	}
+	if after != nil {
+		change.After = unwrapAttributeValues(after.AttributeValues)
+		change.AfterSensitive = UnmarshalGeneric(after.SensitiveValues)
+	}
+	return change
+}
+
+// FromJsonOutputs unmarshals the raw values of the same output value in two
+// different states into a change from before to after. Either may be nil, if
+// the output only exists in one of the states.
+func FromJsonOutputs(before, after *jsonstate.Output) Change {
+	change := Change{
+		Unknown:            false,
+		BeforeSensitive:    false,
+		AfterSensitive:     false,
+		ReplacePaths:       attribute_path.Empty(false),
+		RelevantAttributes: attribute_path.AlwaysMatcher(),
+	}
+	if before != nil {
+		change.Before = UnmarshalGeneric(before.Value)
+		change.BeforeSensitive = before.Sensitive
+	}
+	if after != nil {
+		change.After = UnmarshalGeneric(after.Value)
+		change.AfterSensitive = after.Sensitive
+	}
+	return change
+}
+
 // CalculateAction does a very simple analysis to make the best guess at the
 // action this change describes. For complex types such as objects, maps, lists,
 // or sets it is likely more efficient to work out the action directly instead
@@ -0,0 +1,186 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package junit contains functions to produce JUnit XML reports of the
+// results of the "tofu test" command, for consumption by CI systems.
+//
+// Each test file is reported as a testsuite, and each run block within it
+// as a testcase.
+package junit
+
+import (
+	"encoding/xml"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/command/format"
+	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+type testSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []*testSuite `xml:"testsuite"`
+}
+
+type testSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      string      `xml:"time,attr"`
+	Cases     []*testCase `xml:"testcase"`
+	SystemErr *output     `xml:"system-err,omitempty"`
+}
+
+type testCase struct {
+	Name      string   `xml:"name,attr"`
+	Classname string   `xml:"classname,attr"`
+	Time      string   `xml:"time,attr"`
+	Failure   *problem `xml:"failure,omitempty"`
+	Error     *problem `xml:"error,omitempty"`
+	Skipped   *skipped `xml:"skipped,omitempty"`
+	SystemOut *output  `xml:"system-out,omitempty"`
+}
+
+// The bodies of elements that contain diagnostics are written as CDATA so
+// that the line breaks within them are preserved literally.
+
+type problem struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",cdata"`
+}
+
+type output struct {
+	Body string `xml:",cdata"`
+}
+
+type skipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// Marshal returns a JUnit XML report of the results in the given suite.
+//
+// The suite doesn't need to have completed: run blocks that never started
+// because the tests were interrupted are reported as skipped. The sources
+// are used to include source code snippets in the diagnostics.
+func Marshal(suite *moduletest.Suite, sources map[string]*hcl.File) ([]byte, error) {
+	var names []string
+	for name := range suite.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names) // files are executed in alphabetical order
+
+	ret := &testSuites{}
+	var total time.Duration
+	for _, name := range names {
+		file := suite.Files[name]
+		s := marshalFile(file, sources)
+		ret.Suites = append(ret.Suites, s)
+		ret.Tests += s.Tests
+		ret.Failures += s.Failures
+		ret.Errors += s.Errors
+		ret.Skipped += s.Skipped
+		total += file.Duration
+	}
+	ret.Time = seconds(total)
+
+	out, err := xml.MarshalIndent(ret, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+func marshalFile(file *moduletest.File, sources map[string]*hcl.File) *testSuite {
+	ret := &testSuite{
+		Name:      file.Name,
+		Tests:     len(file.Runs),
+		Time:      seconds(file.Duration),
+		SystemErr: marshalOutput(file.Diagnostics, sources),
+	}
+
+	for _, run := range file.Runs {
+		tc := &testCase{
+			Name:      run.Name,
+			Classname: file.Name,
+			Time:      seconds(run.Duration),
+			SystemOut: marshalOutput(withSeverity(run.Diagnostics, tfdiags.Warning), sources),
+		}
+
+		switch run.Status {
+		case moduletest.Fail:
+			ret.Failures++
+			tc.Failure = marshalProblem(run.Diagnostics, sources, "The run block failed.")
+		case moduletest.Error:
+			ret.Errors++
+			tc.Error = marshalProblem(run.Diagnostics, sources, "The run block encountered an error.")
+		case moduletest.Skip:
+			ret.Skipped++
+			tc.Skipped = &skipped{}
+		case moduletest.Pending:
+			ret.Skipped++
+			tc.Skipped = &skipped{Message: "The tests were interrupted before this run block was executed."}
+		}
+
+		ret.Cases = append(ret.Cases, tc)
+	}
+
+	return ret
+}
+
+// marshalProblem returns a failure or error whose message is the summary of
+// the first error in the given diagnostics, or the given fallback if there
+// are no errors, and whose body describes all of the errors in full.
+func marshalProblem(diags tfdiags.Diagnostics, sources map[string]*hcl.File, fallback string) *problem {
+	ret := &problem{Message: fallback}
+	if errs := withSeverity(diags, tfdiags.Error); len(errs) > 0 {
+		ret.Message = errs[0].Description().Summary
+		ret.Body = diagnostics(errs, sources)
+	}
+	return ret
+}
+
+// marshalOutput returns output describing the given diagnostics, or nil if
+// there are none.
+func marshalOutput(diags tfdiags.Diagnostics, sources map[string]*hcl.File) *output {
+	if len(diags) == 0 {
+		return nil
+	}
+	return &output{Body: diagnostics(diags, sources)}
+}
+
+func withSeverity(diags tfdiags.Diagnostics, severity tfdiags.Severity) tfdiags.Diagnostics {
+	var ret tfdiags.Diagnostics
+	for _, diag := range diags {
+		if diag.Severity() == severity {
+			ret = append(ret, diag)
+		}
+	}
+	return ret
+}
+
+// diagnostics renders the given diagnostics as plain text, without any
+// terminal formatting sequences or line wrapping.
+func diagnostics(diags tfdiags.Diagnostics, sources map[string]*hcl.File) string {
+	var buf strings.Builder
+	for _, diag := range diags {
+		buf.WriteString(format.DiagnosticPlain(diag, sources, 0))
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// seconds formats a duration in seconds, as JUnit expects.
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
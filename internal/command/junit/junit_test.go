@@ -0,0 +1,113 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package junit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+func TestMarshal(t *testing.T) {
+	suite := &moduletest.Suite{
+		Status: moduletest.Fail,
+		Files: map[string]*moduletest.File{
+			"main.tftest.hcl": {
+				Name:     "main.tftest.hcl",
+				Status:   moduletest.Fail,
+				Duration: 3 * time.Second,
+				Runs: []*moduletest.Run{
+					{
+						Name:     "setup",
+						Status:   moduletest.Pass,
+						Duration: 1500 * time.Millisecond,
+						Diagnostics: tfdiags.Diagnostics{}.Append(
+							tfdiags.Sourceless(tfdiags.Warning, "Something odd", "Look at this."),
+						),
+					},
+					{
+						Name:     "check",
+						Status:   moduletest.Fail,
+						Duration: 250 * time.Millisecond,
+						Diagnostics: tfdiags.Diagnostics{}.Append(
+							tfdiags.Sourceless(tfdiags.Error, "Test assertion failed", "Expected <1> & got 2."),
+						),
+					},
+					{
+						Name:   "after",
+						Status: moduletest.Skip,
+					},
+				},
+			},
+			"broken.tftest.hcl": {
+				Name:   "broken.tftest.hcl",
+				Status: moduletest.Error,
+				Runs: []*moduletest.Run{
+					{
+						Name:   "bad",
+						Status: moduletest.Error,
+					},
+				},
+				Diagnostics: tfdiags.Diagnostics{}.Append(
+					tfdiags.Sourceless(tfdiags.Error, "Invalid test file", "Oops."),
+				),
+			},
+			"interrupted.tftest.hcl": {
+				Name:   "interrupted.tftest.hcl",
+				Status: moduletest.Pending,
+				Runs: []*moduletest.Run{
+					{
+						Name:   "never",
+						Status: moduletest.Pending,
+					},
+				},
+			},
+		},
+	}
+
+	got, err := Marshal(suite, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="5" failures="1" errors="1" skipped="2" time="3.000">
+  <testsuite name="broken.tftest.hcl" tests="1" failures="0" errors="1" skipped="0" time="0.000">
+    <testcase name="bad" classname="broken.tftest.hcl" time="0.000">
+      <error message="The run block encountered an error."></error>
+    </testcase>
+    <system-err><![CDATA[Error: Invalid test file
+
+Oops.]]></system-err>
+  </testsuite>
+  <testsuite name="interrupted.tftest.hcl" tests="1" failures="0" errors="0" skipped="1" time="0.000">
+    <testcase name="never" classname="interrupted.tftest.hcl" time="0.000">
+      <skipped message="The tests were interrupted before this run block was executed."></skipped>
+    </testcase>
+  </testsuite>
+  <testsuite name="main.tftest.hcl" tests="3" failures="1" errors="0" skipped="1" time="3.000">
+    <testcase name="setup" classname="main.tftest.hcl" time="1.500">
+      <system-out><![CDATA[Warning: Something odd
+
+Look at this.]]></system-out>
+    </testcase>
+    <testcase name="check" classname="main.tftest.hcl" time="0.250">
+      <failure message="Test assertion failed"><![CDATA[Error: Test assertion failed
+
+Expected <1> & got 2.]]></failure>
+    </testcase>
+    <testcase name="after" classname="main.tftest.hcl" time="0.000">
+      <skipped></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
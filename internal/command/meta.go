@@ -873,10 +873,13 @@ func (m *Meta) SetWorkspace(name string) error {
 	return nil
 }
 
-// isAutoVarFile determines if the file ends with .auto.tfvars or .auto.tfvars.json
+// isAutoVarFile determines if the file ends with .auto.tfvars or one of its
+// JSON and YAML equivalents
 func isAutoVarFile(path string) bool {
 	return strings.HasSuffix(path, ".auto.tfvars") ||
-		strings.HasSuffix(path, ".auto.tfvars.json")
+		strings.HasSuffix(path, ".auto.tfvars.json") ||
+		strings.HasSuffix(path, ".auto.tfvars.yaml") ||
+		strings.HasSuffix(path, ".auto.tfvars.yml")
 }
 
 // FIXME: as an interim refactoring step, we apply the contents of the state
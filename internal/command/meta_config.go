@@ -21,6 +21,7 @@ import (
 	"github.com/zclconf/go-cty/cty/convert"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configload"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
@@ -153,7 +154,7 @@ func (m *Meta) rootModuleCall(ctx context.Context, rootDir string) (configs.Stat
 			}
 		}
 
-		parsed, parsedDiags := v.ParseVariableValue(variable.ParsingMode)
+		parsed, parsedDiags := backend.ParseVariableValueForDecl(v, variable)
 		return parsed.Value, parsedDiags.ToHCL()
 	}, rootDir, workspace)
 	m.rootModuleCallCache = &call
@@ -266,7 +267,7 @@ func (m *Meta) loadHCLFile(filename string) (hcl.Body, tfdiags.Diagnostics) {
 		return nil, diags
 	}
 
-	body, hclDiags := loader.Parser().LoadHCLFile(filename)
+	body, hclDiags := loader.Parser().LoadSingleDocumentHCLFile(filename)
 	diags = diags.Append(hclDiags)
 	return body, diags
 }
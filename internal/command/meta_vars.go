@@ -14,9 +14,11 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/configs/yamlbody"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/opentofu/opentofu/internal/tofu"
 )
@@ -80,7 +82,7 @@ func (m *Meta) collectVariableValues() (map[string]backend.UnparsedVariableValue
 	// Next up we load implicit files from the specified directory (first root then tests dir
 	// as tests dir files have higher precedence). These files are automatically loaded if present.
 	// There's the original terraform.tfvars (DefaultVarsFilename) along with the later-added
-	// search for all files ending in .auto.tfvars.
+	// search for all files ending in .auto.tfvars, each with JSON and YAML equivalents.
 	diags = diags.Append(m.addVarsFromDir(".", ret))
 
 	// Finally we process values given explicitly on the command line, either
@@ -148,6 +150,12 @@ func (m *Meta) addVarsFromDir(currDir string, ret map[string]backend.UnparsedVar
 		moreDiags := m.addVarsFromFile(filepath.Join(currDir, defaultVarsFilenameJSON), tofu.ValueFromAutoFile, ret)
 		diags = diags.Append(moreDiags)
 	}
+	for _, defaultVarsFilenameYAML := range []string{DefaultVarsFilename + ".yaml", DefaultVarsFilename + ".yml"} {
+		if _, err := os.Stat(filepath.Join(currDir, defaultVarsFilenameYAML)); err == nil {
+			moreDiags := m.addVarsFromFile(filepath.Join(currDir, defaultVarsFilenameYAML), tofu.ValueFromAutoFile, ret)
+			diags = diags.Append(moreDiags)
+		}
+	}
 	if infos, err := os.ReadDir(currDir); err == nil {
 		// "infos" is already sorted by name, so we just need to filter it here.
 		for _, info := range infos {
@@ -196,11 +204,12 @@ func (m *Meta) addVarsFromFile(filename string, sourceType tofu.ValueSourceType,
 	var f *hcl.File
 
 	extJSON := strings.HasSuffix(filename, ".json")
+	extYAML := strings.HasSuffix(filename, ".yaml") || strings.HasSuffix(filename, ".yml")
 	extTfvars := strings.HasSuffix(filename, DefaultVarsExtension)
 
 	// Only try json detection if ambiguous
 	// Ex: -var-file=<(./scripts/vars.sh)
-	detectJSON := !extJSON && !extTfvars && strings.HasPrefix(strings.TrimSpace(string(src)), "{")
+	detectJSON := !extJSON && !extYAML && !extTfvars && strings.HasPrefix(strings.TrimSpace(string(src)), "{")
 
 	if extJSON || detectJSON {
 		var hclDiags hcl.Diagnostics
@@ -209,6 +218,13 @@ func (m *Meta) addVarsFromFile(filename string, sourceType tofu.ValueSourceType,
 		if f == nil || f.Body == nil {
 			return diags
 		}
+	} else if extYAML {
+		var hclDiags hcl.Diagnostics
+		f, hclDiags = yamlbody.Parse(src, filename)
+		diags = diags.Append(hclDiags)
+		if f == nil || f.Body == nil {
+			return diags
+		}
 	} else {
 		var hclDiags hcl.Diagnostics
 		f, hclDiags = hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
@@ -270,10 +286,24 @@ type unparsedVariableValueExpression struct {
 }
 
 func (v unparsedVariableValueExpression) ParseVariableValue(mode configs.VariableParsingMode) (*tofu.InputValue, tfdiags.Diagnostics) {
+	return v.ParseVariableValueForType(mode, cty.DynamicPseudoType)
+}
+
+func (v unparsedVariableValueExpression) ParseVariableValueForType(mode configs.VariableParsingMode, ty cty.Type) (*tofu.InputValue, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 	val, hclDiags := v.expr.Value(nil) // nil because no function calls or variable references are allowed here
 	diags = diags.Append(hclDiags)
 
+	// YAML resolves an unquoted value like 1.10 as a number, which would
+	// then be converted to "1.1" for a variable that expects a string, so
+	// we use the value exactly as written in that case instead. Any other
+	// type keeps the value that YAML resolved, as for JSON.
+	if ty.Equals(cty.String) && !hclDiags.HasErrors() {
+		if text, ok := yamlbody.PlainScalarText(v.expr); ok {
+			val = cty.StringVal(text)
+		}
+	}
+
 	rng := tfdiags.SourceRangeFromHCL(v.expr.Range())
 
 	return &tofu.InputValue{
@@ -6,11 +6,15 @@
 package command
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/zclconf/go-cty/cty"
+
 	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/tofu"
 )
 
@@ -20,6 +24,7 @@ func TestMeta_addVarsFromFile(t *testing.T) {
 
 	hclData := `foo = "bar"`
 	jsonData := `{"foo": "bar"}`
+	yamlData := "foo: bar\n"
 
 	cases := []struct {
 		filename string
@@ -46,6 +51,26 @@ func TestMeta_addVarsFromFile(t *testing.T) {
 			contents: jsonData,
 			errors:   false,
 		},
+		{
+			filename: "input.tfvars.yaml",
+			contents: yamlData,
+			errors:   false,
+		},
+		{
+			filename: "input.yml",
+			contents: yamlData,
+			errors:   false,
+		},
+		{
+			filename: "mismatch.yaml",
+			contents: "foo: [",
+			errors:   true,
+		},
+		{
+			filename: "multiple.yaml",
+			contents: "foo: bar\n---\nbaz: bar\n",
+			errors:   true,
+		},
 		{
 			filename: "mismatch.tfvars",
 			contents: jsonData,
@@ -76,3 +101,107 @@ func TestMeta_addVarsFromFile(t *testing.T) {
 		})
 	}
 }
+
+func TestMeta_addVarsFromFile_yaml(t *testing.T) {
+	d := t.TempDir()
+	t.Chdir(d)
+
+	target := filepath.Join(d, "input.tfvars.yaml")
+	src := `
+version: 1.10
+count: 3
+enabled: true
+template: ${not_interpolated}
+list: [a, 1]
+nothing: null
+`
+	if err := os.WriteFile(target, []byte(src), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	m := new(Meta)
+	to := make(map[string]backend.UnparsedVariableValue)
+	diags := m.addVarsFromFile(target, tofu.ValueFromNamedFile, to)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+
+	tests := []struct {
+		name string
+		decl *configs.Variable
+		want cty.Value
+	}{
+		// Only a variable of type string takes the value as written.
+		{"version", &configs.Variable{Type: cty.String, ParsingMode: configs.VariableParseLiteral}, cty.StringVal("1.10")},
+		{"version", &configs.Variable{Type: cty.Number, ParsingMode: configs.VariableParseLiteral}, cty.MustParseNumberVal("1.1")},
+		{"version", &configs.Variable{Type: cty.List(cty.String), ParsingMode: configs.VariableParseHCL}, cty.MustParseNumberVal("1.1")},
+		{"count", &configs.Variable{Type: cty.String, ParsingMode: configs.VariableParseLiteral}, cty.StringVal("3")},
+		{"count", &configs.Variable{Type: cty.Number, ParsingMode: configs.VariableParseLiteral}, cty.NumberIntVal(3)},
+		{"count", &configs.Variable{Type: cty.DynamicPseudoType, ParsingMode: configs.VariableParseLiteral}, cty.NumberIntVal(3)},
+		{"enabled", &configs.Variable{Type: cty.Bool, ParsingMode: configs.VariableParseLiteral}, cty.True},
+		{"enabled", &configs.Variable{Type: cty.String, ParsingMode: configs.VariableParseLiteral}, cty.StringVal("true")},
+		{"template", &configs.Variable{Type: cty.String, ParsingMode: configs.VariableParseLiteral}, cty.StringVal("${not_interpolated}")},
+		{"list", &configs.Variable{Type: cty.List(cty.String), ParsingMode: configs.VariableParseHCL}, cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.NumberIntVal(1)})},
+		{"nothing", &configs.Variable{Type: cty.String, ParsingMode: configs.VariableParseLiteral}, cty.NullVal(cty.DynamicPseudoType)},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %s", test.name, test.decl.Type.FriendlyName()), func(t *testing.T) {
+			raw, ok := to[test.name]
+			if !ok {
+				t.Fatalf("no value for %s", test.name)
+			}
+			got, diags := backend.ParseVariableValueForDecl(raw, test.decl)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Err())
+			}
+			if !got.Value.RawEquals(test.want) {
+				t.Errorf("wrong value\ngot:  %#v\nwant: %#v", got.Value, test.want)
+			}
+			if got.SourceType != tofu.ValueFromNamedFile {
+				t.Errorf("wrong source type %s", got.SourceType)
+			}
+			if got.SourceRange.Filename != target || got.SourceRange.Start.Line == 0 {
+				t.Errorf("wrong source range %#v", got.SourceRange)
+			}
+		})
+	}
+}
+
+func TestMeta_addVarsFromDir_yaml(t *testing.T) {
+	d := t.TempDir()
+	t.Chdir(d)
+
+	files := map[string]string{
+		"terraform.tfvars.yaml": "a: default\nb: default\n",
+		"x.auto.tfvars.yml":     "b: auto\n",
+		"ignored.tfvars.yaml":   "c: ignored\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(d, name), []byte(src), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	m := new(Meta)
+	to := make(map[string]backend.UnparsedVariableValue)
+	if diags := m.addVarsFromDir(".", to); diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+
+	if _, ok := to["c"]; ok {
+		t.Errorf("loaded a file that isn't automatically loaded")
+	}
+	for name, want := range map[string]string{"a": "default", "b": "auto"} {
+		raw, ok := to[name]
+		if !ok {
+			t.Fatalf("no value for %s", name)
+		}
+		got, diags := raw.ParseVariableValue(configs.VariableParseLiteral)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Err())
+		}
+		if got.Value.AsString() != want {
+			t.Errorf("wrong value for %s: %#v; want %q", name, got.Value, want)
+		}
+	}
+}
@@ -297,6 +297,8 @@ Other Options:
                                file at PATH, which must not already exist.
                                OpenTofu may still attempt to write
                                configuration if planning fails with an error.
+                               If PATH ends in .tf.yaml or .tofu.yaml, the
+                               configuration is written in YAML.
 
   -input=false                 Disable prompting for required input variables
                                that are not set some other way.
@@ -12,6 +12,7 @@ import (
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/jsonprovider"
+	"github.com/opentofu/opentofu/internal/command/yamlschema"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
@@ -35,8 +36,9 @@ func (c *ProvidersSchemaCommand) Run(args []string) int {
 	args = c.Meta.process(args)
 	cmdFlags := c.Meta.defaultFlagSet("providers schema")
 	c.Meta.varFlagSet(cmdFlags)
-	var jsonOutput bool
+	var jsonOutput, yamlSchemaOutput bool
 	cmdFlags.BoolVar(&jsonOutput, "json", false, "produce JSON output")
+	cmdFlags.BoolVar(&yamlSchemaOutput, "yaml-schema", false, "produce a JSON Schema for YAML configuration")
 
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
@@ -44,9 +46,9 @@ func (c *ProvidersSchemaCommand) Run(args []string) int {
 		return 1
 	}
 
-	if !jsonOutput {
+	if jsonOutput == yamlSchemaOutput {
 		c.Ui.Error(
-			"The `tofu providers schema` command requires the `-json` flag.\n")
+			"The `tofu providers schema` command requires exactly one of the `-json` and `-yaml-schema` flags.\n")
 		cmdFlags.Usage()
 		return 1
 	}
@@ -127,6 +129,16 @@ func (c *ProvidersSchemaCommand) Run(args []string) int {
 		return 1
 	}
 
+	if yamlSchemaOutput {
+		yamlSchema, err := yamlschema.Marshal(schemas.Providers)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to marshal YAML configuration schema to json: %s", err))
+			return 1
+		}
+		c.Ui.Output(string(yamlSchema))
+		return 0
+	}
+
 	jsonSchemas, err := jsonprovider.Marshal(schemas)
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Failed to marshal provider schemas to json: %s", err))
@@ -139,12 +151,24 @@ func (c *ProvidersSchemaCommand) Run(args []string) int {
 
 const providersSchemaCommandHelp = `
 Usage: tofu [global options] providers schema [options] -json
+       tofu [global options] providers schema [options] -yaml-schema
 
   Prints out a json representation of the schemas for all providers used 
   in the current configuration.
 
+  With -yaml-schema, prints out instead a JSON Schema document describing
+  YAML configuration files (.tf.yaml) that use those providers. Editors
+  that support the YAML language server can use it to validate and complete
+  a file that starts with a header like:
+
+    # yaml-language-server: $schema=./schema.json
+
 Options:
 
+  -json              Print the provider schemas as JSON.
+
+  -yaml-schema       Print a JSON Schema for YAML configuration files.
+
   -var 'foo=bar'     Set a value for one of the input variables in the root
                      module of the configuration. Use this option more than
                      once to set more than one variable.
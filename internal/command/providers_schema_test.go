@@ -109,6 +109,57 @@ func TestProvidersSchema_output(t *testing.T) {
 	}
 }
 
+func TestProvidersSchema_yamlSchema(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, "testdata/providers-schema/basic", td)
+	t.Chdir(td)
+
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"test": {"1.2.3"},
+	})
+	defer close()
+
+	ui := new(cli.MockUi)
+	m := Meta{
+		testingOverrides: metaOverridesForProvider(providersSchemaFixtureProvider()),
+		Ui:               ui,
+		ProviderSource:   providerSource,
+	}
+	ic := &InitCommand{Meta: m}
+	if code := ic.Run([]string{}); code != 0 {
+		t.Fatalf("init failed\n%s", ui.ErrorWriter)
+	}
+	ui.OutputWriter.Reset()
+
+	pc := &ProvidersSchemaCommand{Meta: m}
+	if code := pc.Run([]string{"-yaml-schema"}); code != 0 {
+		t.Fatalf("wrong exit status %d; want 0\nstderr: %s", code, ui.ErrorWriter.String())
+	}
+
+	var got struct {
+		Properties  map[string]json.RawMessage `json:"properties"`
+		Definitions map[string]json.RawMessage `json:"definitions"`
+	}
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"resource", "data", "variable", "provider"} {
+		if _, ok := got.Properties[name]; !ok {
+			t.Errorf("missing top-level property %q", name)
+		}
+	}
+	for _, name := range []string{"provider.test", "resource.test_instance"} {
+		if _, ok := got.Definitions[name]; !ok {
+			t.Errorf("missing definition %q", name)
+		}
+	}
+
+	// -json and -yaml-schema can't be used together.
+	if code := pc.Run([]string{"-json", "-yaml-schema"}); code != 1 {
+		t.Fatalf("wrong exit status %d; want 1", code)
+	}
+}
+
 type providerSchemas struct {
 	FormatVersion string                    `json:"format_version"`
 	Schemas       map[string]providerSchema `json:"provider_schemas"`
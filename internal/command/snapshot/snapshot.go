@@ -0,0 +1,247 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package snapshot contains functions to record the plan created by a run
+// block in a "tofu test" file as a golden snapshot, and to compare later
+// plans against it.
+//
+// Snapshots are derived from the JSON plan representation, keeping only the
+// actions and values of each resource and output change. Values that won't
+// be known until apply, and values that are sensitive, are replaced with
+// placeholders so that snapshots are stable and never contain secrets.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/mitchellh/colorstring"
+
+	"github.com/opentofu/opentofu/internal/command/jsonformat/computed"
+	"github.com/opentofu/opentofu/internal/command/jsonformat/differ"
+	"github.com/opentofu/opentofu/internal/command/jsonformat/structured"
+	"github.com/opentofu/opentofu/internal/command/jsonformat/structured/attribute_path"
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+const (
+	formatVersion = "1.0"
+
+	// UnknownValue and SensitiveValue replace the values within a snapshot
+	// that are unknown or sensitive.
+	UnknownValue   = "(known after apply)"
+	SensitiveValue = "(sensitive value)"
+)
+
+// Snapshot is the normalised representation of a plan.
+type Snapshot struct {
+	FormatVersion   string             `json:"format_version"`
+	ResourceChanges map[string]*Change `json:"resource_changes,omitempty"`
+	OutputChanges   map[string]*Change `json:"output_changes,omitempty"`
+}
+
+// Change is the normalised representation of a single resource instance or
+// output value change.
+type Change struct {
+	Actions []string    `json:"actions"`
+	Before  interface{} `json:"before"`
+	After   interface{} `json:"after"`
+}
+
+// New returns the snapshot of the given plan.
+func New(plan *plans.Plan, schemas *tofu.Schemas) (*Snapshot, error) {
+	resources, err := jsonplan.MarshalResourceChanges(plan.Changes.Resources, schemas)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := jsonplan.MarshalOutputChanges(plan.Changes)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &Snapshot{
+		FormatVersion: formatVersion,
+	}
+	for _, resource := range resources {
+		if ret.ResourceChanges == nil {
+			ret.ResourceChanges = make(map[string]*Change)
+		}
+		key := resource.Address
+		if resource.Deposed != "" {
+			key = fmt.Sprintf("%s (deposed object %s)", key, resource.Deposed)
+		}
+		ret.ResourceChanges[key] = newChange(resource.Change)
+	}
+	for name, output := range outputs {
+		if ret.OutputChanges == nil {
+			ret.OutputChanges = make(map[string]*Change)
+		}
+		ret.OutputChanges[name] = newChange(output)
+	}
+	return ret, nil
+}
+
+func newChange(change jsonplan.Change) *Change {
+	return &Change{
+		Actions: change.Actions,
+		Before: normalise(
+			structured.UnmarshalGeneric(change.Before),
+			nil,
+			structured.UnmarshalGeneric(change.BeforeSensitive)),
+		After: normalise(
+			structured.UnmarshalGeneric(change.After),
+			structured.UnmarshalGeneric(change.AfterUnknown),
+			structured.UnmarshalGeneric(change.AfterSensitive)),
+	}
+}
+
+// normalise replaces the parts of the given value that are marked as unknown
+// or sensitive by the given structures, which follow the conventions of the
+// after_unknown and after_sensitive attributes of the JSON plan.
+func normalise(value, unknown, sensitive interface{}) interface{} {
+	if sensitive == true {
+		return SensitiveValue
+	}
+	if unknown == true {
+		return UnknownValue
+	}
+
+	switch unknown.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		unknown = nil
+	}
+	switch sensitive.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		sensitive = nil
+	}
+	if unknown == nil && sensitive == nil {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			ret[key] = normalise(elem, lookup(unknown, key), lookup(sensitive, key))
+		}
+		// Unknown attributes are omitted from the value entirely.
+		if u, ok := unknown.(map[string]interface{}); ok {
+			for key := range u {
+				if _, exists := ret[key]; !exists {
+					ret[key] = normalise(nil, u[key], lookup(sensitive, key))
+				}
+			}
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for ix, elem := range v {
+			ret[ix] = normalise(elem, index(unknown, ix), index(sensitive, ix))
+		}
+		return ret
+	case nil:
+		// The whole value was omitted, but parts of it might be unknown.
+		if u, ok := unknown.(map[string]interface{}); ok {
+			return normalise(map[string]interface{}{}, u, sensitive)
+		}
+		return nil
+	default:
+		return value
+	}
+}
+
+func lookup(structure interface{}, key string) interface{} {
+	if m, ok := structure.(map[string]interface{}); ok {
+		return m[key]
+	}
+	return nil
+}
+
+func index(structure interface{}, ix int) interface{} {
+	if l, ok := structure.([]interface{}); ok && ix < len(l) {
+		return l[ix]
+	}
+	return nil
+}
+
+// Load reads a snapshot previously written by Save.
+func Load(filename string) (*Snapshot, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(src))
+	decoder.UseNumber()
+	var ret Snapshot
+	if err := decoder.Decode(&ret); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	if ret.FormatVersion != formatVersion {
+		return nil, fmt.Errorf("unsupported snapshot format version %q", ret.FormatVersion)
+	}
+	return &ret, nil
+}
+
+// Save writes the snapshot to the given file, creating its directory if
+// necessary.
+func (s *Snapshot) Save(filename string) error {
+	src, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(src, '\n'), 0644)
+}
+
+// Diff returns a human-readable description of the differences between the
+// stored snapshot and a new one, or an empty string if they are equal.
+func Diff(stored, actual *Snapshot) (string, error) {
+	before, err := generic(stored)
+	if err != nil {
+		return "", err
+	}
+	after, err := generic(actual)
+	if err != nil {
+		return "", err
+	}
+	if reflect.DeepEqual(before, after) {
+		return "", nil
+	}
+
+	diff := differ.ComputeDiffForOutput(structured.Change{
+		Before:             before,
+		After:              after,
+		Unknown:            false,
+		BeforeSensitive:    false,
+		AfterSensitive:     false,
+		ReplacePaths:       attribute_path.Empty(false),
+		RelevantAttributes: attribute_path.AlwaysMatcher(),
+	})
+	opts := computed.NewRenderHumanOpts(&colorstring.Colorize{
+		Colors:  colorstring.DefaultColors,
+		Disable: true,
+	}, false)
+	return diff.RenderHuman(0, opts), nil
+}
+
+// generic converts the snapshot into the generic representation of its JSON
+// encoding, so that snapshots created in memory can be compared with
+// snapshots loaded from files.
+func generic(s *Snapshot) (interface{}, error) {
+	src, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return structured.UnmarshalGeneric(src), nil
+}
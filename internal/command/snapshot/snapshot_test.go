@@ -0,0 +1,92 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package snapshot
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+)
+
+func TestNewChange(t *testing.T) {
+	change := jsonplan.Change{
+		Actions:         []string{"update"},
+		Before:          json.RawMessage(`{"id":"abc","password":"hunter2","tags":["a","b"]}`),
+		After:           json.RawMessage(`{"password":"hunter3","tags":["a",null]}`),
+		AfterUnknown:    json.RawMessage(`{"id":true,"tags":[false,true]}`),
+		BeforeSensitive: json.RawMessage(`{"password":true}`),
+		AfterSensitive:  json.RawMessage(`{"password":true}`),
+	}
+
+	got := newChange(change)
+	want := &Change{
+		Actions: []string{"update"},
+		Before: map[string]interface{}{
+			"id":       "abc",
+			"password": SensitiveValue,
+			"tags":     []interface{}{"a", "b"},
+		},
+		After: map[string]interface{}{
+			"id":       UnknownValue,
+			"password": SensitiveValue,
+			"tags":     []interface{}{"a", UnknownValue},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong change\n%s", diff)
+	}
+}
+
+func TestSaveLoadDiff(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "snapshots", "main", "setup.json")
+
+	stored := &Snapshot{
+		FormatVersion: formatVersion,
+		ResourceChanges: map[string]*Change{
+			"test_resource.foo": {
+				Actions: []string{"create"},
+				After:   map[string]interface{}{"id": UnknownValue, "value": "bar"},
+			},
+		},
+	}
+	if err := stored.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := Diff(loaded, stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "" {
+		t.Errorf("expected no differences, got:\n%s", diff)
+	}
+
+	changed := &Snapshot{
+		FormatVersion: formatVersion,
+		ResourceChanges: map[string]*Change{
+			"test_resource.foo": {
+				Actions: []string{"create"},
+				After:   map[string]interface{}{"id": UnknownValue, "value": "baz"},
+			},
+		},
+	}
+	diff, err = Diff(loaded, changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"bar" -> "baz"`} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff does not include %q:\n%s", want, diff)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/clistate"
+	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/instances"
+	"github.com/opentofu/opentofu/internal/refactoring"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// StateApplyMovesCommand is a Command implementation that applies the
+// "moved" and "removed" blocks from a file to the state.
+type StateApplyMovesCommand struct {
+	StateMeta
+}
+
+func (c *StateApplyMovesCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+	args = c.Meta.process(args)
+	var dryRun bool
+	cmdFlags := c.Meta.ignoreRemoteVersionFlagSet("state apply-moves")
+	cmdFlags.BoolVar(&dryRun, "dry-run", false, "dry run")
+	cmdFlags.StringVar(&c.backupPath, "backup", "-", "backup")
+	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
+	cmdFlags.StringVar(&c.statePath, "state", "", "path")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	args = cmdFlags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("Exactly one argument expected: the path of the file of moved and removed blocks.\n")
+		return cli.RunResultHelp
+	}
+
+	if diags := c.Meta.checkRequiredVersion(ctx); diags != nil {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// The statements are loaded and validated before we touch the state, so
+	// that an invalid file never leaves the state locked for longer than
+	// necessary.
+	moveStmts, removeStmts, diags := loadStateMovesFile(args[0])
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := c.Encryption(ctx)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(encDiags)
+		return 1
+	}
+
+	// Get the state
+	stateMgr, err := c.State(ctx, enc)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return 1
+	}
+
+	if c.stateLock {
+		stateLocker := clistate.NewLocker(c.stateLockTimeout, views.NewStateLocker(arguments.ViewHuman, c.View))
+		if diags := stateLocker.Lock(stateMgr, "state-apply-moves"); diags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+		defer func() {
+			if diags := stateLocker.Unlock(); diags.HasErrors() {
+				c.showDiagnostics(diags)
+			}
+		}()
+	}
+
+	if err := stateMgr.RefreshState(context.TODO()); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to refresh state: %s", err))
+		return 1
+	}
+
+	state := stateMgr.State()
+	if state == nil {
+		c.Ui.Error(errStateNotFound)
+		return 1
+	}
+	// We always work on a copy, so that a dry run can never change what the
+	// state manager holds.
+	state = state.DeepCopy()
+
+	// Moves are applied before removals, in the same way as during planning,
+	// so the "removed" blocks refer to the addresses after all of the moves.
+	moveResults := refactoring.ApplyMoves(moveStmts, state)
+	removed := refactoring.ApplyRemoves(removeStmts, state)
+
+	moveVerb, removeVerb := "Moved", "Removed"
+	if dryRun {
+		moveVerb, removeVerb = "Would move", "Would remove"
+	}
+	moves := moveResults.Changes.Values()
+	sort.Slice(moves, func(i, j int) bool {
+		return moves[i].From.Less(moves[j].From)
+	})
+	for _, move := range moves {
+		c.Ui.Output(fmt.Sprintf("%s %s to %s", moveVerb, move.From, move.To))
+	}
+	for _, addr := range removed {
+		c.Ui.Output(fmt.Sprintf("%s %s", removeVerb, addr))
+	}
+	if moveResults.Blocked.Len() > 0 {
+		diags = diags.Append(stateMovesBlockedDiag(moveResults))
+	}
+
+	changed := len(moves) + len(removed)
+	if dryRun {
+		if changed == 0 {
+			c.Ui.Output("Would have changed nothing.")
+		}
+		c.showDiagnostics(diags)
+		return 0 // This is as far as we go in dry-run mode
+	}
+
+	if changed == 0 {
+		c.showDiagnostics(diags)
+		c.Ui.Output("No resource instances matched the moved and removed blocks, so the state was not changed.")
+		return 0
+	}
+
+	b, backendDiags := c.Backend(ctx, nil, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Get schemas, if possible, before writing state
+	var schemas *tofu.Schemas
+	if isCloudMode(b) {
+		var schemaDiags tfdiags.Diagnostics
+		schemas, schemaDiags = c.MaybeGetSchemas(ctx, state, nil)
+		diags = diags.Append(schemaDiags)
+	}
+
+	if err := stateMgr.WriteState(state); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateApplyMovesPersist, err))
+		return 1
+	}
+	if err := stateMgr.PersistState(context.TODO(), schemas); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateApplyMovesPersist, err))
+		return 1
+	}
+
+	c.showDiagnostics(diags)
+	c.Ui.Output(fmt.Sprintf("Successfully moved %d and removed %d resource instance(s).", len(moves), len(removed)))
+	return 0
+}
+
+// stateMovesFileSchema is the schema of the top-level body of the file read
+// by "tofu state apply-moves", which may only contain the blocks that
+// describe changes to the state.
+var stateMovesFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "moved"},
+		{Type: "removed"},
+	},
+}
+
+// loadStateMovesFile reads the "moved" and "removed" blocks from the file at
+// the given path and returns them as statements that are relative to the
+// root module, after validating them in the same way as the statements of a
+// configuration.
+func loadStateMovesFile(path string) ([]refactoring.MoveStatement, []*refactoring.RemoveStatement, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	parser := configs.NewParser(nil)
+	body, hclDiags := parser.LoadHCLFile(path)
+	diags = diags.Append(hclDiags)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+	_, hclDiags = body.Content(stateMovesFileSchema)
+	diags = diags.Append(hclDiags)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+
+	file, hclDiags := parser.LoadConfigFile(path)
+	diags = diags.Append(hclDiags)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+
+	for _, removed := range file.Removed {
+		if removed.Destroy {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Cannot destroy objects outside of a plan",
+				Detail:   "This command only changes the state, so it can't destroy the remote objects of the removed resources. Set \"destroy = false\" in the lifecycle block, or use \"tofu apply\" to destroy them.",
+				Subject:  removed.DeclRange.Ptr(),
+			})
+		}
+	}
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+
+	// The statements are interpreted as if they were declared in an otherwise
+	// empty root module, since there is no configuration involved.
+	cfg := configs.NewEmptyConfig()
+	cfg.Module.Moved = file.Moved
+	cfg.Module.Removed = file.Removed
+
+	moveStmts := refactoring.FindMoveStatements(cfg)
+	diags = diags.Append(refactoring.ValidateMoves(moveStmts, cfg, instances.NewExpander().AllInstances()))
+	removeStmts, removeDiags := refactoring.FindRemoveStatements(cfg)
+	diags = diags.Append(removeDiags)
+	return moveStmts, removeStmts, diags
+}
+
+func stateMovesBlockedDiag(results refactoring.MoveResults) tfdiags.Diagnostic {
+	var items []string
+	for _, blocked := range results.Blocked.Values() {
+		items = append(items, fmt.Sprintf("\n  - %s could not move to %s", blocked.Actual, blocked.Wanted))
+	}
+	sort.Strings(items)
+
+	return tfdiags.Sourceless(
+		tfdiags.Warning,
+		"Some moves were skipped",
+		fmt.Sprintf(
+			"Some objects were not moved because there are already objects at their new addresses:%s\n\nUse \"tofu state rm\" to remove the existing objects first if they are no longer needed.",
+			strings.Join(items, ""),
+		),
+	)
+}
+
+func (c *StateApplyMovesCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] state apply-moves [options] FILE
+
+  Apply the "moved" and "removed" blocks in the given file to the state.
+
+  This command is for refactoring the state in bulk. The file uses the same
+  syntax as the configuration, but may only contain "moved" and "removed"
+  blocks, whose addresses are all relative to the root module. Unlike the
+  same blocks in the configuration, they are applied directly to the state
+  without creating a plan, so no providers are needed.
+
+  The moves are applied first, in the same order as during planning, and
+  then the resources and modules of the "removed" blocks are removed from the
+  state without destroying their remote objects. All of the changes are
+  saved together as a single new state snapshot.
+
+Options:
+
+  -dry-run                If set, prints out what would change but doesn't
+                          actually change the state.
+
+  -backup=PATH            Path where OpenTofu should write the backup
+                          state.
+
+  -lock=false             Don't hold a state lock during the operation. This is
+                          dangerous if others might concurrently run commands
+                          against the same workspace.
+
+  -lock-timeout=0s        Duration to retry a state lock.
+
+  -state=PATH             Path to the state file to update. Defaults to the
+                          current workspace state.
+
+  -ignore-remote-version  Continue even if remote and local OpenTofu versions
+                          are incompatible. This may result in an unusable
+                          workspace, and should be used with extreme caution.
+
+  -var 'foo=bar'          Set a value for one of the input variables in the root
+                          module of the configuration. Use this option more than
+                          once to set more than one variable.
+
+  -var-file=filename      Load variable values from the given file, in addition
+                          to the default files terraform.tfvars and *.auto.tfvars.
+                          Use this option more than once to include more than one
+                          variables file.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateApplyMovesCommand) Synopsis() string {
+	return "Apply moved and removed blocks from a file to the state"
+}
+
+const errStateApplyMovesPersist = `Error saving the state: %s
+
+The state was not saved. No items were moved or removed in the persisted
+state. Please resolve the issue above and try again.`
@@ -0,0 +1,210 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+const testStateApplyMovesFile = `
+moved {
+  from = test_instance.foo
+  to   = test_instance.baz
+}
+
+removed {
+  from = test_instance.bar
+
+  lifecycle {
+    destroy = false
+  }
+}
+`
+
+func TestStateApplyMoves(t *testing.T) {
+	statePath := testStateFile(t, testStateHistoryState(true))
+	movesPath := testStateApplyMovesFilePath(t, testStateApplyMovesFile)
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StateApplyMovesCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		movesPath,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	got := ui.OutputWriter.String()
+	for _, want := range []string{
+		"Moved test_instance.foo to test_instance.baz",
+		"Removed test_instance.bar",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output is missing %q:\n%s", want, got)
+		}
+	}
+
+	state := testStateRead(t, statePath)
+	for _, name := range []string{"foo", "bar"} {
+		if state.ResourceInstance(testStateApplyMovesAddr(name)) != nil {
+			t.Errorf("test_instance.%s should no longer be in the state:\n%s", name, state)
+		}
+	}
+	if state.ResourceInstance(testStateApplyMovesAddr("baz")) == nil {
+		t.Errorf("test_instance.baz should be in the state:\n%s", state)
+	}
+
+	// The original state is kept as a backup.
+	backups := testStateBackups(t, filepath.Dir(statePath))
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup, got %v", backups)
+	}
+	backup := testStateRead(t, backups[0])
+	if backup.ResourceInstance(testStateApplyMovesAddr("foo")) == nil {
+		t.Errorf("backup should contain the original state:\n%s", backup)
+	}
+}
+
+func TestStateApplyMoves_dryRun(t *testing.T) {
+	statePath := testStateFile(t, testStateHistoryState(true))
+	movesPath := testStateApplyMovesFilePath(t, testStateApplyMovesFile)
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StateApplyMovesCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-dry-run",
+		movesPath,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	got := ui.OutputWriter.String()
+	for _, want := range []string{
+		"Would move test_instance.foo to test_instance.baz",
+		"Would remove test_instance.bar",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output is missing %q:\n%s", want, got)
+		}
+	}
+
+	state := testStateRead(t, statePath)
+	for _, name := range []string{"foo", "bar"} {
+		if state.ResourceInstance(testStateApplyMovesAddr(name)) == nil {
+			t.Errorf("dry run should not have changed test_instance.%s:\n%s", name, state)
+		}
+	}
+}
+
+func TestStateApplyMoves_invalid(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"other blocks": {
+			`resource "test_instance" "foo" {}`,
+			`Blocks of type "resource" are not expected here`,
+		},
+		"destroy": {
+			`
+removed {
+  from = test_instance.bar
+  lifecycle {
+    destroy = true
+  }
+}
+`,
+			"Cannot destroy objects outside of a plan",
+		},
+		"cycle": {
+			`
+moved {
+  from = test_instance.foo
+  to   = test_instance.bar
+}
+moved {
+  from = test_instance.bar
+  to   = test_instance.foo
+}
+`,
+			"Cyclic dependency in move statements",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			statePath := testStateFile(t, testStateHistoryState(true))
+			movesPath := testStateApplyMovesFilePath(t, test.src)
+
+			ui := new(cli.MockUi)
+			view, _ := testView(t)
+			c := &StateApplyMovesCommand{
+				StateMeta{
+					Meta: Meta{
+						testingOverrides: metaOverridesForProvider(testProvider()),
+						Ui:               ui,
+						View:             view,
+					},
+				},
+			}
+
+			args := []string{
+				"-state", statePath,
+				movesPath,
+			}
+			if code := c.Run(args); code != 1 {
+				t.Fatalf("expected failure, got %d\n\n%s", code, ui.OutputWriter.String())
+			}
+			if got := ui.ErrorWriter.String(); !strings.Contains(got, test.want) {
+				t.Errorf("error is missing %q:\n%s", test.want, got)
+			}
+		})
+	}
+}
+
+func testStateApplyMovesFilePath(t *testing.T, src string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "moves.tf")
+	if err := os.WriteFile(path, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testStateApplyMovesAddr(name string) addrs.AbsResourceInstance {
+	return addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: name,
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+}
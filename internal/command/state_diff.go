@@ -0,0 +1,417 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/command/jsonformat"
+	"github.com/opentofu/opentofu/internal/command/jsonprovider"
+	"github.com/opentofu/opentofu/internal/command/jsonstate"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+)
+
+const (
+	stateDiffWorkspacePrefix = "workspace:"
+	stateDiffHistoryPrefix   = "history:"
+
+	// stateDiffFormatVersion is the version of the JSON output format of
+	// "tofu state diff -json".
+	stateDiffFormatVersion = "1.0"
+)
+
+// StateDiffCommand is a Command implementation that shows the differences
+// between two state snapshots.
+type StateDiffCommand struct {
+	Meta
+}
+
+func (c *StateDiffCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+
+	args = c.Meta.process(args)
+	var jsonOutput, showSensitive bool
+	cmdFlags := c.Meta.defaultFlagSet("state diff")
+	c.Meta.varFlagSet(cmdFlags)
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "produce JSON output")
+	cmdFlags.BoolVar(&showSensitive, "show-sensitive", false, "displays sensitive values")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Streams.Eprintf("Error parsing command-line flags: %s\n", err.Error())
+		return 1
+	}
+	args = cmdFlags.Args()
+	if len(args) < 1 || len(args) > 2 {
+		c.Streams.Eprint("Expected one or two arguments: the old state and, optionally, the new state.\n")
+		return cli.RunResultHelp
+	}
+	oldSource := args[0]
+	newSource := ""
+	if len(args) == 2 {
+		newSource = args[1]
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := c.Encryption(ctx)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(encDiags)
+		return 1
+	}
+
+	// Load the backend
+	b, backendDiags := c.Backend(ctx, nil, enc.State())
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(backendDiags)
+		return 1
+	}
+
+	// This is a read-only command
+	c.ignoreRemoteVersionConflict(b)
+
+	oldFile, err := c.stateDiffSource(ctx, b, oldSource)
+	if err != nil {
+		c.Streams.Eprintln(err.Error())
+		return 1
+	}
+	newFile, err := c.stateDiffSource(ctx, b, newSource)
+	if err != nil {
+		c.Streams.Eprintln(err.Error())
+		return 1
+	}
+
+	// The schemas are needed to decode the resource instance objects and to
+	// know which of their attributes are sensitive.
+	cwd, err := os.Getwd()
+	if err != nil {
+		c.Streams.Eprintf("Error getting cwd: %s\n", err)
+		return 1
+	}
+	config, configDiags := c.loadConfig(ctx, cwd)
+	if configDiags.HasErrors() {
+		c.showDiagnostics(configDiags)
+		return 1
+	}
+	before, err := c.stateDiffState(ctx, oldFile, config)
+	if err != nil {
+		c.Streams.Eprintln(fmt.Sprintf(errStateDiffSchemas, stateDiffSourceName(oldSource), err))
+		return 1
+	}
+	after, err := c.stateDiffState(ctx, newFile, config)
+	if err != nil {
+		c.Streams.Eprintln(fmt.Sprintf(errStateDiffSchemas, stateDiffSourceName(newSource), err))
+		return 1
+	}
+	diff := jsonformat.StateDiff{
+		Before: before,
+		After:  after,
+	}
+
+	if jsonOutput {
+		out, err := marshalStateDiff(diff)
+		if err != nil {
+			c.Streams.Eprintf("Failed to marshal state diff to json: %s\n", err)
+			return 1
+		}
+		c.Streams.Println(string(out))
+		return 0
+	}
+
+	renderer := jsonformat.Renderer{
+		Streams:             c.Streams,
+		Colorize:            c.Colorize(),
+		RunningInAutomation: c.RunningInAutomation,
+		ShowSensitive:       showSensitive,
+	}
+	renderer.RenderHumanStateDiff(diff)
+	return 0
+}
+
+// stateDiffSource loads the state snapshot named by one of the arguments
+// of the state diff command. The source is either a path to a state file,
+// "workspace:NAME" for the latest state of another workspace,
+// "history:SERIAL" for an earlier snapshot of the current workspace, or
+// empty for the latest state of the current workspace.
+func (c *StateDiffCommand) stateDiffSource(ctx context.Context, b backend.Backend, source string) (*statefile.File, error) {
+	switch {
+	case source == "":
+		workspace, err := c.Workspace(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("Error selecting workspace: %w", err)
+		}
+		return stateDiffWorkspace(ctx, b, workspace)
+
+	case strings.HasPrefix(source, stateDiffWorkspacePrefix):
+		workspace := strings.TrimPrefix(source, stateDiffWorkspacePrefix)
+		workspaces, err := b.Workspaces(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list workspaces: %w", err)
+		}
+		found := false
+		for _, name := range workspaces {
+			if name == workspace {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Workspace %q doesn't exist.", workspace)
+		}
+		return stateDiffWorkspace(ctx, b, workspace)
+
+	case strings.HasPrefix(source, stateDiffHistoryPrefix):
+		raw := strings.TrimPrefix(source, stateDiffHistoryPrefix)
+		serial, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid state serial %q: must be a whole number.", raw)
+		}
+		workspace, err := c.Workspace(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("Error selecting workspace: %w", err)
+		}
+		stateMgr, err := b.StateMgr(ctx, workspace)
+		if err != nil {
+			return nil, fmt.Errorf(errStateLoadingState, err)
+		}
+		if err := stateMgr.RefreshState(context.TODO()); err != nil {
+			return nil, fmt.Errorf("Failed to load state: %w", err)
+		}
+		// Serials are only unique within a lineage, so the snapshot is looked
+		// up in the lineage of the current state, as "tofu state rollback"
+		// does.
+		var lineage string
+		if current := statemgr.Export(stateMgr); current != nil {
+			lineage = current.Lineage
+		}
+		version, file, err := findStateVersion(ctx, stateMgr, lineage, serial)
+		if err != nil {
+			return nil, err
+		}
+		if version == nil {
+			return nil, fmt.Errorf(errStateSnapshotNotFound, serial, lineage)
+		}
+		return file, nil
+
+	default:
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read state file %s: %w", source, err)
+		}
+		defer f.Close()
+
+		// Like "tofu state push", we assume the given state file is not
+		// encrypted.
+		file, err := statefile.Read(f, encryption.StateEncryptionDisabled())
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read state file %s: %w", source, err)
+		}
+		return file, nil
+	}
+}
+
+// stateDiffWorkspace returns the latest state of the given workspace, which
+// is empty if the workspace doesn't have any state yet.
+func stateDiffWorkspace(ctx context.Context, b backend.Backend, workspace string) (*statefile.File, error) {
+	stateMgr, err := b.StateMgr(ctx, workspace)
+	if err != nil {
+		return nil, fmt.Errorf(errStateLoadingState, err)
+	}
+	if err := stateMgr.RefreshState(context.TODO()); err != nil {
+		return nil, fmt.Errorf("Failed to load state: %w", err)
+	}
+	file := statemgr.Export(stateMgr)
+	if file == nil || file.State == nil {
+		return statefile.New(states.NewState(), "", 0), nil
+	}
+	return file, nil
+}
+
+// stateDiffState prepares the given state snapshot for the renderer, using
+// the schemas of the providers it refers to.
+func (c *StateDiffCommand) stateDiffState(ctx context.Context, file *statefile.File, config *configs.Config) (jsonformat.State, error) {
+	schemas, diags := c.MaybeGetSchemas(ctx, file.State, config)
+	if diags.HasErrors() {
+		return jsonformat.State{}, diags.Err()
+	}
+	root, outputs, err := jsonstate.MarshalForRenderer(file, schemas)
+	if err != nil {
+		return jsonformat.State{}, err
+	}
+	return jsonformat.State{
+		StateFormatVersion:    jsonstate.FormatVersion,
+		ProviderFormatVersion: jsonprovider.FormatVersion,
+		RootModule:            root,
+		RootModuleOutputs:     outputs,
+		ProviderSchemas:       jsonprovider.MarshalForRenderer(schemas),
+	}, nil
+}
+
+func stateDiffSourceName(source string) string {
+	if source == "" {
+		return "the current workspace"
+	}
+	return source
+}
+
+// stateDiffOutput is the JSON representation of the output of the state diff
+// command.
+type stateDiffOutput struct {
+	FormatVersion   string                           `json:"format_version"`
+	ResourceChanges []stateDiffResourceChange        `json:"resource_changes,omitempty"`
+	OutputChanges   map[string]stateDiffOutputChange `json:"output_changes,omitempty"`
+}
+
+type stateDiffResourceChange struct {
+	Address string          `json:"address"`
+	Mode    string          `json:"mode"`
+	Type    string          `json:"type"`
+	Name    string          `json:"name"`
+	Index   json.RawMessage `json:"index,omitempty"`
+	Deposed string          `json:"deposed,omitempty"`
+
+	// Action is "create" if the object only exists in the new state,
+	// "delete" if it only exists in the old state, or "update" otherwise.
+	Action string `json:"action"`
+
+	Before          jsonstate.AttributeValues `json:"before"`
+	After           jsonstate.AttributeValues `json:"after"`
+	BeforeSensitive json.RawMessage           `json:"before_sensitive,omitempty"`
+	AfterSensitive  json.RawMessage           `json:"after_sensitive,omitempty"`
+}
+
+type stateDiffOutputChange struct {
+	Action          string          `json:"action"`
+	Before          json.RawMessage `json:"before"`
+	After           json.RawMessage `json:"after"`
+	BeforeSensitive bool            `json:"before_sensitive"`
+	AfterSensitive  bool            `json:"after_sensitive"`
+}
+
+// marshalStateDiff returns the JSON representation of the given diff. Like
+// "tofu show -json", sensitive values are included and only marked as such.
+func marshalStateDiff(diff jsonformat.StateDiff) ([]byte, error) {
+	output := stateDiffOutput{
+		FormatVersion: stateDiffFormatVersion,
+	}
+
+	for _, resource := range diff.Resources() {
+		change := stateDiffResourceChange{
+			Address: resource.Address,
+			Deposed: resource.Deposed,
+			Action:  stateDiffAction(resource.Diff.Action),
+		}
+		if resource.Before != nil {
+			change.Mode = resource.Before.Mode
+			change.Type = resource.Before.Type
+			change.Name = resource.Before.Name
+			change.Index = resource.Before.Index
+			change.Before = resource.Before.AttributeValues
+			change.BeforeSensitive = resource.Before.SensitiveValues
+		}
+		if resource.After != nil {
+			change.Mode = resource.After.Mode
+			change.Type = resource.After.Type
+			change.Name = resource.After.Name
+			change.Index = resource.After.Index
+			change.After = resource.After.AttributeValues
+			change.AfterSensitive = resource.After.SensitiveValues
+		}
+		output.ResourceChanges = append(output.ResourceChanges, change)
+	}
+
+	for _, out := range diff.Outputs() {
+		change := stateDiffOutputChange{
+			Action: stateDiffAction(out.Diff.Action),
+		}
+		if out.Before != nil {
+			change.Before = out.Before.Value
+			change.BeforeSensitive = out.Before.Sensitive
+		}
+		if out.After != nil {
+			change.After = out.After.Value
+			change.AfterSensitive = out.After.Sensitive
+		}
+		if output.OutputChanges == nil {
+			output.OutputChanges = make(map[string]stateDiffOutputChange)
+		}
+		output.OutputChanges[out.Name] = change
+	}
+
+	return json.Marshal(output)
+}
+
+func stateDiffAction(action plans.Action) string {
+	switch action {
+	case plans.Create:
+		return "create"
+	case plans.Delete:
+		return "delete"
+	default:
+		return "update"
+	}
+}
+
+func (c *StateDiffCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] state diff [options] OLD [NEW]
+
+  Show the differences between two state snapshots.
+
+  Resource instances are matched by their address, and the attributes that
+  differ between them are shown in the same form as in a plan. Output values
+  of the root module are compared too.
+
+  OLD and NEW can each be one of the following:
+
+    PATH               A state file on disk, which must not be encrypted.
+    workspace:NAME     The latest state of the named workspace.
+    history:SERIAL     An earlier snapshot of the current workspace's state,
+                       as listed by "tofu state history". Only snapshots in
+                       the lineage of the current state are considered.
+
+  If NEW is omitted, OLD is compared with the latest state of the current
+  workspace.
+
+  The providers of the resources in both states must be installed, since
+  their schemas are needed to show the differences.
+
+Options:
+
+  -json               Produce output in a machine-readable JSON format.
+                      Sensitive values are included in the output, and
+                      marked as such.
+
+  -show-sensitive     If specified, sensitive values will be displayed.
+
+  -var 'foo=bar'      Set a value for one of the input variables in the root
+                      module of the configuration. Use this option more than
+                      once to set more than one variable.
+
+  -var-file=filename  Load variable values from the given file, in addition
+                      to the default files terraform.tfvars and *.auto.tfvars.
+                      Use this option more than once to include more than one
+                      variables file.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateDiffCommand) Synopsis() string {
+	return "Show the differences between two state snapshots"
+}
+
+const errStateDiffSchemas = `Failed to prepare the state of %s: %s
+
+The schemas of the providers used by the state are needed to compare it.
+Run "tofu init" to install them.`
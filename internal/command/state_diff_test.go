@@ -0,0 +1,194 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/internal/terminal"
+)
+
+func TestStateDiff(t *testing.T) {
+	testCwdTemp(t)
+	testStateFileDefault(t, testStateHistoryState(false))
+	oldPath := testStateFile(t, testStateHistoryState(true))
+
+	streams, done := terminal.StreamsForTesting(t)
+	c := &StateDiffCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			Ui:               new(cli.MockUi),
+			Streams:          streams,
+		},
+	}
+
+	code := c.Run([]string{oldPath})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+
+	actual := output.Stdout()
+	for _, want := range []string{
+		"  # test_instance.bar was removed\n  - resource \"test_instance\" \"bar\" {",
+		"Summary: 0 added, 0 changed, 1 removed.",
+	} {
+		if !strings.Contains(actual, want) {
+			t.Errorf("output is missing %q\n%s", want, actual)
+		}
+	}
+	if strings.Contains(actual, "test_instance.foo") {
+		t.Errorf("unchanged resource instance should not be shown\n%s", actual)
+	}
+}
+
+func TestStateDiff_json(t *testing.T) {
+	testCwdTemp(t)
+	testStateFileDefault(t, testStateHistoryState(true))
+	oldPath := testStateFile(t, testStateHistoryState(false))
+
+	streams, done := terminal.StreamsForTesting(t)
+	c := &StateDiffCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			Ui:               new(cli.MockUi),
+			Streams:          streams,
+		},
+	}
+
+	code := c.Run([]string{"-json", oldPath})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+
+	var got stateDiffOutput
+	if err := json.Unmarshal([]byte(output.Stdout()), &got); err != nil {
+		t.Fatalf("invalid JSON output: %s\n%s", err, output.Stdout())
+	}
+	if got.FormatVersion != stateDiffFormatVersion {
+		t.Errorf("wrong format version %q", got.FormatVersion)
+	}
+	if len(got.ResourceChanges) != 1 {
+		t.Fatalf("expected one resource change, got %d\n%s", len(got.ResourceChanges), output.Stdout())
+	}
+	change := got.ResourceChanges[0]
+	if change.Address != "test_instance.bar" || change.Action != "create" || change.Before != nil {
+		t.Errorf("wrong resource change %#v", change)
+	}
+	if diff := cmp.Diff(`"bar"`, string(change.After["id"])); diff != "" {
+		t.Errorf("wrong id after\n%s", diff)
+	}
+}
+
+func TestStateDiff_history(t *testing.T) {
+	testCwdTemp(t)
+	for _, f := range []struct {
+		path    string
+		withBar bool
+		serial  uint64
+	}{
+		{DefaultStateFilename, false, 2},
+		{DefaultStateFilename + ".1700000000.backup", true, 1},
+	} {
+		fh, err := os.Create(f.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = statefile.Write(statefile.New(testStateHistoryState(f.withBar), "history-lineage", f.serial), fh, encryption.StateEncryptionDisabled())
+		fh.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	streams, done := terminal.StreamsForTesting(t)
+	c := &StateDiffCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			Ui:               new(cli.MockUi),
+			Streams:          streams,
+		},
+	}
+
+	code := c.Run([]string{"history:1", "workspace:default"})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+	if want := "  # test_instance.bar was removed"; !strings.Contains(output.Stdout(), want) {
+		t.Errorf("output is missing %q\n%s", want, output.Stdout())
+	}
+}
+
+func TestStateDiff_historyNotFound(t *testing.T) {
+	testCwdTemp(t)
+	testStateFileDefault(t, testStateHistoryState(false))
+
+	streams, done := terminal.StreamsForTesting(t)
+	c := &StateDiffCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			Ui:               new(cli.MockUi),
+			Streams:          streams,
+		},
+	}
+
+	code := c.Run([]string{"history:42"})
+	output := done(t)
+	if code != 1 {
+		t.Fatalf("expected failure, got %d\n\n%s", code, output.Stdout())
+	}
+	if want := "No state snapshot with serial 42 was found in lineage"; !strings.Contains(output.Stderr(), want) {
+		t.Errorf("wrong error\n%s", output.Stderr())
+	}
+}
+
+func TestStateDiff_historyOtherLineage(t *testing.T) {
+	testCwdTemp(t)
+	for _, f := range []struct {
+		path    string
+		lineage string
+		serial  uint64
+	}{
+		{DefaultStateFilename, "current-lineage", 2},
+		{DefaultStateFilename + ".1700000000.backup", "other-lineage", 1},
+	} {
+		fh, err := os.Create(f.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = statefile.Write(statefile.New(testStateHistoryState(false), f.lineage, f.serial), fh, encryption.StateEncryptionDisabled())
+		fh.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	streams, done := terminal.StreamsForTesting(t)
+	c := &StateDiffCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			Ui:               new(cli.MockUi),
+			Streams:          streams,
+		},
+	}
+
+	code := c.Run([]string{"history:1"})
+	output := done(t)
+	if code != 1 {
+		t.Fatalf("expected failure, got %d\n\n%s", code, output.Stdout())
+	}
+	if want := `No state snapshot with serial 1 was found in lineage "current-lineage".`; !strings.Contains(output.Stderr(), want) {
+		t.Errorf("wrong error\n%s", output.Stderr())
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+)
+
+// StateHistoryCommand is a Command implementation that lists the earlier
+// state snapshots retained by the state storage.
+type StateHistoryCommand struct {
+	Meta
+}
+
+func (c *StateHistoryCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+
+	args = c.Meta.process(args)
+	var statePath string
+	var limit int
+	cmdFlags := c.Meta.defaultFlagSet("state history")
+	c.Meta.varFlagSet(cmdFlags)
+	cmdFlags.StringVar(&statePath, "state", "", "path")
+	cmdFlags.IntVar(&limit, "limit", 20, "limit")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return cli.RunResultHelp
+	}
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The state history command expects no arguments.\n")
+		return cli.RunResultHelp
+	}
+	if limit < 0 {
+		c.Ui.Error("The -limit option must not be negative.\n")
+		return cli.RunResultHelp
+	}
+
+	if statePath != "" {
+		c.Meta.statePath = statePath
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := c.Encryption(ctx)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(encDiags)
+		return 1
+	}
+
+	// Load the backend
+	b, backendDiags := c.Backend(ctx, nil, enc.State())
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(backendDiags)
+		return 1
+	}
+
+	// This is a read-only command
+	c.ignoreRemoteVersionConflict(b)
+
+	env, err := c.Workspace(ctx)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error selecting workspace: %s", err))
+		return 1
+	}
+	stateMgr, err := b.StateMgr(ctx, env)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return 1
+	}
+	if err := stateMgr.RefreshState(context.TODO()); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
+		return 1
+	}
+
+	versions, err := stateHistory(ctx, stateMgr, limit)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	if len(versions) == 0 {
+		c.Ui.Output("No state snapshots were found.")
+		return 0
+	}
+
+	current := statemgr.Export(stateMgr)
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERIAL\tWRITTEN\tLINEAGE\tID")
+	for _, v := range versions {
+		serial := fmt.Sprint(v.Serial)
+		if current != nil && v.Serial == current.Serial && v.Lineage == current.Lineage {
+			serial += " (current)"
+		}
+		written := "-"
+		if !v.Time.IsZero() {
+			written = v.Time.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", serial, written, v.Lineage, v.ID)
+	}
+	w.Flush()
+	c.Ui.Output(strings.TrimSuffix(buf.String(), "\n"))
+
+	return 0
+}
+
+// stateHistory returns up to limit of the newest snapshots retained by the
+// given state manager, or all of them if limit is zero, or an error explaining
+// that the state storage doesn't retain any.
+func stateHistory(ctx context.Context, stateMgr statemgr.Full, limit int) ([]*statemgr.SnapshotVersion, error) {
+	history, ok := stateMgr.(statemgr.History)
+	if !ok {
+		return nil, fmt.Errorf(errStateHistoryNotSupported, statemgr.ErrHistoryNotSupported)
+	}
+	versions, err := history.StateHistory(ctx, limit)
+	if errors.Is(err, statemgr.ErrHistoryNotSupported) {
+		return nil, fmt.Errorf(errStateHistoryNotSupported, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list state snapshots: %w", err)
+	}
+	return versions, nil
+}
+
+// findStateVersion returns the newest snapshot with the given lineage and
+// serial retained by the given state manager along with its content, or nil
+// if there is none, or an error explaining that the state storage doesn't
+// retain any.
+func findStateVersion(ctx context.Context, stateMgr statemgr.Full, lineage string, serial uint64) (*statemgr.SnapshotVersion, *statefile.File, error) {
+	history, ok := stateMgr.(statemgr.History)
+	if !ok {
+		return nil, nil, fmt.Errorf(errStateHistoryNotSupported, statemgr.ErrHistoryNotSupported)
+	}
+	version, file, err := history.FindStateVersion(ctx, lineage, serial)
+	if errors.Is(err, statemgr.ErrHistoryNotSupported) {
+		return nil, nil, fmt.Errorf(errStateHistoryNotSupported, err)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to read state snapshots: %w", err)
+	}
+	return version, file, nil
+}
+
+// readStateVersion returns the snapshot with the given version ID retained by
+// the given state manager, or an error explaining that the state storage
+// doesn't retain any.
+func readStateVersion(ctx context.Context, stateMgr statemgr.Full, id string) (*statefile.File, error) {
+	history, ok := stateMgr.(statemgr.History)
+	if !ok {
+		return nil, fmt.Errorf(errStateHistoryNotSupported, statemgr.ErrHistoryNotSupported)
+	}
+	file, err := history.StateVersion(ctx, id)
+	if errors.Is(err, statemgr.ErrHistoryNotSupported) {
+		return nil, fmt.Errorf(errStateHistoryNotSupported, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read state snapshot %s: %w", id, err)
+	}
+	return file, nil
+}
+
+func (c *StateHistoryCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] state history [options]
+
+  List the earlier state snapshots retained by the state storage.
+
+  Only some state storage retains earlier snapshots: the local backend
+  keeps backup files alongside the state file, while remote backends such as
+  s3, gcs and azurerm retain earlier versions of the state when object
+  versioning (or, for azurerm, snapshots) is enabled.
+
+  The snapshots are listed newest first. Use "tofu state rollback" to
+  restore the state to one of them.
+
+Options:
+
+  -limit=n            List at most n snapshots. Each snapshot must be
+                      downloaded to read its serial and lineage, so only the
+                      newest 20 are listed by default. Use 0 to list all.
+
+  -state=statefile    Path to a OpenTofu state file to list the backups of.
+                      By default, OpenTofu will consult the state of the
+                      currently-selected workspace.
+
+  -var 'foo=bar'      Set a value for one of the input variables in the root
+                      module of the configuration. Use this option more than
+                      once to set more than one variable.
+
+  -var-file=filename  Load variable values from the given file, in addition
+                      to the default files terraform.tfvars and *.auto.tfvars.
+                      Use this option more than once to include more than one
+                      variables file.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateHistoryCommand) Synopsis() string {
+	return "List earlier state snapshots"
+}
+
+const errStateHistoryNotSupported = `Error listing state snapshots: %s
+
+State history is only available for the local backend and for remote
+backends that retain earlier versions of the state, such as s3, gcs and
+azurerm with object versioning enabled.`
+
+const errStateSnapshotNotFound = `No state snapshot with serial %d was found in lineage %q.
+
+Use "tofu state history" to list the snapshots that are available.`
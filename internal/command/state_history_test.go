@@ -0,0 +1,153 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+)
+
+func TestStateHistory(t *testing.T) {
+	statePath := testStateHistoryFiles(t)
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StateHistoryCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+			View:             view,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(ui.OutputWriter.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected a header and 3 snapshots, got:\n%s", ui.OutputWriter.String())
+	}
+	for i, want := range []string{
+		"2 (current)",
+		"1 ",
+		"1 ",
+	} {
+		if !strings.HasPrefix(lines[i+1], want) {
+			t.Errorf("line %d should start with %q, got %q", i+1, want, lines[i+1])
+		}
+	}
+	if !strings.HasSuffix(lines[3], "state.tfstate.1700000000.backup") {
+		t.Errorf("wrong oldest snapshot: %q", lines[3])
+	}
+}
+
+func TestStateHistory_limit(t *testing.T) {
+	statePath := testStateHistoryFiles(t)
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StateHistoryCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+			View:             view,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-limit", "2",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(ui.OutputWriter.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 snapshots, got:\n%s", ui.OutputWriter.String())
+	}
+	if !strings.HasSuffix(lines[2], "state.tfstate.backup") {
+		t.Errorf("wrong oldest snapshot: %q", lines[2])
+	}
+}
+
+// testStateHistoryFiles writes a state file with serial 2 containing only
+// test_instance.foo, along with two backups with serial 1 that also contain
+// test_instance.bar, and returns the path of the state file.
+func testStateHistoryFiles(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "state.tfstate")
+	now := time.Now()
+
+	current := testStateHistoryState(false)
+	previous := testStateHistoryState(true)
+	for _, f := range []struct {
+		name   string
+		state  *states.State
+		serial uint64
+		age    time.Duration
+	}{
+		{"state.tfstate", current, 2, 0},
+		{"state.tfstate.backup", previous, 1, time.Minute},
+		{"state.tfstate.1700000000.backup", previous, 1, time.Hour},
+	} {
+		path := filepath.Join(dir, f.name)
+		fh, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = statefile.Write(statefile.New(f.state, "history-lineage", f.serial), fh, encryption.StateEncryptionDisabled())
+		fh.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-f.age), now.Add(-f.age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return statePath
+}
+
+func testStateHistoryState(withBar bool) *states.State {
+	return states.BuildState(func(s *states.SyncState) {
+		names := []string{"foo"}
+		if withBar {
+			names = append(names, "bar")
+		}
+		for _, name := range names {
+			s.SetResourceInstanceCurrent(
+				addrs.Resource{
+					Mode: addrs.ManagedResourceMode,
+					Type: "test_instance",
+					Name: name,
+				}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+				&states.ResourceInstanceObjectSrc{
+					AttrsJSON: []byte(`{"id":"` + name + `"}`),
+					Status:    states.ObjectReady,
+				},
+				addrs.AbsProviderConfig{
+					Provider: addrs.NewDefaultProvider("test"),
+					Module:   addrs.RootModule,
+				},
+				addrs.NoKey,
+			)
+		}
+	})
+}
@@ -0,0 +1,276 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/clistate"
+	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// StateRollbackCommand is a Command implementation that restores the state
+// to an earlier snapshot retained by the state storage.
+type StateRollbackCommand struct {
+	StateMeta
+}
+
+func (c *StateRollbackCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+	args = c.Meta.process(args)
+	var dryRun bool
+	var versionID string
+	cmdFlags := c.Meta.ignoreRemoteVersionFlagSet("state rollback")
+	cmdFlags.BoolVar(&dryRun, "dry-run", false, "dry run")
+	cmdFlags.StringVar(&versionID, "version-id", "", "version id")
+	cmdFlags.StringVar(&c.backupPath, "backup", "-", "backup")
+	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
+	cmdFlags.StringVar(&c.statePath, "state", "", "path")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	args = cmdFlags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("Exactly one argument expected: the serial of the state snapshot to restore.\n")
+		return cli.RunResultHelp
+	}
+	serial, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Invalid state serial %q: must be a whole number.", args[0]))
+		return 1
+	}
+
+	if diags := c.Meta.checkRequiredVersion(ctx); diags != nil {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := c.Encryption(ctx)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(encDiags)
+		return 1
+	}
+
+	// Get the state
+	stateMgr, err := c.State(ctx, enc)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return 1
+	}
+
+	if c.stateLock {
+		stateLocker := clistate.NewLocker(c.stateLockTimeout, views.NewStateLocker(arguments.ViewHuman, c.View))
+		if diags := stateLocker.Lock(stateMgr, "state-rollback"); diags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+		defer func() {
+			if diags := stateLocker.Unlock(); diags.HasErrors() {
+				c.showDiagnostics(diags)
+			}
+		}()
+	}
+
+	if err := stateMgr.RefreshState(context.TODO()); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to refresh state: %s", err))
+		return 1
+	}
+
+	current := statemgr.Export(stateMgr)
+	if current == nil || current.State == nil {
+		c.Ui.Error(errStateNotFound)
+		return 1
+	}
+
+	// We only roll back within the lineage of the current state, since a
+	// snapshot from another lineage describes unrelated infrastructure.
+	var targetID string
+	var targetFile *statefile.File
+	if versionID != "" {
+		f, err := readStateVersion(ctx, stateMgr, versionID)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		if f != nil && f.Serial == serial && f.Lineage == current.Lineage {
+			targetID, targetFile = versionID, f
+		}
+	} else {
+		target, f, err := findStateVersion(ctx, stateMgr, current.Lineage, serial)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		if target != nil {
+			targetID, targetFile = target.ID, f
+		}
+	}
+	if targetFile == nil {
+		c.Ui.Error(fmt.Sprintf(errStateSnapshotNotFound, serial, current.Lineage))
+		return 1
+	}
+
+	changes := stateInstanceChanges(current.State, targetFile.State)
+	prefix := "Rolling back"
+	if dryRun {
+		prefix = "Would roll back"
+	}
+	c.Ui.Output(fmt.Sprintf("%s from serial %d to the snapshot with serial %d (%s).", prefix, current.Serial, serial, targetID))
+	if len(changes) == 0 {
+		c.Ui.Output("No resource instances differ between the two snapshots.")
+	}
+	for _, change := range changes {
+		c.Ui.Output("  " + change)
+	}
+
+	if dryRun {
+		return 0 // This is as far as we go in dry-run mode
+	}
+
+	b, backendDiags := c.Backend(ctx, nil, enc.State())
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(backendDiags)
+		return 1
+	}
+
+	// Get schemas, if possible, before writing state
+	var schemas *tofu.Schemas
+	var diags tfdiags.Diagnostics
+	if isCloudMode(b) {
+		schemas, diags = c.MaybeGetSchemas(ctx, targetFile.State, nil)
+	}
+
+	// The restored snapshot is written as a new snapshot, so its serial will
+	// be higher than the current one rather than the serial it was read from.
+	if err := stateMgr.WriteState(targetFile.State); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateRollbackPersist, err))
+		return 1
+	}
+	if err := stateMgr.PersistState(context.TODO(), schemas); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateRollbackPersist, err))
+		return 1
+	}
+
+	c.showDiagnostics(diags)
+	c.Ui.Output(fmt.Sprintf("Successfully rolled back to the state snapshot with serial %d.", serial))
+	return 0
+}
+
+// stateInstanceChanges describes the resource instances that differ between
+// two states, sorted by address. Each is prefixed with "+" if it is only in
+// the new state, "-" if it is only in the old state, or "~" if it differs.
+func stateInstanceChanges(oldState, newState *states.State) []string {
+	type pair struct {
+		old, new *states.ResourceInstance
+	}
+	instances := make(map[string]*pair)
+	collect := func(state *states.State, set func(*pair, *states.ResourceInstance)) {
+		for _, ms := range state.Modules {
+			for _, rs := range ms.Resources {
+				for key, is := range rs.Instances {
+					addr := rs.Addr.Instance(key).String()
+					if instances[addr] == nil {
+						instances[addr] = &pair{}
+					}
+					set(instances[addr], is)
+				}
+			}
+		}
+	}
+	collect(oldState, func(p *pair, is *states.ResourceInstance) { p.old = is })
+	collect(newState, func(p *pair, is *states.ResourceInstance) { p.new = is })
+
+	var ret []string
+	for addr, p := range instances {
+		switch {
+		case p.old == nil:
+			ret = append(ret, "+ "+addr)
+		case p.new == nil:
+			ret = append(ret, "- "+addr)
+		case !p.old.Equal(p.new):
+			ret = append(ret, "~ "+addr)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i][2:] < ret[j][2:]
+	})
+	return ret
+}
+
+func (c *StateRollbackCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] state rollback [options] SERIAL
+
+  Restore the state to an earlier snapshot retained by the state storage.
+
+  This command finds the snapshot with the given serial in the lineage of
+  the current state, as listed by "tofu state history", and writes it as the
+  latest state. The restored state is written with a new serial that is
+  higher than the current one, so the rollback itself can also be undone.
+
+  Before writing, the command lists the resource instances that the
+  rollback adds (+), removes (-) or changes (~).
+
+Options:
+
+  -dry-run                If set, prints out what would change but doesn't
+                          actually roll back the state.
+
+  -version-id=ID          The ID of the snapshot to restore, for when the
+                          history contains more than one snapshot with the
+                          same serial. By default the newest is restored.
+
+  -backup=PATH            Path where OpenTofu should write the backup
+                          state.
+
+  -lock=false             Don't hold a state lock during the operation. This is
+                          dangerous if others might concurrently run commands
+                          against the same workspace.
+
+  -lock-timeout=0s        Duration to retry a state lock.
+
+  -state=PATH             Path to the state file to update. Defaults to the
+                          current workspace state.
+
+  -ignore-remote-version  Continue even if remote and local OpenTofu versions
+                          are incompatible. This may result in an unusable
+                          workspace, and should be used with extreme caution.
+
+  -var 'foo=bar'          Set a value for one of the input variables in the root
+                          module of the configuration. Use this option more than
+                          once to set more than one variable.
+
+  -var-file=filename      Load variable values from the given file, in addition
+                          to the default files terraform.tfvars and *.auto.tfvars.
+                          Use this option more than once to include more than one
+                          variables file.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateRollbackCommand) Synopsis() string {
+	return "Restore the state to an earlier snapshot"
+}
+
+const errStateRollbackPersist = `Error saving the state: %s
+
+The state was not saved, so it has not been rolled back. Please resolve
+the issue above and try again.`
@@ -0,0 +1,152 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+var testStateRollbackBarAddr = addrs.Resource{
+	Mode: addrs.ManagedResourceMode,
+	Type: "test_instance",
+	Name: "bar",
+}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+
+func TestStateRollback(t *testing.T) {
+	statePath := testStateHistoryFiles(t)
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StateRollbackCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"1",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if got := ui.OutputWriter.String(); !strings.Contains(got, "+ test_instance.bar") {
+		t.Errorf("expected the added instance to be listed, got:\n%s", got)
+	}
+
+	state := testStateRead(t, statePath)
+	if state.ResourceInstance(testStateRollbackBarAddr) == nil {
+		t.Fatalf("test_instance.bar was not restored:\n%s", state)
+	}
+}
+
+func TestStateRollback_dryRun(t *testing.T) {
+	statePath := testStateHistoryFiles(t)
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StateRollbackCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-dry-run",
+		"1",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if got := ui.OutputWriter.String(); !strings.Contains(got, "Would roll back from serial 2") {
+		t.Errorf("wrong output:\n%s", got)
+	}
+
+	state := testStateRead(t, statePath)
+	if state.ResourceInstance(testStateRollbackBarAddr) != nil {
+		t.Fatalf("dry run should not have changed the state:\n%s", state)
+	}
+}
+
+func TestStateRollback_notFound(t *testing.T) {
+	statePath := testStateHistoryFiles(t)
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StateRollbackCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"5",
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("expected failure, got %d", code)
+	}
+	if got := ui.ErrorWriter.String(); !strings.Contains(got, "No state snapshot with serial 5") {
+		t.Errorf("wrong error:\n%s", got)
+	}
+}
+
+func TestStateRollback_versionID(t *testing.T) {
+	statePath := testStateHistoryFiles(t)
+	versionID := statePath + ".1700000000.backup"
+
+	for name, tc := range map[string]struct {
+		serial   string
+		wantCode int
+		want     string
+	}{
+		"matching serial": {"1", 0, "Would roll back from serial 2 to the snapshot with serial 1 (" + versionID + ")."},
+		"other serial":    {"2", 1, "No state snapshot with serial 2"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ui := new(cli.MockUi)
+			view, _ := testView(t)
+			c := &StateRollbackCommand{
+				StateMeta{
+					Meta: Meta{
+						testingOverrides: metaOverridesForProvider(testProvider()),
+						Ui:               ui,
+						View:             view,
+					},
+				},
+			}
+
+			args := []string{
+				"-state", statePath,
+				"-dry-run",
+				"-version-id", versionID,
+				tc.serial,
+			}
+			if code := c.Run(args); code != tc.wantCode {
+				t.Fatalf("wrong exit code %d; want %d\n\n%s", code, tc.wantCode, ui.ErrorWriter.String())
+			}
+			if got := ui.OutputWriter.String() + ui.ErrorWriter.String(); !strings.Contains(got, tc.want) {
+				t.Errorf("output is missing %q:\n%s", tc.want, got)
+			}
+		})
+	}
+}
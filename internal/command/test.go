@@ -10,10 +10,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"path"
+	"path/filepath"
 	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/opentofu/opentofu/internal/lang"
@@ -24,12 +27,18 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/junit"
+	"github.com/opentofu/opentofu/internal/command/snapshot"
 	"github.com/opentofu/opentofu/internal/command/views"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/logging"
 	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/moduletest/coverage"
+	"github.com/opentofu/opentofu/internal/moduletest/keptstate"
+	"github.com/opentofu/opentofu/internal/moduletest/recording"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/statefile"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -74,21 +83,69 @@ Options:
                         will be performed. All locations, for all errors
                         will be listed. Disabled by default
 
+  -coverage             Print a summary of which resources, data sources,
+                        outputs and checks were exercised by the run blocks,
+                        and list the custom conditions that were never
+                        evaluated.
+
+  -coverage-out=path    Also write a JSON coverage report, keyed by the source
+                        location of each object, to the given file.
+
+  -destroy-kept-state   Destroy the infrastructure kept by a previous execution
+                        with -run-only, instead of executing any tests. The
+                        same variables must be given as for that execution.
+                        Can be combined with -filter to select the test files.
+
+  -file-parallelism=n   Execute up to n test files concurrently. Defaults to 1.
+                        Test files that apply the same configuration are never
+                        executed concurrently. The output for each test file
+                        is shown once the whole file has completed.
+
   -filter=testfile      If specified, OpenTofu will only execute the test files
                         specified by this flag. You can use this option multiple
                         times to execute more than one test file. The path should
                         be relative to the current working directory, even if
-                        -test-directory is set.
+                        -test-directory is set. To execute a single run block,
+                        add its name after a colon, like main.tftest.hcl:setup.
+                        The runs expanded from a run block with for_each can be
+                        selected together by the block name, or individually
+                        by names like main.tftest.hcl:cases[small].
 
   -json                 If specified, machine readable output will be printed in
                         JSON format
 
+  -junit-xml=path       Also write a JUnit XML report of the test results to
+                        the given file. The report is written even if the
+                        tests are interrupted.
+
   -no-color             If specified, output won't contain any color.
 
+  -record-providers     Record the requests that read or change infrastructure
+                        made to providers by each run block, along with the
+                        responses, into a fixture file in a "fixtures"
+                        directory next to the test file.
+
+  -replay-providers     Serve the requests that read or change infrastructure
+                        from the fixture files created by -record-providers
+                        instead of calling the providers, so no real
+                        infrastructure is created. The test fails if a request
+                        doesn't match the recording.
+
+  -run-only=file:run    Execute only the given test file, stopping after the
+                        named run block, and keep the infrastructure it
+                        created instead of destroying it. The states are
+                        written to .terraform/test-states, where they can be
+                        inspected with commands such as "tofu state list
+                        -state=PATH".
+
   -test-directory=path  Set the OpenTofu test directory, defaults to "tests". When set, the
                         test command will search for test files in the current directory and
                         in the one specified by the flag.
 
+  -update-snapshots     Write the plans of run blocks that set "snapshot = true"
+                        to their snapshot files, instead of comparing the plans
+                        against them.
+
   -var 'foo=bar'        Set a value for one of the input variables in the root
                         module of the configuration. Use this option more than
                         once to set more than one variable.
@@ -166,8 +223,112 @@ func (c *TestCommand) Run(rawArgs []string) int {
 		Files: func() map[string]*moduletest.File {
 			files := make(map[string]*moduletest.File)
 
+			addFile := func(name string, file *configs.TestFile, runNames []string) {
+				fileCount++
+
+				var runs []*moduletest.Run
+				for ix, run := range file.Runs {
+					if runNames != nil && !slices.Contains(runNames, run.Name) && !slices.Contains(runNames, run.BlockName) {
+						continue
+					}
+					runs = append(runs, &moduletest.Run{
+						Config: run,
+						Index:  ix,
+						Name:   run.Name,
+					})
+				}
+
+				runCount += len(runs)
+				files[name] = &moduletest.File{
+					Config: file,
+					Name:   name,
+					Runs:   runs,
+				}
+			}
+
+			if args.DestroyKeptState {
+				// We only need the test files that have kept states, which
+				// can still be restricted with the usual filter.
+				names, err := keptstate.Files(keptstate.DefaultDir)
+				if err != nil {
+					fileDiags = fileDiags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Failed to find kept test state",
+						fmt.Sprintf("Could not read the kept test states in %s: %s.", keptstate.DefaultDir, err)))
+					return files
+				}
+				for _, name := range names {
+					if len(args.Filter) > 0 && !slices.Contains(args.Filter, name) {
+						continue
+					}
+					file, ok := config.Module.Tests[name]
+					if !ok {
+						fileDiags = fileDiags.Append(tfdiags.Sourceless(
+							tfdiags.Warning,
+							"Unknown test file",
+							fmt.Sprintf("States were kept for the test file %s, but it could not be found. The infrastructure in the states kept in %s must be destroyed manually.", name, filepath.Join(keptstate.DefaultDir, name))))
+						continue
+					}
+					addFile(name, file, nil)
+				}
+				return files
+			}
+
+			if args.RunOnly != "" {
+				// We execute the run blocks in the file up to and including
+				// the named run block, which might have been expanded into
+				// several runs.
+				name, runName, _ := strings.Cut(args.RunOnly, ":")
+				file, ok := config.Module.Tests[name]
+				if !ok {
+					fileDiags = fileDiags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Unknown test file",
+						fmt.Sprintf("The specified test file, %s, could not be found.", name)))
+					return files
+				}
+
+				last := -1
+				for ix, run := range file.Runs {
+					if run.Name == runName || run.BlockName == runName {
+						last = ix
+					}
+				}
+				if last < 0 {
+					fileDiags = fileDiags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Unknown run block",
+						fmt.Sprintf("The specified run block, %s, could not be found in %s.", runName, name)))
+					return files
+				}
+
+				// Executing the file again would lose track of the
+				// infrastructure kept by a previous execution.
+				if kept, err := keptstate.Files(keptstate.DefaultDir); err == nil && slices.Contains(kept, name) {
+					fileDiags = fileDiags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Test state already kept",
+						fmt.Sprintf("The infrastructure created by a previous execution of %s has been kept. Destroy it with \"tofu test -destroy-kept-state\" before executing the file again.", name)))
+					return files
+				}
+
+				runNames := make([]string, 0, last+1)
+				for _, run := range file.Runs[:last+1] {
+					runNames = append(runNames, run.Name)
+				}
+				addFile(name, file, runNames)
+				return files
+			}
+
 			if len(args.Filter) > 0 {
-				for _, name := range args.Filter {
+				// Each filter is either the path of a test file, or the path
+				// of a test file and the name of a run block within it
+				// separated by a colon. A nil list of run names selects all
+				// the run blocks in the file.
+				var names []string
+				runNames := make(map[string][]string)
+				for _, filter := range args.Filter {
+					name, runName, hasRunName := strings.Cut(filter, ":")
 					file, ok := config.Module.Tests[name]
 					if !ok {
 						// If the filter is invalid, we'll simply skip this
@@ -180,47 +341,37 @@ func (c *TestCommand) Run(rawArgs []string) int {
 						continue
 					}
 
-					fileCount++
-
-					var runs []*moduletest.Run
-					for ix, run := range file.Runs {
-						runs = append(runs, &moduletest.Run{
-							Config: run,
-							Index:  ix,
-							Name:   run.Name,
-						})
+					if hasRunName && !slices.ContainsFunc(file.Runs, func(run *configs.TestRun) bool {
+						return run.Name == runName || run.BlockName == runName
+					}) {
+						fileDiags = fileDiags.Append(tfdiags.Sourceless(
+							tfdiags.Warning,
+							"Unknown run block",
+							fmt.Sprintf("The specified run block, %s, could not be found in %s.", runName, name)))
+						continue
 					}
 
-					runCount += len(runs)
-					files[name] = &moduletest.File{
-						Config: file,
-						Name:   name,
-						Runs:   runs,
+					existing, seen := runNames[name]
+					if !seen {
+						names = append(names, name)
+					}
+					switch {
+					case !hasRunName:
+						runNames[name] = nil
+					case !seen || existing != nil:
+						runNames[name] = append(existing, runName)
 					}
 				}
 
+				for _, name := range names {
+					addFile(name, config.Module.Tests[name], runNames[name])
+				}
 				return files
 			}
 
 			// Otherwise, we'll just do all the tests in the directory!
 			for name, file := range config.Module.Tests {
-				fileCount++
-
-				var runs []*moduletest.Run
-				for ix, run := range file.Runs {
-					runs = append(runs, &moduletest.Run{
-						Config: run,
-						Index:  ix,
-						Name:   run.Name,
-					})
-				}
-
-				runCount += len(runs)
-				files[name] = &moduletest.File{
-					Config: file,
-					Name:   name,
-					Runs:   runs,
-				}
+				addFile(name, file, nil)
 			}
 			return files
 		}(),
@@ -228,7 +379,7 @@ func (c *TestCommand) Run(rawArgs []string) int {
 
 	log.Printf("[DEBUG] TestCommand: found %d files with %d run blocks", fileCount, runCount)
 
-	if len(args.Filter) > 0 && len(suite.Files) == 0 {
+	if len(args.Filter) > 0 && len(suite.Files) == 0 && !args.DestroyKeptState {
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Warning,
 			"No tests were found",
@@ -287,10 +438,31 @@ func (c *TestCommand) Run(rawArgs []string) int {
 		Cancelled: false,
 		Stopped:   false,
 
-		Verbose: args.Verbose,
+		Verbose:         args.Verbose,
+		FileParallelism: args.FileParallelism,
+		RecordProviders: args.RecordProviders,
+		ReplayProviders: args.ReplayProviders,
+		UpdateSnapshots: args.UpdateSnapshots,
+
+		KeepState:        args.RunOnly != "",
+		DestroyKeptState: args.DestroyKeptState,
+	}
+
+	if args.Coverage || args.CoveragePath != "" {
+		runner.Coverage = coverage.NewReport()
+		runner.Coverage.AddConfig(config)
+		for _, file := range suite.Files {
+			for _, run := range file.Runs {
+				if run.Config.ConfigUnderTest != nil {
+					runner.Coverage.AddConfig(run.Config.ConfigUnderTest)
+				}
+			}
+		}
 	}
 
-	view.Abstract(&suite)
+	if !runner.DestroyKeptState {
+		view.Abstract(&suite)
+	}
 
 	panicHandler := logging.PanicHandlerWithTraceFn()
 	go func() {
@@ -307,7 +479,9 @@ func (c *TestCommand) Run(rawArgs []string) int {
 	case <-c.ShutdownCh:
 		// Nice request to be cancelled.
 
+		runner.viewLock.Lock()
 		view.Interrupted()
+		runner.viewLock.Unlock()
 		runner.Stopped = true
 		stop()
 
@@ -316,7 +490,9 @@ func (c *TestCommand) Run(rawArgs []string) int {
 			// The user pressed it again, now we have to get it to stop as
 			// fast as possible.
 
+			runner.viewLock.Lock()
 			view.FatalInterrupt()
+			runner.viewLock.Unlock()
 			runner.Cancelled = true
 			cancel()
 
@@ -335,11 +511,43 @@ func (c *TestCommand) Run(rawArgs []string) int {
 	}
 
 	if runner.Cancelled {
-		// Don't print out the conclusion if the test was cancelled.
+		// Don't write any reports or print out the conclusion if the test was
+		// cancelled, since the runner might still be updating the suite.
 		return 1
 	}
 
-	view.Conclusion(&suite)
+	if args.JUnitXMLPath != "" {
+		// We write the report even if the tests were stopped, so that it
+		// records the run blocks that did complete.
+		if err := c.writeJUnitXML(args.JUnitXMLPath, &suite); err != nil {
+			view.Diagnostics(nil, nil, tfdiags.Diagnostics{}.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to write JUnit XML report",
+				fmt.Sprintf("Could not write the test results to %s: %s.", args.JUnitXMLPath, err),
+			)))
+			return 1
+		}
+	}
+
+	if !runner.DestroyKeptState {
+		view.Conclusion(&suite)
+	}
+
+	if runner.Coverage != nil {
+		if args.Coverage {
+			view.Coverage(runner.Coverage)
+		}
+		if args.CoveragePath != "" {
+			if err := writeCoverage(args.CoveragePath, runner.Coverage); err != nil {
+				view.Diagnostics(nil, nil, tfdiags.Diagnostics{}.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Failed to write coverage report",
+					fmt.Sprintf("Could not write the coverage report to %s: %s.", args.CoveragePath, err),
+				)))
+				return 1
+			}
+		}
+	}
 
 	if suite.Status != moduletest.Pass {
 		return 1
@@ -347,6 +555,25 @@ func (c *TestCommand) Run(rawArgs []string) int {
 	return 0
 }
 
+// writeJUnitXML writes a JUnit XML report of the results in the given
+// suite to the given path.
+func (c *TestCommand) writeJUnitXML(path string, suite *moduletest.Suite) error {
+	src, err := junit.Marshal(suite, c.configSources())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, src, 0644)
+}
+
+// writeCoverage writes a JSON report of the given coverage to the given path.
+func writeCoverage(path string, report *coverage.Report) error {
+	src, err := coverage.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, src, 0644)
+}
+
 // test runner
 
 type TestSuiteRunner struct {
@@ -378,6 +605,38 @@ type TestSuiteRunner struct {
 
 	// Verbose tells the runner to print out plan files during each test run.
 	Verbose bool
+
+	// FileParallelism is the number of test files that can be executed
+	// concurrently.
+	FileParallelism int
+
+	// RecordProviders tells the runner to record the interactions with the
+	// providers for each run block into a fixture file, and ReplayProviders
+	// tells the runner to serve them from the fixture files instead.
+	RecordProviders bool
+	ReplayProviders bool
+
+	// UpdateSnapshots tells the runner to write the plan snapshots of run
+	// blocks that use them, rather than comparing against them.
+	UpdateSnapshots bool
+
+	// Coverage collects which objects in the configurations under test were
+	// exercised by the run blocks, or is nil if coverage isn't being
+	// collected.
+	Coverage *coverage.Report
+
+	// KeepState tells the runner to write the states created while executing
+	// each file to disk, instead of destroying the infrastructure in them.
+	//
+	// DestroyKeptState tells the runner to destroy the infrastructure in the
+	// states kept by a previous execution, instead of executing the files.
+	KeepState        bool
+	DestroyKeptState bool
+
+	// viewLock is held while writing to View when test files are executed
+	// concurrently, so that output written from elsewhere isn't interleaved
+	// with the buffered output of each file.
+	viewLock sync.Mutex
 }
 
 func (runner *TestSuiteRunner) Start(ctx context.Context) {
@@ -388,33 +647,138 @@ func (runner *TestSuiteRunner) Start(ctx context.Context) {
 	sort.Strings(files) // execute the files in alphabetical order
 
 	runner.Suite.Status = moduletest.Pass
+	if runner.FileParallelism > 1 {
+		runner.startParallel(ctx, files)
+		return
+	}
+
 	for _, name := range files {
 		if runner.Cancelled {
 			return
 		}
 
 		file := runner.Suite.Files[name]
+		runner.executeFile(ctx, file, runner.View)
+		runner.Suite.Status = runner.Suite.Status.Merge(file.Status)
+	}
+}
 
-		fileRunner := &TestFileRunner{
-			Suite: runner,
-			States: map[string]*TestFileState{
-				MainStateIdentifier: {
-					Run:   nil,
-					State: states.NewState(),
-				},
-			},
+// startParallel executes up to FileParallelism of the given test files
+// concurrently, starting them in the given order.
+//
+// Files that share a state key manage the same infrastructure, so a file is
+// only started once no other running file shares any of its state keys. The
+// output for each file is buffered and written once the file is complete,
+// so that it isn't interleaved with the output of other files.
+func (runner *TestSuiteRunner) startParallel(ctx context.Context, files []string) {
+	done := make(chan *moduletest.File)
+	inUse := make(map[string]bool)
+	running := 0
+
+	pending := files
+	for len(pending) > 0 || running > 0 {
+		for i := 0; i < len(pending) && running < runner.FileParallelism && !runner.Cancelled; {
+			file := runner.Suite.Files[pending[i]]
+			keys := testFileStateKeys(file)
+			if slices.ContainsFunc(keys, func(key string) bool { return inUse[key] }) {
+				i++
+				continue
+			}
+
+			for _, key := range keys {
+				inUse[key] = true
+			}
+			pending = slices.Delete(pending, i, i+1)
+			running++
+
+			log.Printf("[TRACE] TestSuiteRunner: starting test file %s", file.Name)
+			go func() {
+				defer logging.PanicHandler()
+
+				view := views.NewTestBuffered(runner.View, &runner.viewLock)
+				runner.executeFile(ctx, file, view)
+				view.Flush()
+				done <- file
+			}()
+		}
+
+		if running == 0 {
+			// We can only get here if the remaining files were not started
+			// because the tests were cancelled.
+			return
+		}
+
+		file := <-done
+		running--
+		for _, key := range testFileStateKeys(file) {
+			delete(inUse, key)
 		}
+		runner.Suite.Status = runner.Suite.Status.Merge(file.Status)
+	}
+}
 
+// executeFile executes all the run blocks in the given file, and then cleans
+// up any infrastructure they created, writing output to the given view.
+func (runner *TestSuiteRunner) executeFile(ctx context.Context, file *moduletest.File, view views.Test) {
+	fileRunner := &TestFileRunner{
+		Suite: runner,
+		View:  view,
+		States: map[string]*TestFileState{
+			MainStateIdentifier: {
+				Run:   nil,
+				State: states.NewState(),
+			},
+		},
+		Fixtures: make(map[*moduletest.Run]*recording.Fixture),
+	}
+
+	start := time.Now()
+	switch {
+	case runner.DestroyKeptState:
+		fileRunner.DestroyKeptState(ctx, file)
+	case runner.KeepState:
+		fileRunner.ExecuteTestFile(ctx, file)
+		fileRunner.KeepState(ctx, file)
+	default:
 		fileRunner.ExecuteTestFile(ctx, file)
 		fileRunner.Cleanup(ctx, file)
-		runner.Suite.Status = runner.Suite.Status.Merge(file.Status)
 	}
+	file.Duration = time.Since(start)
+
+	if runner.RecordProviders {
+		fileRunner.SaveFixtures(file)
+	}
+}
+
+// testFileStateKeys returns the keys of the states that the run blocks in the
+// given file use, which identify the configuration that each run block
+// applies.
+func testFileStateKeys(file *moduletest.File) []string {
+	var keys []string
+	for _, run := range file.Runs {
+		key := MainStateIdentifier
+		if run.Config.ConfigUnderTest != nil {
+			key = run.Config.Module.Source.String()
+		}
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
 }
 
 type TestFileRunner struct {
 	Suite *TestSuiteRunner
 
+	// View is where the output for this file is written, which might be a
+	// buffer if several files are executed concurrently.
+	View views.Test
+
 	States map[string]*TestFileState
+
+	// Fixtures holds the recorded provider interactions for each run block
+	// that has been executed, when recording or replaying them.
+	Fixtures map[*moduletest.Run]*recording.Fixture
 }
 
 type TestFileState struct {
@@ -480,7 +844,17 @@ func (runner *TestFileRunner) ExecuteTestFile(ctx context.Context, file *modulet
 			}
 		}
 
+		fixtureDiags := runner.prepareFixture(run, file)
+		run.Diagnostics = run.Diagnostics.Append(fixtureDiags)
+		if fixtureDiags.HasErrors() {
+			run.Status = moduletest.Error
+			file.Status = file.Status.Merge(run.Status)
+			continue
+		}
+
+		start := time.Now()
 		state, updatedState := runner.ExecuteTestRun(ctx, run, file, runner.States[key].State, config)
+		run.Duration = time.Since(start)
 		if updatedState {
 			var err error
 
@@ -513,9 +887,9 @@ func (runner *TestFileRunner) ExecuteTestFile(ctx context.Context, file *modulet
 		file.Status = file.Status.Merge(run.Status)
 	}
 
-	runner.Suite.View.File(file)
+	runner.View.File(file)
 	for _, run := range file.Runs {
-		runner.Suite.View.Run(run, file)
+		runner.View.Run(run, file)
 	}
 }
 
@@ -573,14 +947,19 @@ func (runner *TestFileRunner) ExecuteTestRun(ctx context.Context, run *moduletes
 	planCtx, plan, planDiags := runner.plan(ctx, config, state, run, file)
 	if run.Config.Command == configs.PlanTestCommand {
 		expectedFailures, sourceRanges := run.BuildExpectedFailuresAndSourceMaps()
+		expectedDiagnostics := run.BuildExpectedDiagnostics()
 		// Then we want to assess our conditions and diagnostics differently.
+		planDiags = run.ValidateExpectedDiagnostics(expectedDiagnostics, expectedFailures, planDiags)
 		planDiags = run.ValidateExpectedFailures(expectedFailures, sourceRanges, planDiags)
+		planDiags = planDiags.Append(run.MissingExpectedDiagnostics(expectedDiagnostics))
 		run.Diagnostics = run.Diagnostics.Append(planDiags)
 		if planDiags.HasErrors() {
 			run.Status = moduletest.Error
 			return state, false
 		}
 
+		runner.compareSnapshot(ctx, planCtx, config, plan, run, file)
+
 		variables, resetVariables, variableDiags := runner.prepareInputVariablesForAssertions(config, run, file, runner.Suite.GlobalVariables)
 		defer resetVariables()
 
@@ -616,12 +995,20 @@ func (runner *TestFileRunner) ExecuteTestRun(ctx context.Context, run *moduletes
 		}
 
 		planCtx.TestContext(config, plan.PlannedState, plan, variables).EvaluateAgainstPlan(run)
+		runner.recordCoverage(config, run, plan, nil)
 		return state, false
 	}
 
 	expectedFailures, sourceRanges := run.BuildExpectedFailuresAndSourceMaps()
+	expectedDiagnostics := run.BuildExpectedDiagnostics()
 
 	planDiags = checkProblematicPlanErrors(expectedFailures, planDiags)
+	if !planDiags.HasErrors() {
+		// Any errors during the planning will fail the test regardless, so
+		// we only remove the expected warnings here. This also means the
+		// errors are reported in full rather than as missing diagnostics.
+		planDiags = run.ValidateExpectedDiagnostics(expectedDiagnostics, expectedFailures, planDiags)
+	}
 
 	// Otherwise any error during the planning prevents our apply from
 	// continuing which is an error.
@@ -631,6 +1018,8 @@ func (runner *TestFileRunner) ExecuteTestRun(ctx context.Context, run *moduletes
 		return state, false
 	}
 
+	runner.compareSnapshot(ctx, planCtx, config, plan, run, file)
+
 	// Since we're carrying on an executing the apply operation as well, we're
 	// just going to do some post processing of the diagnostics. We remove the
 	// warnings generated from check blocks, as the apply operation will either
@@ -648,7 +1037,9 @@ func (runner *TestFileRunner) ExecuteTestRun(ctx context.Context, run *moduletes
 	applyCtx, updated, applyDiags := runner.apply(ctx, plan, state, config, run, file)
 
 	// Remove expected diagnostics, and add diagnostics in case anything that should have failed didn't.
+	applyDiags = run.ValidateExpectedDiagnostics(expectedDiagnostics, expectedFailures, applyDiags)
 	applyDiags = run.ValidateExpectedFailures(expectedFailures, sourceRanges, applyDiags)
+	applyDiags = applyDiags.Append(run.MissingExpectedDiagnostics(expectedDiagnostics))
 
 	run.Diagnostics = run.Diagnostics.Append(applyDiags)
 	if applyDiags.HasErrors() {
@@ -693,15 +1084,138 @@ func (runner *TestFileRunner) ExecuteTestRun(ctx context.Context, run *moduletes
 	}
 
 	applyCtx.TestContext(config, updated, plan, variables).EvaluateAgainstState(run)
+	runner.recordCoverage(config, run, plan, updated)
 	return updated, true
 }
 
+// compareSnapshot compares the given plan against the stored snapshot for the
+// run block, if the run block uses one, or writes the snapshot if the suite is
+// updating snapshots. A plan that doesn't match the snapshot fails the run
+// block.
+func (runner *TestFileRunner) compareSnapshot(ctx context.Context, planCtx *tofu.Context, config *configs.Config, plan *plans.Plan, run *moduletest.Run, file *moduletest.File) {
+	if !run.Config.Snapshot {
+		return
+	}
+
+	schemas, diags := planCtx.Schemas(ctx, config, plan.PlannedState)
+	run.Diagnostics = run.Diagnostics.Append(diags)
+	if diags.HasErrors() {
+		run.Status = run.Status.Merge(moduletest.Error)
+		return
+	}
+
+	filename := moduletest.RunFilename(file.Name, "snapshots", run.Name)
+	actual, err := snapshot.New(plan, schemas)
+	if err != nil {
+		run.Status = run.Status.Merge(moduletest.Error)
+		run.Diagnostics = run.Diagnostics.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to create plan snapshot",
+			Detail:   fmt.Sprintf("OpenTofu could not create a snapshot of the plan for this run block: %s.", err),
+			Subject:  run.Config.SnapshotDeclRange.Ptr(),
+		})
+		return
+	}
+
+	if runner.Suite.UpdateSnapshots {
+		if err := actual.Save(filename); err != nil {
+			run.Status = run.Status.Merge(moduletest.Error)
+			run.Diagnostics = run.Diagnostics.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to write plan snapshot",
+				Detail:   fmt.Sprintf("OpenTofu could not write the plan snapshot to %s: %s.", filename, err),
+				Subject:  run.Config.SnapshotDeclRange.Ptr(),
+			})
+		}
+		return
+	}
+
+	stored, err := snapshot.Load(filename)
+	if err != nil {
+		run.Status = run.Status.Merge(moduletest.Error)
+		if os.IsNotExist(err) {
+			run.Diagnostics = run.Diagnostics.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Missing plan snapshot",
+				Detail:   fmt.Sprintf("No plan snapshot has been stored for this run block. Create it by running the tests with -update-snapshots, which will write %s.", filename),
+				Subject:  run.Config.SnapshotDeclRange.Ptr(),
+			})
+			return
+		}
+		run.Diagnostics = run.Diagnostics.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to load plan snapshot",
+			Detail:   fmt.Sprintf("OpenTofu could not load the plan snapshot from %s: %s.", filename, err),
+			Subject:  run.Config.SnapshotDeclRange.Ptr(),
+		})
+		return
+	}
+
+	diff, err := snapshot.Diff(stored, actual)
+	if err != nil {
+		run.Status = run.Status.Merge(moduletest.Error)
+		run.Diagnostics = run.Diagnostics.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to compare plan snapshot",
+			Detail:   fmt.Sprintf("OpenTofu could not compare the plan against the snapshot in %s: %s.", filename, err),
+			Subject:  run.Config.SnapshotDeclRange.Ptr(),
+		})
+		return
+	}
+	if diff != "" {
+		run.Status = run.Status.Merge(moduletest.Fail)
+		run.Diagnostics = run.Diagnostics.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Plan does not match snapshot",
+			Detail:   fmt.Sprintf("The plan for this run block differs from the snapshot in %s:\n\n%s\n\nIf the new plan is correct, update the snapshot by running the tests with -update-snapshots.", filename, diff),
+			Subject:  run.Config.SnapshotDeclRange.Ptr(),
+		})
+	}
+}
+
+// recordCoverage records what the given run block did with the objects in the
+// given configuration, if the suite is collecting coverage. The state is nil
+// for run blocks that only create a plan.
+func (runner *TestFileRunner) recordCoverage(config *configs.Config, run *moduletest.Run, plan *plans.Plan, state *states.State) {
+	report := runner.Suite.Coverage
+	if report == nil {
+		return
+	}
+
+	for _, change := range plan.Changes.Resources {
+		report.Planned(config, change.Addr.ConfigResource())
+	}
+	if plan.PlannedState != nil {
+		// Data sources that were read during the plan don't have a change,
+		// but they are in the planned state.
+		for _, module := range plan.PlannedState.Modules {
+			for _, res := range module.Resources {
+				if len(res.Instances) == 0 {
+					// Resources with no instances, such as those with a
+					// count of zero, were not actually planned.
+					continue
+				}
+				report.Planned(config, res.Addr.Config())
+			}
+		}
+	}
+	report.Checked(config, plan.Checks)
+
+	if state != nil {
+		report.Applied(config, state)
+		report.Checked(config, state.CheckResults)
+	}
+
+	report.Asserted(config, run.Config.CheckRules)
+	report.ExpectedFailures(config, run.Config.ExpectFailures)
+}
+
 func (runner *TestFileRunner) validate(ctx context.Context, config *configs.Config, run *moduletest.Run, file *moduletest.File) tfdiags.Diagnostics {
 	log.Printf("[TRACE] TestFileRunner: called validate for %s/%s", file.Name, run.Name)
 
 	var diags tfdiags.Diagnostics
 
-	tfCtx, ctxDiags := tofu.NewContext(runner.Suite.Opts)
+	tfCtx, ctxDiags := tofu.NewContext(runner.contextOpts(run))
 	diags = diags.Append(ctxDiags)
 	if ctxDiags.HasErrors() {
 		return diags
@@ -759,7 +1273,7 @@ func (runner *TestFileRunner) destroy(ctx context.Context, config *configs.Confi
 		SetVariables: variables,
 	}
 
-	tfCtx, ctxDiags := tofu.NewContext(runner.Suite.Opts)
+	tfCtx, ctxDiags := tofu.NewContext(runner.contextOpts(run))
 	diags = diags.Append(ctxDiags)
 	if ctxDiags.HasErrors() {
 		return state, diags
@@ -836,7 +1350,7 @@ func (runner *TestFileRunner) plan(ctx context.Context, config *configs.Config,
 		ExternalReferences: references,
 	}
 
-	tfCtx, ctxDiags := tofu.NewContext(runner.Suite.Opts)
+	tfCtx, ctxDiags := tofu.NewContext(runner.contextOpts(run))
 	diags = diags.Append(ctxDiags)
 	if ctxDiags.HasErrors() {
 		return nil, nil, diags
@@ -891,7 +1405,7 @@ func (runner *TestFileRunner) apply(ctx context.Context, plan *plans.Plan, state
 		created = append(created, change)
 	}
 
-	tfCtx, ctxDiags := tofu.NewContext(runner.Suite.Opts)
+	tfCtx, ctxDiags := tofu.NewContext(runner.contextOpts(run))
 	diags = diags.Append(ctxDiags)
 	if ctxDiags.HasErrors() {
 		return nil, state, diags
@@ -922,6 +1436,86 @@ func (runner *TestFileRunner) apply(ctx context.Context, plan *plans.Plan, state
 	return tfCtx, updated, diags
 }
 
+// prepareFixture loads or creates the fixture for the given run block, if the
+// provider interactions are being recorded or replayed.
+func (runner *TestFileRunner) prepareFixture(run *moduletest.Run, file *moduletest.File) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	filename := moduletest.RunFilename(file.Name, "fixtures", run.Name)
+	switch {
+	case runner.Suite.RecordProviders:
+		runner.Fixtures[run] = recording.NewFixture(filename)
+	case runner.Suite.ReplayProviders:
+		fixture, err := recording.LoadFixture(filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Missing provider fixture",
+					Detail:   fmt.Sprintf("No provider interactions have been recorded for this run block, so they can't be replayed. Record them by running the tests with -record-providers, which will create %s.", filename),
+					Subject:  run.Config.DeclRange.Ptr(),
+				})
+				return diags
+			}
+
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to load provider fixture",
+				Detail:   fmt.Sprintf("The recorded provider interactions for this run block could not be loaded: %s.", err),
+				Subject:  run.Config.DeclRange.Ptr(),
+			})
+			return diags
+		}
+		runner.Fixtures[run] = fixture
+	}
+	return diags
+}
+
+// contextOpts returns the options for the contexts that execute the given run
+// block, with the providers wrapped to record or replay their interactions if
+// requested.
+func (runner *TestFileRunner) contextOpts(run *moduletest.Run) *tofu.ContextOpts {
+	fixture, ok := runner.Fixtures[run]
+	if !ok {
+		return runner.Suite.Opts
+	}
+
+	opts := *runner.Suite.Opts
+	opts.Providers = make(map[addrs.Provider]providers.Factory, len(runner.Suite.Opts.Providers))
+	for addr, factory := range runner.Suite.Opts.Providers {
+		if runner.Suite.RecordProviders {
+			opts.Providers[addr] = recording.Record(addr, factory, fixture)
+		} else {
+			opts.Providers[addr] = recording.Replay(addr, factory, fixture)
+		}
+	}
+	return &opts
+}
+
+// SaveFixtures writes the recorded provider interactions for each run block
+// in the given file to its fixture file.
+func (runner *TestFileRunner) SaveFixtures(file *moduletest.File) {
+	var diags tfdiags.Diagnostics
+	for _, run := range file.Runs {
+		fixture, ok := runner.Fixtures[run]
+		if !ok {
+			continue
+		}
+
+		if err := fixture.Save(); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to save provider fixture",
+				fmt.Sprintf("The recorded provider interactions for %s could not be written to %s: %s.", run.Name, fixture.Filename(), err)))
+		}
+	}
+
+	if diags.HasErrors() {
+		file.Status = file.Status.Merge(moduletest.Error)
+		runner.View.Diagnostics(nil, file, diags)
+	}
+}
+
 func (runner *TestFileRunner) wait(ctx *tofu.Context, runningCtx context.Context, run *moduletest.Run, file *moduletest.File, created []*plans.ResourceInstanceChangeSrc) (diags tfdiags.Diagnostics, cancelled bool) {
 	var identifier string
 	if file == nil {
@@ -949,7 +1543,7 @@ func (runner *TestFileRunner) wait(ctx *tofu.Context, runningCtx context.Context
 			}
 			states[module.Run] = module.State
 		}
-		runner.Suite.View.FatalInterruptSummary(run, file, states, created)
+		runner.View.FatalInterruptSummary(run, file, states, created)
 
 		cancelled = true
 		go ctx.Stop()
@@ -990,16 +1584,20 @@ func (runner *TestFileRunner) wait(ctx *tofu.Context, runningCtx context.Context
 	return diags, cancelled
 }
 
-func (runner *TestFileRunner) Cleanup(ctx context.Context, file *moduletest.File) {
+// Cleanup destroys the infrastructure in the states created while executing
+// the given file. It returns the states that still contain resources
+// afterwards, including any it didn't get to, keyed in the same way as States.
+func (runner *TestFileRunner) Cleanup(ctx context.Context, file *moduletest.File) map[string]*TestFileState {
 	log.Printf("[TRACE] TestStateManager: cleaning up state for %s", file.Name)
 
+	remaining := make(map[string]*TestFileState)
 	if runner.Suite.Cancelled {
 		// Don't try and clean anything up if the execution has been cancelled.
 		log.Printf("[DEBUG] TestStateManager: skipping state cleanup for %s due to cancellation", file.Name)
-		return
+		return remaining
 	}
 
-	var states []*TestFileState
+	var keys []string
 	for key, state := range runner.States {
 		if state.Run == nil {
 			if state.State.Empty() {
@@ -1022,28 +1620,32 @@ func (runner *TestFileRunner) Cleanup(ctx context.Context, file *moduletest.File
 
 			var diags tfdiags.Diagnostics
 			diags = diags.Append(tfdiags.Sourceless(tfdiags.Error, "Inconsistent state", fmt.Sprintf("Found inconsistent state while cleaning up %s. This is a bug in OpenTofu - please report it", file.Name)))
-			runner.Suite.View.DestroySummary(diags, nil, file, state.State)
+			runner.View.DestroySummary(diags, nil, file, state.State)
 			continue
 		}
 
-		states = append(states, state)
+		keys = append(keys, key)
+		if state.State.HasManagedResourceInstanceObjects() {
+			remaining[key] = state
+		}
 	}
 
-	slices.SortFunc(states, func(a, b *TestFileState) int {
+	slices.SortFunc(keys, func(a, b string) int {
 		// We want to clean up later run blocks first. So, we'll sort this in
 		// reverse according to index. This means larger indices first.
-		return b.Run.Index - a.Run.Index
+		return runner.States[b].Run.Index - runner.States[a].Run.Index
 	})
 
 	// Clean up all the states (for main and custom modules) in reverse order.
-	for _, state := range states {
+	for _, key := range keys {
+		state := runner.States[key]
 		log.Printf("[DEBUG] TestStateManager: cleaning up state for %s/%s", file.Name, state.Run.Name)
 
 		if runner.Suite.Cancelled {
 			// In case the cancellation came while a previous state was being
 			// destroyed.
 			log.Printf("[DEBUG] TestStateManager: skipping state cleanup for %s/%s due to cancellation", file.Name, state.Run.Name)
-			return
+			return remaining
 		}
 
 		var diags tfdiags.Diagnostics
@@ -1058,7 +1660,7 @@ func (runner *TestFileRunner) Cleanup(ctx context.Context, file *moduletest.File
 
 		evalCtx, evalDiags := buildEvalContextForProviderConfigTransform(runner.States, state.Run, file, runConfig, runner.Suite.GlobalVariables)
 		if evalDiags.HasErrors() {
-			return
+			return remaining
 		}
 
 		reset, configDiags := runConfig.TransformForTest(state.Run.Config, file.Config, evalCtx)
@@ -1070,13 +1672,141 @@ func (runner *TestFileRunner) Cleanup(ctx context.Context, file *moduletest.File
 			updated, destroyDiags = runner.destroy(ctx, runConfig, state.State, state.Run, file)
 			diags = diags.Append(destroyDiags)
 		}
-		runner.Suite.View.DestroySummary(diags, state.Run, file, updated)
+		runner.View.DestroySummary(diags, state.Run, file, updated)
 
 		if updated.HasManagedResourceInstanceObjects() {
-			views.SaveErroredTestStateFile(updated, state.Run, file, runner.Suite.View)
+			views.SaveErroredTestStateFile(updated, state.Run, file, runner.View)
+			remaining[key] = &TestFileState{
+				Run:   state.Run,
+				State: updated,
+			}
+		} else {
+			delete(remaining, key)
 		}
 		reset()
 	}
+	return remaining
+}
+
+// KeepState writes the states created while executing the given file to
+// disk, instead of destroying the infrastructure in them. If the states can't
+// be written, the infrastructure is destroyed as usual.
+func (runner *TestFileRunner) KeepState(ctx context.Context, file *moduletest.File) {
+	log.Printf("[TRACE] TestFileRunner: keeping state for %s", file.Name)
+
+	var kept []*keptstate.State
+	for key, state := range runner.States {
+		if state.Run == nil {
+			// This state was never updated by a run block, so there is
+			// nothing in it to keep.
+			continue
+		}
+		kept = append(kept, &keptstate.State{
+			Key:   key,
+			Run:   state.Run.Name,
+			State: state.State,
+		})
+	}
+	if len(kept) == 0 {
+		return
+	}
+
+	var diags tfdiags.Diagnostics
+	if err := keptstate.Write(keptstate.DefaultDir, file.Name, kept); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to keep test state",
+			fmt.Sprintf("Could not write the states created while executing %s to %s, so the infrastructure will be destroyed instead: %s.", file.Name, keptstate.DefaultDir, err)))
+		runner.View.Diagnostics(nil, file, diags)
+		file.Status = file.Status.Merge(moduletest.Error)
+		runner.Cleanup(ctx, file)
+		return
+	}
+
+	var paths strings.Builder
+	for _, state := range kept {
+		fmt.Fprintf(&paths, "\n  - %s (last updated by run %q)", state.Path, state.Run)
+	}
+	diags = diags.Append(tfdiags.Sourceless(
+		tfdiags.Warning,
+		"Test state kept",
+		fmt.Sprintf("The infrastructure created while executing %s has not been destroyed. Its state has been written to:%s\n\nDestroy the infrastructure with \"tofu test -destroy-kept-state\" once you have finished inspecting it.", file.Name, paths.String())))
+	runner.View.Diagnostics(nil, file, diags)
+}
+
+// DestroyKeptState destroys the infrastructure in the states kept by a
+// previous execution of the given file, and then removes the kept states. Any
+// states that still contain resources are kept so the destroy can be retried.
+func (runner *TestFileRunner) DestroyKeptState(ctx context.Context, file *moduletest.File) {
+	log.Printf("[TRACE] TestFileRunner: destroying kept state for %s", file.Name)
+
+	var diags tfdiags.Diagnostics
+	kept, err := keptstate.Read(keptstate.DefaultDir, file.Name)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read kept test state",
+			fmt.Sprintf("Could not read the states kept for %s: %s.", file.Name, err)))
+		runner.View.Diagnostics(nil, file, diags)
+		file.Status = moduletest.Error
+		return
+	}
+
+	for _, state := range kept {
+		ix := slices.IndexFunc(file.Runs, func(run *moduletest.Run) bool {
+			return run.Name == state.Run
+		})
+		if ix < 0 {
+			// We need the run block to know how to configure the
+			// infrastructure for destruction, so we can't go any further.
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Unknown run block",
+				fmt.Sprintf("The state kept at %s was last updated by the run block %s, which could not be found in %s. The kept states for this file must be destroyed manually.", state.Path, state.Run, file.Name)))
+			runner.View.Diagnostics(nil, file, diags)
+			file.Status = moduletest.Error
+			return
+		}
+		runner.States[state.Key] = &TestFileState{
+			Run:   file.Runs[ix],
+			State: state.State,
+		}
+	}
+
+	remaining := runner.Cleanup(ctx, file)
+	if runner.Suite.Cancelled {
+		// The kept states are left exactly as they were.
+		return
+	}
+
+	if len(remaining) == 0 {
+		file.Status = moduletest.Pass
+		if err := keptstate.Remove(keptstate.DefaultDir, file.Name); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Failed to remove kept test state",
+				fmt.Sprintf("The infrastructure kept for %s was destroyed, but its states could not be removed from %s: %s.", file.Name, keptstate.DefaultDir, err)))
+			runner.View.Diagnostics(nil, file, diags)
+		}
+		return
+	}
+
+	file.Status = moduletest.Error
+	var leftover []*keptstate.State
+	for key, state := range remaining {
+		leftover = append(leftover, &keptstate.State{
+			Key:   key,
+			Run:   state.Run.Name,
+			State: state.State,
+		})
+	}
+	if err := keptstate.Write(keptstate.DefaultDir, file.Name, leftover); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to update kept test state",
+			fmt.Sprintf("Could not write the states that still contain resources for %s to %s: %s.", file.Name, keptstate.DefaultDir, err)))
+		runner.View.Diagnostics(nil, file, diags)
+	}
 }
 
 // helper functions
@@ -6,7 +6,9 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"path"
 	"path/filepath"
 	"strings"
@@ -19,7 +21,9 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	testing_command "github.com/opentofu/opentofu/internal/command/testing"
 	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/moduletest"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/terminal"
 )
@@ -345,6 +349,517 @@ func TestTest_Interrupt(t *testing.T) {
 	}
 }
 
+func TestTest_JUnitXML(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "simple_fail")), td)
+	t.Chdir(td)
+
+	provider := testing_command.NewProvider(nil)
+	view, done := testView(t)
+
+	c := &TestCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(provider.Provider),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-junit-xml=results.xml", "-no-color"})
+	done(t)
+	if code != 1 {
+		t.Errorf("expected status code 1 but got %d", code)
+	}
+
+	report, err := os.ReadFile("results.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<testsuites tests="1" failures="1" errors="0" skipped="0"`,
+		`<testsuite name="main.tftest.hcl" tests="1" failures="1"`,
+		`<testcase name="validate_test_resource" classname="main.tftest.hcl"`,
+		`<failure message="Test assertion failed">`,
+		`invalid value`,
+	} {
+		if !strings.Contains(string(report), want) {
+			t.Errorf("report does not include %q\n%s", want, report)
+		}
+	}
+}
+
+func TestTest_JUnitXMLInterrupt(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "with_interrupt")), td)
+	t.Chdir(td)
+
+	provider := testing_command.NewProvider(nil)
+	view, done := testView(t)
+
+	interrupt := make(chan struct{})
+	provider.Interrupt = interrupt
+
+	c := &TestCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(provider.Provider),
+			View:             view,
+			ShutdownCh:       interrupt,
+		},
+	}
+
+	c.Run([]string{"-junit-xml=results.xml"})
+	done(t)
+
+	// The report is still written when the tests are interrupted, and the
+	// run blocks that didn't execute are reported as skipped.
+	report, err := os.ReadFile("results.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<testcase name="primary" classname="main.tftest.hcl"`,
+		`<testcase name="tertiary" classname="main.tftest.hcl" time="0.000">
+      <skipped></skipped>`,
+	} {
+		if !strings.Contains(string(report), want) {
+			t.Errorf("report does not include %q\n%s", want, report)
+		}
+	}
+}
+
+func TestTest_FileParallelism(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "parallel_files")), td)
+	t.Chdir(td)
+
+	provider := testing_command.NewProvider(nil)
+
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"test": {"1.0.0"},
+	})
+	defer close()
+
+	streams, done := terminal.StreamsForTesting(t)
+	view := views.NewView(streams)
+	ui := new(cli.MockUi)
+
+	meta := Meta{
+		testingOverrides: metaOverridesForProvider(provider.Provider),
+		Ui:               ui,
+		View:             view,
+		Streams:          streams,
+		ProviderSource:   providerSource,
+	}
+
+	init := &InitCommand{
+		Meta: meta,
+	}
+
+	if code := init.Run(nil); code != 0 {
+		t.Fatalf("expected status code 0 but got %d: %s", code, ui.ErrorWriter)
+	}
+
+	c := &TestCommand{
+		Meta: meta,
+	}
+
+	code := c.Run([]string{"-file-parallelism=4", "-no-color"})
+	output := done(t)
+	if code != 0 {
+		t.Errorf("expected status code 0 but got %d\n%s", code, output.All())
+	}
+
+	// The files can complete in any order, but the output for each file must
+	// not be interleaved with the output of any other file.
+	stdout := output.Stdout()
+	for _, want := range []string{
+		"a.tftest.hcl... pass\n  run \"first\"... pass\n  run \"second\"... pass\n",
+		"b.tftest.hcl... pass\n  run \"first\"... pass\n  run \"second\"... pass\n",
+		"main.tftest.hcl... pass\n  run \"main\"... pass\n",
+		"shared.tftest.hcl... pass\n  run \"shared\"... pass\n",
+		"Success! 6 passed, 0 failed.",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output does not include %q\n%s", want, stdout)
+		}
+	}
+
+	if provider.ResourceCount() > 0 {
+		t.Errorf("should have deleted all resources on completion but left %v", provider.ResourceString())
+	}
+}
+
+func TestTest_Coverage(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "coverage")), td)
+	t.Chdir(td)
+
+	provider := testing_command.NewProvider(nil)
+	view, done := testView(t)
+
+	c := &TestCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(provider.Provider),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-coverage", "-coverage-out=coverage.json", "-no-color"})
+	output := done(t)
+	if code != 0 {
+		t.Errorf("expected status code 0 but got %d\n%s", code, output.All())
+	}
+
+	expected := `main.tftest.hcl... pass
+  run "validate_value"... pass
+
+Success! 1 passed, 0 failed.
+
+Coverage: 2 of 4 objects exercised (50%).
+
+Objects not exercised by any run block:
+  - test_resource.optional (main.tf:26,1-36)
+  - output.unused (main.tf:41,1-16)
+
+Rules not evaluated by any run block:
+  - precondition 0 of test_resource.optional (main.tf:30,5-17)
+`
+	if diff := cmp.Diff(expected, output.Stdout()); len(diff) > 0 {
+		t.Errorf("expected:\n%s\nactual:\n%s\ndiff:\n%s", expected, output.Stdout(), diff)
+	}
+
+	src, err := os.ReadFile("coverage.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Summary struct {
+			Objects          int `json:"objects"`
+			ExercisedObjects int `json:"exercised_objects"`
+		} `json:"summary"`
+		Files map[string][]struct {
+			Address string `json:"address"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(src, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Summary.Objects != 4 || report.Summary.ExercisedObjects != 2 {
+		t.Errorf("wrong summary %+v", report.Summary)
+	}
+	if len(report.Files["main.tf"]) != 5 {
+		t.Errorf("wrong objects %+v", report.Files)
+	}
+
+	if provider.ResourceCount() > 0 {
+		t.Errorf("should have deleted all resources on completion but left %v", provider.ResourceString())
+	}
+}
+
+func TestTest_ForEachRuns(t *testing.T) {
+	tcs := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"all": {
+			expected: `main.tftest.hcl... pass
+  run "cases[large]"... pass
+  run "cases[small]"... pass
+  run "rows[0]"... pass
+  run "rows[1]"... pass
+
+Success! 4 passed, 0 failed.
+`,
+		},
+		"single run": {
+			args: []string{"-filter=main.tftest.hcl:cases[small]"},
+			expected: `main.tftest.hcl... pass
+  run "cases[small]"... pass
+
+Success! 1 passed, 0 failed.
+`,
+		},
+		"run block": {
+			args: []string{"-filter=main.tftest.hcl:rows"},
+			expected: `main.tftest.hcl... pass
+  run "rows[0]"... pass
+  run "rows[1]"... pass
+
+Success! 2 passed, 0 failed.
+`,
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			td := t.TempDir()
+			testCopyDir(t, testFixturePath(path.Join("test", "for_each_runs")), td)
+			t.Chdir(td)
+
+			provider := testing_command.NewProvider(nil)
+			view, done := testView(t)
+
+			c := &TestCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(provider.Provider),
+					View:             view,
+				},
+			}
+
+			code := c.Run(append(tc.args, "-no-color"))
+			output := done(t)
+			if code != 0 {
+				t.Errorf("expected status code 0 but got %d\n%s", code, output.All())
+			}
+
+			if diff := cmp.Diff(tc.expected, output.Stdout()); len(diff) > 0 {
+				t.Errorf("expected:\n%s\nactual:\n%s\ndiff:\n%s", tc.expected, output.Stdout(), diff)
+			}
+
+			if provider.ResourceCount() > 0 {
+				t.Errorf("should have deleted all resources on completion but left %v", provider.ResourceString())
+			}
+		})
+	}
+}
+
+func TestTest_RecordReplayProviders(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "simple_pass")), td)
+	t.Chdir(td)
+
+	run := func(t *testing.T, provider *testing_command.TestProvider, args ...string) (int, string) {
+		view, done := testView(t)
+		c := &TestCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(provider.Provider),
+				View:             view,
+			},
+		}
+		code := c.Run(append(args, "-no-color"))
+		return code, done(t).All()
+	}
+
+	// First, we record the provider interactions with a real run.
+	code, output := run(t, testing_command.NewProvider(nil), "-record-providers")
+	if code != 0 {
+		t.Fatalf("expected status code 0 but got %d\n%s", code, output)
+	}
+	if _, err := os.Stat(filepath.Join("fixtures", "main", "validate_test_resource.json")); err != nil {
+		t.Fatalf("fixture file was not written: %s", err)
+	}
+
+	// Then we replay them, without calling the provider.
+	provider := testing_command.NewProvider(nil)
+	code, output = run(t, provider, "-replay-providers")
+	if code != 0 {
+		t.Fatalf("expected status code 0 but got %d\n%s", code, output)
+	}
+	if !strings.Contains(output, "1 passed, 0 failed") {
+		t.Errorf("output didn't contain expected string:\n\n%s", output)
+	}
+	if provider.Provider.PlanResourceChangeCalled || provider.Provider.ApplyResourceChangeCalled {
+		t.Errorf("the provider should not have been called while replaying")
+	}
+
+	// If the configuration changes, the requests no longer match.
+	if err := os.WriteFile("main.tf", []byte(`resource "test_resource" "foo" { value = "baz" }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	code, output = run(t, testing_command.NewProvider(nil), "-replay-providers")
+	if code != 1 {
+		t.Errorf("expected status code 1 but got %d", code)
+	}
+	if !strings.Contains(output, "Provider request does not match the recording") {
+		t.Errorf("output didn't contain expected string:\n\n%s", output)
+	}
+}
+
+func TestTest_PlanSnapshots(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "plan_snapshot")), td)
+	t.Chdir(td)
+
+	run := func(t *testing.T, args ...string) (int, string) {
+		provider := testing_command.NewProvider(nil)
+		view, done := testView(t)
+		c := &TestCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(provider.Provider),
+				View:             view,
+			},
+		}
+		code := c.Run(append(args, "-no-color"))
+		return code, done(t).All()
+	}
+
+	// Without any snapshots, the run blocks can't be compared.
+	code, output := run(t)
+	if code != 1 {
+		t.Errorf("expected status code 1 but got %d", code)
+	}
+	if !strings.Contains(output, "Missing plan snapshot") {
+		t.Errorf("output didn't contain expected string:\n\n%s", output)
+	}
+
+	code, output = run(t, "-update-snapshots")
+	if code != 0 {
+		t.Fatalf("expected status code 0 but got %d\n%s", code, output)
+	}
+	for _, name := range []string{"plan", "apply"} {
+		src, err := os.ReadFile(filepath.Join("snapshots", "main", name+".json"))
+		if err != nil {
+			t.Fatalf("snapshot file was not written: %s", err)
+		}
+		if !strings.Contains(string(src), `"id": "(known after apply)"`) {
+			t.Errorf("unknown values were not normalised in the %s snapshot:\n%s", name, src)
+		}
+	}
+
+	code, output = run(t)
+	if code != 0 {
+		t.Fatalf("expected status code 0 but got %d\n%s", code, output)
+	}
+	if !strings.Contains(output, "2 passed, 0 failed") {
+		t.Errorf("output didn't contain expected string:\n\n%s", output)
+	}
+
+	// If the plan changes, the run blocks fail and show what changed.
+	code, output = run(t, "-var=value=baz")
+	if code != 1 {
+		t.Errorf("expected status code 1 but got %d", code)
+	}
+	for _, want := range []string{"Plan does not match snapshot", `"bar" -> "baz"`, "0 passed, 2 failed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output didn't contain %q:\n\n%s", want, output)
+		}
+	}
+}
+
+func TestTest_RunOnly(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "run_only")), td)
+	t.Chdir(td)
+
+	// The same provider is used throughout, so it keeps track of the
+	// resources that are kept between executions.
+	provider := testing_command.NewProvider(nil)
+	run := func(t *testing.T, args ...string) (int, string) {
+		view, done := testView(t)
+		c := &TestCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(provider.Provider),
+				View:             view,
+			},
+		}
+		code := c.Run(append(args, "-no-color"))
+		return code, done(t).All()
+	}
+
+	code, output := run(t, "-run-only=main.tftest.hcl:second")
+	if code != 0 {
+		t.Fatalf("expected status code 0 but got %d\n%s", code, output)
+	}
+	for _, want := range []string{"2 passed, 0 failed", "Test state kept", filepath.Join(".terraform", "test-states", "main.tftest.hcl", "main.tfstate")} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output didn't contain %q:\n\n%s", want, output)
+		}
+	}
+	if provider.ResourceCount() != 1 {
+		t.Errorf("expected the resource to be kept, but found %s", provider.ResourceString())
+	}
+	if _, err := os.Stat(filepath.Join(".terraform", "test-states", "main.tftest.hcl", "main.tfstate")); err != nil {
+		t.Errorf("state file was not written: %s", err)
+	}
+
+	// The file can't be executed again until the kept state is destroyed.
+	code, output = run(t, "-run-only=main.tftest.hcl:second")
+	if code != 1 {
+		t.Errorf("expected status code 1 but got %d", code)
+	}
+	if !strings.Contains(output, "Test state already kept") {
+		t.Errorf("output didn't contain expected string:\n\n%s", output)
+	}
+
+	code, output = run(t, "-destroy-kept-state")
+	if code != 0 {
+		t.Fatalf("expected status code 0 but got %d\n%s", code, output)
+	}
+	if provider.ResourceCount() > 0 {
+		t.Errorf("should have deleted all resources but left %v", provider.ResourceString())
+	}
+	if _, err := os.Stat(filepath.Join(".terraform", "test-states", "main.tftest.hcl")); !os.IsNotExist(err) {
+		t.Errorf("kept state was not removed: %v", err)
+	}
+}
+
+func TestTest_ReplayProvidersMissingFixture(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "simple_pass")), td)
+	t.Chdir(td)
+
+	provider := testing_command.NewProvider(nil)
+	view, done := testView(t)
+
+	c := &TestCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(provider.Provider),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-replay-providers", "-no-color"})
+	output := done(t)
+	if code != 1 {
+		t.Errorf("expected status code 1 but got %d", code)
+	}
+	if !strings.Contains(output.All(), "Missing provider fixture") {
+		t.Errorf("output didn't contain expected string:\n\n%s", output.All())
+	}
+	if provider.ResourceCount() > 0 {
+		t.Errorf("should not have created any resources but left %v", provider.ResourceString())
+	}
+}
+
+func TestTestFileStateKeys(t *testing.T) {
+	main := &configs.TestRun{Name: "main"}
+	module := func(name, source string) *configs.TestRun {
+		return &configs.TestRun{
+			Name:            name,
+			Module:          &configs.TestRunModuleCall{Source: addrs.ModuleSourceLocal(source)},
+			ConfigUnderTest: &configs.Config{},
+		}
+	}
+
+	tcs := map[string]struct {
+		runs []*configs.TestRun
+		want []string
+	}{
+		"main": {
+			runs: []*configs.TestRun{main, main},
+			want: []string{MainStateIdentifier},
+		},
+		"modules": {
+			runs: []*configs.TestRun{module("a", "./a"), module("b", "./b"), module("c", "./a")},
+			want: []string{"./a", "./b"},
+		},
+		"mixed": {
+			runs: []*configs.TestRun{module("a", "./a"), main},
+			want: []string{"./a", MainStateIdentifier},
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			file := &moduletest.File{Name: name}
+			for _, run := range tc.runs {
+				file.Runs = append(file.Runs, &moduletest.Run{Name: run.Name, Config: run})
+			}
+
+			if diff := cmp.Diff(tc.want, testFileStateKeys(file)); diff != "" {
+				t.Errorf("wrong state keys\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestTest_DoubleInterrupt(t *testing.T) {
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath(path.Join("test", "with_double_interrupt")), td)
@@ -883,6 +1398,10 @@ func TestTest_Modules(t *testing.T) {
 		"run_mod_output_in_provider_undefined_ref": {
 			code: 1,
 		},
+		"expect_diagnostics": {
+			expected: "main.tftest.hcl... pass\n  run \"deprecated_variable\"... pass\n  run \"invalid_input\"... pass\n  run \"long_input\"... pass\n\nSuccess! 3 passed, 0 failed.\n",
+			code:     0,
+		},
 	}
 
 	for name, tc := range tcs {
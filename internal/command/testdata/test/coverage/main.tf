@@ -0,0 +1,43 @@
+variable "input" {
+  type = string
+
+  validation {
+    condition     = length(var.input) > 0
+    error_message = "input must not be empty"
+  }
+}
+
+variable "enabled" {
+  type    = bool
+  default = false
+}
+
+resource "test_resource" "foo" {
+  value = var.input
+
+  lifecycle {
+    postcondition {
+      condition     = self.value == var.input
+      error_message = "value must match the input"
+    }
+  }
+}
+
+resource "test_resource" "optional" {
+  count = var.enabled ? 1 : 0
+
+  lifecycle {
+    precondition {
+      condition     = var.enabled
+      error_message = "optional resource must be enabled"
+    }
+  }
+}
+
+output "value" {
+  value = test_resource.foo.value
+}
+
+output "unused" {
+  value = "unused"
+}
@@ -0,0 +1,10 @@
+variables {
+  input = "bar"
+}
+
+run "validate_value" {
+  assert {
+    condition     = output.value == "bar"
+    error_message = "invalid value"
+  }
+}
@@ -0,0 +1,4 @@
+variable "value" {
+  type       = string
+  deprecated = "Use the new_value variable instead."
+}
@@ -0,0 +1,27 @@
+variable "input" {
+  type = string
+
+  validation {
+    condition     = lower(var.input) == var.input
+    error_message = "The input must be lowercase."
+  }
+}
+
+variable "use_deprecated" {
+  type    = bool
+  default = false
+}
+
+module "child" {
+  source = "./child"
+  count  = var.use_deprecated ? 1 : 0
+
+  value = var.input
+}
+
+check "length" {
+  assert {
+    condition     = length(var.input) < 10
+    error_message = "The input is too long."
+  }
+}
@@ -0,0 +1,41 @@
+run "deprecated_variable" {
+  variables {
+    input          = "short"
+    use_deprecated = true
+  }
+
+  expect_diagnostic {
+    severity     = warning
+    summary      = "Variable marked as deprecated by the module author"
+    detail_regex = "Use the new_value variable instead"
+  }
+}
+
+run "invalid_input" {
+  command = plan
+
+  variables {
+    input = "UPPER"
+  }
+
+  expect_failures = [
+    var.input,
+  ]
+
+  expect_diagnostic {
+    object       = var.input
+    summary      = "Invalid value for variable"
+    detail_regex = "^The input must be lowercase\\."
+  }
+}
+
+run "long_input" {
+  variables {
+    input = "much_too_long"
+  }
+
+  expect_diagnostic {
+    object = check.length
+    detail = "The input is too long."
+  }
+}
@@ -0,0 +1,7 @@
+variable "size" {
+  type = number
+}
+
+resource "test_resource" "foo" {
+  value = "size-${var.size}"
+}
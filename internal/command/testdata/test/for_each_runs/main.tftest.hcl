@@ -0,0 +1,21 @@
+run "cases" {
+  for_each = {
+    small = { size = 1, expected = "size-1" }
+    large = { size = 100, expected = "size-100" }
+  }
+
+  assert {
+    condition     = test_resource.foo.value == var.expected
+    error_message = "invalid value"
+  }
+}
+
+run "rows" {
+  command  = plan
+  for_each = csvdecode(file("cases.csv"))
+
+  assert {
+    condition     = test_resource.foo.value == var.expected
+    error_message = "invalid value"
+  }
+}
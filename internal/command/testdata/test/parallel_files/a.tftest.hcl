@@ -0,0 +1,16 @@
+run "first" {
+  module {
+    source = "./a"
+  }
+
+  assert {
+    condition     = test_resource.a.value == "a"
+    error_message = "invalid value"
+  }
+}
+
+run "second" {
+  module {
+    source = "./a"
+  }
+}
@@ -0,0 +1,16 @@
+run "first" {
+  module {
+    source = "./b"
+  }
+
+  assert {
+    condition     = test_resource.b.value == "b"
+    error_message = "invalid value"
+  }
+}
+
+run "second" {
+  module {
+    source = "./b"
+  }
+}
@@ -0,0 +1,3 @@
+resource "test_resource" "b" {
+  value = "b"
+}
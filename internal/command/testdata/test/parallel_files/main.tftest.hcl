@@ -0,0 +1,6 @@
+run "main" {
+  assert {
+    condition     = test_resource.main.value == "main"
+    error_message = "invalid value"
+  }
+}
@@ -0,0 +1,9 @@
+# This file applies the main configuration, just like main.tftest.hcl, so the
+# two files must never be executed at the same time.
+
+run "shared" {
+  assert {
+    condition     = test_resource.main.value == "main"
+    error_message = "invalid value"
+  }
+}
@@ -0,0 +1,12 @@
+variable "value" {
+  type    = string
+  default = "bar"
+}
+
+resource "test_resource" "foo" {
+  value = var.value
+}
+
+output "value" {
+  value = test_resource.foo.value
+}
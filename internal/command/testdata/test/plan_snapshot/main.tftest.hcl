@@ -0,0 +1,8 @@
+run "plan" {
+  command  = plan
+  snapshot = true
+}
+
+run "apply" {
+  snapshot = true
+}
@@ -0,0 +1,7 @@
+variable "value" {
+  type = string
+}
+
+resource "test_resource" "foo" {
+  value = var.value
+}
@@ -0,0 +1,27 @@
+run "first" {
+  variables {
+    value = "first"
+  }
+}
+
+run "second" {
+  variables {
+    value = "second"
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "second"
+    error_message = "bad value"
+  }
+}
+
+run "third" {
+  variables {
+    value = "third"
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "fourth"
+    error_message = "this run block should not be executed"
+  }
+}
@@ -40,6 +40,7 @@ const (
 	MessageTestPlan      MessageType = "test_plan"
 	MessageTestState     MessageType = "test_state"
 	MessageTestSummary   MessageType = "test_summary"
+	MessageTestCoverage  MessageType = "test_coverage"
 	MessageTestCleanup   MessageType = "test_cleanup"
 	MessageTestInterrupt MessageType = "test_interrupt"
 )
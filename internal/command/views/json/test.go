@@ -8,7 +8,11 @@ package json
 import (
 	"strings"
 
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/command/jsonentities"
 	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/moduletest/coverage"
 )
 
 type TestSuiteAbstract map[string][]string
@@ -34,6 +38,26 @@ type TestSuiteSummary struct {
 	Skipped int        `json:"skipped"`
 }
 
+type TestCoverage struct {
+	Objects            int                  `json:"objects"`
+	ExercisedObjects   int                  `json:"exercised_objects"`
+	UnexercisedObjects []TestCoverageObject `json:"unexercised_objects,omitempty"`
+	UnevaluatedRules   []TestCoverageRule   `json:"unevaluated_rules,omitempty"`
+}
+
+type TestCoverageObject struct {
+	Kind    string                       `json:"kind"`
+	Address string                       `json:"address"`
+	Range   jsonentities.DiagnosticRange `json:"range"`
+}
+
+type TestCoverageRule struct {
+	Kind    string                       `json:"kind"`
+	Index   int                          `json:"index"`
+	Address string                       `json:"address"`
+	Range   jsonentities.DiagnosticRange `json:"range"`
+}
+
 type TestFileCleanup struct {
 	FailedResources []TestFailedResource `json:"failed_resources"`
 }
@@ -52,3 +76,42 @@ type TestFatalInterrupt struct {
 func ToTestStatus(status moduletest.Status) TestStatus {
 	return TestStatus(strings.ToLower(status.String()))
 }
+
+// NewTestCoverage summarises the given report, listing only the objects and
+// rules that were not exercised.
+func NewTestCoverage(report *coverage.Report) TestCoverage {
+	summary := report.Summary()
+	ret := TestCoverage{
+		Objects:          summary.Objects,
+		ExercisedObjects: summary.ExercisedObjects,
+	}
+	for _, obj := range report.Objects() {
+		if obj.Kind != coverage.VariableObject && !obj.Exercised() {
+			ret.UnexercisedObjects = append(ret.UnexercisedObjects, TestCoverageObject{
+				Kind:    string(obj.Kind),
+				Address: obj.Address,
+				Range:   testCoverageRange(obj.Range),
+			})
+		}
+		if obj.Checked {
+			continue
+		}
+		for _, rule := range obj.Rules {
+			ret.UnevaluatedRules = append(ret.UnevaluatedRules, TestCoverageRule{
+				Kind:    string(rule.Kind),
+				Index:   rule.Index,
+				Address: obj.Address,
+				Range:   testCoverageRange(rule.Range),
+			})
+		}
+	}
+	return ret
+}
+
+func testCoverageRange(rng hcl.Range) jsonentities.DiagnosticRange {
+	return jsonentities.DiagnosticRange{
+		Filename: rng.Filename,
+		Start:    jsonentities.Pos{Line: rng.Start.Line, Column: rng.Start.Column, Byte: rng.Start.Byte},
+		End:      jsonentities.Pos{Line: rng.End.Line, Column: rng.End.Column, Byte: rng.End.Byte},
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/moduletest/coverage"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/statefile"
@@ -42,6 +43,11 @@ type Test interface {
 	// completed status.
 	Conclusion(suite *moduletest.Suite)
 
+	// Coverage prints out a summary of which objects in the configurations
+	// under test were exercised by the run blocks, and lists the objects and
+	// rules that were not.
+	Coverage(report *coverage.Report)
+
 	// File prints out the summary for an entire test file.
 	File(file *moduletest.File)
 
@@ -135,6 +141,42 @@ func (t *TestHuman) Conclusion(suite *moduletest.Suite) {
 	}
 }
 
+func (t *TestHuman) Coverage(report *coverage.Report) {
+	summary := report.Summary()
+
+	t.view.streams.Println()
+	t.view.streams.Printf("Coverage: %d of %d objects exercised (%s).\n",
+		summary.ExercisedObjects, summary.Objects, coveragePercent(summary.ExercisedObjects, summary.Objects))
+
+	var objects, rules []string
+	for _, obj := range report.Objects() {
+		if obj.Kind != coverage.VariableObject && !obj.Exercised() {
+			objects = append(objects, fmt.Sprintf("  - %s (%s)", obj.Address, obj.Range))
+		}
+		if obj.Checked {
+			continue
+		}
+		for _, rule := range obj.Rules {
+			rules = append(rules, fmt.Sprintf("  - %s %d of %s (%s)", rule.Kind, rule.Index, obj.Address, rule.Range))
+		}
+	}
+
+	if len(objects) > 0 {
+		t.view.streams.Println()
+		t.view.streams.Println("Objects not exercised by any run block:")
+		for _, line := range objects {
+			t.view.streams.Println(line)
+		}
+	}
+	if len(rules) > 0 {
+		t.view.streams.Println()
+		t.view.streams.Println("Rules not evaluated by any run block:")
+		for _, line := range rules {
+			t.view.streams.Println(line)
+		}
+	}
+}
+
 func (t *TestHuman) File(file *moduletest.File) {
 	t.view.streams.Printf("%s... %s\n", file.Name, colorizeTestStatus(file.Status, t.view.colorize))
 	t.Diagnostics(nil, file, file.Diagnostics)
@@ -390,6 +432,14 @@ func (t *TestJSON) Conclusion(suite *moduletest.Suite) {
 		json.MessageTestSummary, summary)
 }
 
+func (t *TestJSON) Coverage(report *coverage.Report) {
+	summary := report.Summary()
+	t.view.log.Info(
+		fmt.Sprintf("Coverage: %d of %d objects exercised.", summary.ExercisedObjects, summary.Objects),
+		"type", json.MessageTestCoverage,
+		json.MessageTestCoverage, json.NewTestCoverage(report))
+}
+
 func (t *TestJSON) File(file *moduletest.File) {
 	t.view.log.Info(
 		fmt.Sprintf("%s... %s", file.Name, testStatus(file.Status)),
@@ -541,6 +591,14 @@ func (t *TestJSON) FatalInterruptSummary(run *moduletest.Run, file *moduletest.F
 		"@testfile", file.Name)
 }
 
+// coveragePercent formats the given proportion as a whole percentage.
+func coveragePercent(n, total int) string {
+	if total == 0 {
+		return "100%"
+	}
+	return fmt.Sprintf("%d%%", n*100/total)
+}
+
 func colorizeTestStatus(status moduletest.Status, color *colorstring.Colorize) string {
 	switch status {
 	case moduletest.Error, moduletest.Fail:
@@ -582,6 +640,14 @@ func SaveErroredTestStateFile(state *states.State, run *moduletest.Run, file *mo
 	//creating an operation to invoke EmergencyDumpState()
 	var op Operation
 	switch v := view.(type) {
+	case *TestBuffered:
+		// The state must be written immediately, so we write out everything
+		// buffered so far and then use the underlying view directly.
+		v.Flush()
+		v.lock.Lock()
+		defer v.lock.Unlock()
+		SaveErroredTestStateFile(state, run, file, v.view)
+		return
 	case *TestHuman:
 		op = NewOperation(arguments.ViewHuman, false, v.view)
 		v.view.streams.Eprint(format.WordWrap("\nWriting state to file: errored_test.tfstate\n", v.view.errorColumns()))
@@ -0,0 +1,111 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"sync"
+
+	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/moduletest/coverage"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// TestBuffered is a Test view that holds back the output for a single test
+// file until Flush is called, so that the output of test files that are
+// executed concurrently isn't interleaved.
+//
+// The output that concerns the whole suite, and the details of a fatal
+// interrupt, are passed through to the underlying view immediately.
+type TestBuffered struct {
+	view Test
+
+	// lock is shared between all the buffered views that write to the same
+	// underlying view, and is held while writing to it.
+	lock *sync.Mutex
+
+	mu    sync.Mutex
+	calls []func(view Test)
+}
+
+var _ Test = (*TestBuffered)(nil)
+
+// NewTestBuffered returns a view that buffers output for the given view.
+// All buffered views for the same underlying view must share the same lock.
+func NewTestBuffered(view Test, lock *sync.Mutex) *TestBuffered {
+	return &TestBuffered{
+		view: view,
+		lock: lock,
+	}
+}
+
+// Flush writes all of the buffered output to the underlying view.
+func (t *TestBuffered) Flush() {
+	t.mu.Lock()
+	calls := t.calls
+	t.calls = nil
+	t.mu.Unlock()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, call := range calls {
+		call(t.view)
+	}
+}
+
+func (t *TestBuffered) buffer(call func(view Test)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.calls = append(t.calls, call)
+}
+
+func (t *TestBuffered) passThrough(call func(view Test)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	call(t.view)
+}
+
+func (t *TestBuffered) Abstract(suite *moduletest.Suite) {
+	t.passThrough(func(view Test) { view.Abstract(suite) })
+}
+
+func (t *TestBuffered) Conclusion(suite *moduletest.Suite) {
+	t.passThrough(func(view Test) { view.Conclusion(suite) })
+}
+
+func (t *TestBuffered) Coverage(report *coverage.Report) {
+	t.passThrough(func(view Test) { view.Coverage(report) })
+}
+
+func (t *TestBuffered) File(file *moduletest.File) {
+	t.buffer(func(view Test) { view.File(file) })
+}
+
+func (t *TestBuffered) Run(run *moduletest.Run, file *moduletest.File) {
+	t.buffer(func(view Test) { view.Run(run, file) })
+}
+
+func (t *TestBuffered) DestroySummary(diags tfdiags.Diagnostics, run *moduletest.Run, file *moduletest.File, state *states.State) {
+	t.buffer(func(view Test) { view.DestroySummary(diags, run, file, state) })
+}
+
+func (t *TestBuffered) Diagnostics(run *moduletest.Run, file *moduletest.File, diags tfdiags.Diagnostics) {
+	t.buffer(func(view Test) { view.Diagnostics(run, file, diags) })
+}
+
+func (t *TestBuffered) Interrupted() {
+	t.passThrough(func(view Test) { view.Interrupted() })
+}
+
+func (t *TestBuffered) FatalInterrupt() {
+	t.passThrough(func(view Test) { view.FatalInterrupt() })
+}
+
+func (t *TestBuffered) FatalInterruptSummary(run *moduletest.Run, file *moduletest.File, states map[*moduletest.Run]*states.State, created []*plans.ResourceInstanceChangeSrc) {
+	// OpenTofu may exit soon after a fatal interrupt, so we must print the
+	// details of what was left behind straight away.
+	t.Flush()
+	t.passThrough(func(view Test) { view.FatalInterruptSummary(run, file, states, created) })
+}
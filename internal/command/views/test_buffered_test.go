@@ -0,0 +1,46 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/terminal"
+)
+
+func TestTestBuffered(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewTest(arguments.ViewHuman, NewView(streams))
+
+	var lock sync.Mutex
+	first := NewTestBuffered(view, &lock)
+	second := NewTestBuffered(view, &lock)
+
+	one := &moduletest.File{Name: "one.tftest.hcl", Status: moduletest.Pass}
+	two := &moduletest.File{Name: "two.tftest.hcl", Status: moduletest.Fail}
+	runOne := &moduletest.Run{Name: "a", Status: moduletest.Pass}
+	runTwo := &moduletest.Run{Name: "b", Status: moduletest.Fail}
+
+	// The calls are interleaved, but the output for each file must be
+	// written together in the order the buffers are flushed.
+	first.File(one)
+	second.File(two)
+	second.Run(runTwo, two)
+	first.Run(runOne, one)
+
+	second.Flush()
+	first.Flush()
+	first.Flush() // flushing again must not repeat the output
+
+	actual := done(t).Stdout()
+	expected := "two.tftest.hcl... fail\n  run \"b\"... fail\none.tftest.hcl... pass\n  run \"a\"... pass\n"
+	if diff := cmp.Diff(expected, actual); len(diff) > 0 {
+		t.Fatalf("expected:\n%s\nactual:\n%s\ndiff:\n%s", expected, actual, diff)
+	}
+}
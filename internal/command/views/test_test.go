@@ -6,6 +6,7 @@
 package views
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -13,6 +14,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
@@ -20,6 +22,7 @@ import (
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/moduletest/coverage"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
@@ -414,6 +417,65 @@ func TestTestHuman_Conclusion(t *testing.T) {
 	}
 }
 
+func testCoverageReport(t *testing.T) *coverage.Report {
+	t.Helper()
+
+	fs := afero.NewMemMapFs()
+	src := `
+variable "name" {
+  type = string
+
+  validation {
+    condition     = length(var.name) > 0
+    error_message = "The name must not be empty."
+  }
+}
+
+resource "test_resource" "a" {
+}
+
+resource "test_resource" "b" {
+}
+`
+	if err := afero.WriteFile(fs, "main.tf", []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mod, diags := configs.NewParser(fs).LoadConfigDir(".", configs.RootModuleCallForTesting())
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	config, diags := configs.BuildConfig(context.Background(), mod, configs.DisabledModuleWalker)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	report := coverage.NewReport()
+	report.AddConfig(config)
+	report.Planned(config, addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_resource", Name: "a"}.InModule(addrs.RootModule))
+	return report
+}
+
+func TestTestHuman_Coverage(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewTest(arguments.ViewHuman, NewView(streams))
+
+	view.Coverage(testCoverageReport(t))
+
+	actual := done(t).Stdout()
+	expected := `
+Coverage: 1 of 2 objects exercised (50%).
+
+Objects not exercised by any run block:
+  - test_resource.b (main.tf:14,1-29)
+
+Rules not evaluated by any run block:
+  - validation 0 of var.name (main.tf:5,3-13)
+`
+	if diff := cmp.Diff(expected, actual); len(diff) > 0 {
+		t.Fatalf("expected:\n%s\nactual:\n%s\ndiff:\n%s", expected, actual, diff)
+	}
+}
+
 func TestTestHuman_File(t *testing.T) {
 	tcs := map[string]struct {
 		File     *moduletest.File
@@ -2460,6 +2522,49 @@ func TestTestJSON_File(t *testing.T) {
 	}
 }
 
+func TestTestJSON_Coverage(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewTest(arguments.ViewJSON, NewView(streams))
+
+	view.Coverage(testCoverageReport(t))
+
+	testJSONViewOutputEquals(t, done(t).All(), []map[string]interface{}{
+		{
+			"@level":   "info",
+			"@message": "Coverage: 1 of 2 objects exercised.",
+			"@module":  "tofu.ui",
+			"test_coverage": map[string]interface{}{
+				"objects":           float64(2),
+				"exercised_objects": float64(1),
+				"unexercised_objects": []interface{}{
+					map[string]interface{}{
+						"kind":    "resource",
+						"address": "test_resource.b",
+						"range": map[string]interface{}{
+							"filename": "main.tf",
+							"start":    map[string]interface{}{"line": float64(14), "column": float64(1), "byte": float64(183)},
+							"end":      map[string]interface{}{"line": float64(14), "column": float64(29), "byte": float64(211)},
+						},
+					},
+				},
+				"unevaluated_rules": []interface{}{
+					map[string]interface{}{
+						"kind":    "validation",
+						"index":   float64(0),
+						"address": "var.name",
+						"range": map[string]interface{}{
+							"filename": "main.tf",
+							"start":    map[string]interface{}{"line": float64(5), "column": float64(3), "byte": float64(38)},
+							"end":      map[string]interface{}{"line": float64(5), "column": float64(13), "byte": float64(48)},
+						},
+					},
+				},
+			},
+			"type": "test_coverage",
+		},
+	})
+}
+
 func TestTestJSON_Run(t *testing.T) {
 	tcs := map[string]struct {
 		run  *moduletest.Run
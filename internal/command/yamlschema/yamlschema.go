@@ -0,0 +1,473 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package yamlschema generates a JSON Schema document describing the YAML
+// configuration syntax, for use by editors and language servers.
+//
+// The schema describes the label nesting of the top-level block types and,
+// for each resource type, data source and ephemeral resource of the
+// installed providers, the arguments and nested blocks that they accept.
+// It can be referenced from a configuration file using a header like:
+//
+//	# yaml-language-server: $schema=./schema.json
+package yamlschema
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/providers"
+)
+
+// Schema is a JSON Schema (draft-07) document or subschema. Only the
+// keywords needed to describe the configuration language are included.
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	Ref         string             `json:"$ref,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Deprecated  bool               `json:"deprecated,omitempty"`
+	Type        []string           `json:"type,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+
+	// AdditionalProperties is either a *Schema or a bool.
+	AdditionalProperties any       `json:"additionalProperties,omitempty"`
+	Required             []string  `json:"required,omitempty"`
+	Items                *Schema   `json:"items,omitempty"`
+	AnyOf                []*Schema `json:"anyOf,omitempty"`
+
+	Definitions map[string]*Schema `json:"definitions,omitempty"`
+}
+
+const draft07 = "http://json-schema.org/draft-07/schema#"
+
+// Marshal returns a JSON Schema document describing YAML configuration
+// files that use the providers with the given schemas.
+func Marshal(schemas map[addrs.Provider]providers.ProviderSchema) ([]byte, error) {
+	return json.MarshalIndent(Generate(schemas), "", "  ")
+}
+
+// Generate returns the JSON Schema document that Marshal encodes.
+func Generate(schemas map[addrs.Provider]providers.ProviderSchema) *Schema {
+	g := &generator{defs: make(map[string]*Schema)}
+
+	providerConfigs := make(map[string]*Schema)
+	resources := make(map[string]*Schema)
+	dataSources := make(map[string]*Schema)
+	ephemerals := make(map[string]*Schema)
+
+	// Iterate over the providers in a predictable order so that the first
+	// of several providers with the same local name always wins.
+	provs := make([]addrs.Provider, 0, len(schemas))
+	for addr := range schemas {
+		provs = append(provs, addr)
+	}
+	sort.Slice(provs, func(i, j int) bool {
+		return provs[i].String() < provs[j].String()
+	})
+	for _, addr := range provs {
+		ps := schemas[addr]
+		if _, exists := providerConfigs[addr.Type]; !exists && ps.Provider.Block != nil {
+			providerConfigs[addr.Type] = blockValue(g.body("provider."+addr.Type, ps.Provider.Block, providerMeta))
+		}
+		for name, rs := range ps.ResourceTypes {
+			if _, exists := resources[name]; exists || rs.Block == nil {
+				continue
+			}
+			resources[name] = labels(1, blockValue(g.body("resource."+name, rs.Block, resourceMeta)))
+		}
+		for name, ds := range ps.DataSources {
+			if _, exists := dataSources[name]; exists || ds.Block == nil {
+				continue
+			}
+			dataSources[name] = labels(1, blockValue(g.body("data."+name, ds.Block, dataMeta)))
+		}
+		for name, es := range ps.EphemeralResources {
+			if _, exists := ephemerals[name]; exists || es.Block == nil {
+				continue
+			}
+			ephemerals[name] = labels(1, blockValue(g.body("ephemeral."+name, es.Block, dataMeta)))
+		}
+	}
+
+	root := &Schema{
+		Schema:      draft07,
+		Title:       "OpenTofu YAML configuration",
+		Type:        []string{"object", "null"},
+		Properties:  make(map[string]*Schema),
+		Definitions: g.defs,
+	}
+	for name, s := range languageBlocks() {
+		root.Properties[name] = s
+	}
+	root.Properties["provider"] = blockTypes(providerConfigs, blockValue(&Schema{Type: []string{"object", "null"}}))
+	root.Properties["resource"] = blockTypes(resources, nil)
+	root.Properties["data"] = blockTypes(dataSources, nil)
+	root.Properties["ephemeral"] = blockTypes(ephemerals, nil)
+	root.Properties[commentKey] = comment()
+	root.AdditionalProperties = false
+
+	return root
+}
+
+// commentKey is the property name that yamlbody treats as a comment in any
+// body.
+const commentKey = "//"
+
+type generator struct {
+	defs map[string]*Schema
+}
+
+// metaArguments returns the schemas for the meta-arguments that can appear
+// in a particular kind of block alongside those defined by its schema.
+type metaArguments func() map[string]*Schema
+
+// body registers a definition describing the contents of a block with the
+// given schema, and returns a reference to it.
+//
+// Nested blocks are registered as separate definitions, named after the path
+// to them, so that each body is described only once even though a nested
+// block can be written either as a single mapping or as a sequence.
+func (g *generator) body(name string, block *configschema.Block, meta metaArguments) *Schema {
+	def := &Schema{
+		Description: block.Description,
+		Deprecated:  block.Deprecated,
+		Type:        []string{"object"},
+		Properties:  make(map[string]*Schema),
+	}
+	g.defs[name] = def
+
+	for attrName, attr := range block.Attributes {
+		def.Properties[attrName] = attribute(attr)
+		if attr.Required {
+			def.Required = append(def.Required, attrName)
+		}
+	}
+	sort.Strings(def.Required)
+
+	dynamic := make(map[string]*Schema)
+	for blockName, nested := range block.BlockTypes {
+		ref := g.body(name+"."+blockName, &nested.Block, nil)
+		def.Properties[blockName] = nestedBlock(nested.Nesting, ref)
+		dynamic[blockName] = blockValue(dynamicBody(ref))
+	}
+	if len(dynamic) > 0 {
+		def.Properties["dynamic"] = &Schema{
+			Description:          "Dynamically-constructed nested blocks.",
+			Type:                 []string{"object"},
+			Properties:           dynamic,
+			AdditionalProperties: false,
+		}
+	}
+
+	if meta != nil {
+		for metaName, s := range meta() {
+			// A schema can't override a meta-argument, so if it defines
+			// something with the same name then the schema's definition
+			// can't be used in the configuration anyway.
+			def.Properties[metaName] = s
+		}
+	}
+	def.Properties[commentKey] = comment()
+	def.AdditionalProperties = false
+
+	return &Schema{Ref: "#/definitions/" + escapePointer(name)}
+}
+
+// escapePointer escapes a definition name for use in a JSON Pointer.
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+// attribute returns the schema for the value of the given attribute.
+func attribute(attr *configschema.Attribute) *Schema {
+	var ret *Schema
+	if attr.NestedType != nil {
+		ret = nestedObject(attr.NestedType)
+	} else {
+		ret = valueType(attr.Type)
+	}
+	ret.Description = attr.Description
+	ret.Deprecated = attr.Deprecated
+	return ret
+}
+
+func nestedObject(obj *configschema.Object) *Schema {
+	attrs := &Schema{
+		Type:                 []string{"object", "string", "null"},
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+	}
+	for name, attr := range obj.Attributes {
+		attrs.Properties[name] = attribute(attr)
+	}
+
+	switch obj.Nesting {
+	case configschema.NestingList, configschema.NestingSet:
+		return &Schema{Type: []string{"array", "string", "null"}, Items: attrs}
+	case configschema.NestingMap:
+		return &Schema{Type: []string{"object", "string", "null"}, AdditionalProperties: attrs}
+	default:
+		return attrs
+	}
+}
+
+// valueType returns the schema for a value of the given type.
+//
+// Any value can be written as an expression or a template, so every schema
+// also accepts a string in place of the value itself. Strings accept numbers
+// and bools, which are converted automatically.
+func valueType(ty cty.Type) *Schema {
+	switch {
+	case ty == cty.String:
+		return &Schema{Type: []string{"string", "number", "boolean", "null"}}
+	case ty == cty.Number:
+		return &Schema{Type: []string{"number", "string", "null"}}
+	case ty == cty.Bool:
+		return &Schema{Type: []string{"boolean", "string", "null"}}
+	case ty.IsListType() || ty.IsSetType():
+		return &Schema{Type: []string{"array", "string", "null"}, Items: valueType(ty.ElementType())}
+	case ty.IsMapType():
+		return &Schema{Type: []string{"object", "string", "null"}, AdditionalProperties: valueType(ty.ElementType())}
+	case ty.IsObjectType():
+		ret := &Schema{
+			Type:                 []string{"object", "string", "null"},
+			Properties:           make(map[string]*Schema),
+			AdditionalProperties: false,
+		}
+		for name, aty := range ty.AttributeTypes() {
+			ret.Properties[name] = valueType(aty)
+		}
+		return ret
+	case ty.IsTupleType():
+		return &Schema{Type: []string{"array", "string", "null"}}
+	default:
+		// cty.DynamicPseudoType accepts anything.
+		return &Schema{}
+	}
+}
+
+// nestedBlock returns the schema for a nested block with the given nesting
+// mode and body.
+func nestedBlock(nesting configschema.NestingMode, body *Schema) *Schema {
+	switch nesting {
+	case configschema.NestingList, configschema.NestingSet:
+		return blockValue(body)
+	case configschema.NestingMap:
+		return labels(1, body)
+	default:
+		return body
+	}
+}
+
+// blockValue returns a schema accepting either a single block with the given
+// body or a sequence of them, which is how yamlbody represents multiple
+// blocks of the same type.
+func blockValue(body *Schema) *Schema {
+	return &Schema{
+		AnyOf: []*Schema{
+			body,
+			{Type: []string{"array"}, Items: body},
+		},
+	}
+}
+
+// labels wraps the given schema in n levels of mapping, one for each block
+// label.
+func labels(n int, s *Schema) *Schema {
+	for range n {
+		s = &Schema{
+			Type:                 []string{"object"},
+			AdditionalProperties: s,
+		}
+	}
+	return s
+}
+
+// blockTypes returns the schema for a top-level block type whose first label
+// is the name of a resource type or provider. If other is nil then only the
+// given names are accepted.
+func blockTypes(names map[string]*Schema, other *Schema) *Schema {
+	ret := &Schema{
+		Type:       []string{"object"},
+		Properties: names,
+	}
+	if other != nil {
+		ret.AdditionalProperties = other
+	} else {
+		ret.AdditionalProperties = false
+	}
+	return ret
+}
+
+func dynamicBody(content *Schema) *Schema {
+	return &Schema{
+		Type: []string{"object"},
+		Properties: map[string]*Schema{
+			"for_each": {Description: "The collection to create a block for each element of."},
+			"iterator": expr("The name of the temporary variable representing the current element."),
+			"labels":   {Type: []string{"array", "string"}, Items: &Schema{Type: []string{"string"}}},
+			"content":  content,
+			commentKey: comment(),
+		},
+		Required:             []string{"content", "for_each"},
+		AdditionalProperties: false,
+	}
+}
+
+func comment() *Schema {
+	return &Schema{
+		Description: "A comment.",
+		Type:        []string{"string"},
+	}
+}
+
+// expr returns the schema for an argument that takes a string, which is
+// typically a reference written with the !ref tag.
+func expr(desc string) *Schema {
+	return &Schema{Description: desc, Type: []string{"string"}}
+}
+
+// anything returns a schema that accepts any value.
+func anything(desc string) *Schema {
+	return &Schema{Description: desc}
+}
+
+// object returns the schema for a body with the given arguments, which
+// doesn't accept any other arguments unless open is true.
+func object(desc string, open bool, props map[string]*Schema) *Schema {
+	props[commentKey] = comment()
+	ret := &Schema{
+		Description: desc,
+		Type:        []string{"object", "null"},
+		Properties:  props,
+	}
+	if !open {
+		ret.AdditionalProperties = false
+	}
+	return ret
+}
+
+func conditions(desc string) *Schema {
+	return blockValue(object(desc, false, map[string]*Schema{
+		"condition":     anything("The condition that must be true."),
+		"error_message": anything("The error message to return if the condition is false."),
+	}))
+}
+
+func dependsOn() *Schema {
+	return &Schema{
+		Description: "Explicit dependencies on other objects.",
+		Type:        []string{"array", "string"},
+		Items:       &Schema{Type: []string{"string"}},
+	}
+}
+
+func providerMeta() map[string]*Schema {
+	return map[string]*Schema{
+		"alias":   expr("An alternate name for this provider configuration."),
+		"version": expr("Deprecated. Use required_providers instead."),
+	}
+}
+
+func dataMeta() map[string]*Schema {
+	return map[string]*Schema{
+		"count":      anything("The number of instances to create."),
+		"for_each":   anything("A map or set of strings to create an instance for each element of."),
+		"provider":   expr("The provider configuration to use, such as aws.west."),
+		"depends_on": dependsOn(),
+		"lifecycle": object("", false, map[string]*Schema{
+			"precondition":  conditions("A condition checked before evaluating this object."),
+			"postcondition": conditions("A condition checked after evaluating this object."),
+			"enabled":       anything("Whether this object is declared."),
+		}),
+	}
+}
+
+func resourceMeta() map[string]*Schema {
+	ret := dataMeta()
+	lifecycle := ret["lifecycle"]
+	lifecycle.Properties["create_before_destroy"] = anything("Whether to create a replacement object before destroying this one.")
+	lifecycle.Properties["prevent_destroy"] = anything("Whether to reject plans that would destroy this object.")
+	lifecycle.Properties["ignore_changes"] = anything("Arguments whose changes are ignored when planning updates, or all.")
+	lifecycle.Properties["replace_triggered_by"] = anything("References whose changes cause this object to be replaced.")
+	ret["connection"] = object("How to connect to the object for provisioning.", true, map[string]*Schema{})
+	ret["provisioner"] = labels(1, blockValue(object("", true, map[string]*Schema{
+		"when":       anything("When to run the provisioner, either create or destroy."),
+		"on_failure": anything("What to do if the provisioner fails, either continue or fail."),
+		"connection": object("How to connect to the object for provisioning.", true, map[string]*Schema{}),
+	})))
+	return ret
+}
+
+// languageBlocks returns the schemas for the top-level block types that are
+// defined by the language rather than by providers.
+func languageBlocks() map[string]*Schema {
+	return map[string]*Schema{
+		"terraform": object("Settings for OpenTofu itself.", true, map[string]*Schema{
+			"required_version": expr("The versions of OpenTofu that this module is compatible with."),
+			"required_providers": &Schema{
+				Description:          "The providers that this module requires.",
+				Type:                 []string{"object"},
+				AdditionalProperties: true,
+			},
+			"backend":     labels(1, object("", true, map[string]*Schema{})),
+			"cloud":       object("", true, map[string]*Schema{}),
+			"encryption":  object("State and plan encryption settings.", true, map[string]*Schema{}),
+			"experiments": &Schema{Type: []string{"array"}},
+		}),
+		"variable": labels(1, object("An input variable.", false, map[string]*Schema{
+			"type":        expr("The type of value accepted, written with the !ref or !expr tag."),
+			"default":     anything("The default value."),
+			"description": expr("The documentation for this variable."),
+			"sensitive":   anything("Whether to hide the value in output."),
+			"nullable":    anything("Whether the value can be null."),
+			"ephemeral":   anything("Whether the value is ephemeral."),
+			"deprecated":  expr("A message to show when the variable is set."),
+			"validation":  conditions("A custom validation rule."),
+		})),
+		"output": labels(1, object("An output value.", false, map[string]*Schema{
+			"value":        anything("The value to return."),
+			"description":  expr("The documentation for this output value."),
+			"sensitive":    anything("Whether to hide the value in output."),
+			"ephemeral":    anything("Whether the value is ephemeral."),
+			"deprecated":   expr("A message to show when the output value is used."),
+			"depends_on":   dependsOn(),
+			"precondition": conditions("A condition checked before evaluating the value."),
+		})),
+		"locals": blockValue(object("Local values.", true, map[string]*Schema{})),
+		"module": labels(1, object("A module call. Any other arguments set the module's input variables.", true, map[string]*Schema{
+			"source":     expr("The location of the module's source code."),
+			"version":    expr("The versions of the module to accept."),
+			"count":      anything("The number of instances to create."),
+			"for_each":   anything("A map or set of strings to create an instance for each element of."),
+			"providers":  &Schema{Type: []string{"object", "string"}},
+			"depends_on": dependsOn(),
+		})),
+		"check": labels(1, object("A check block.", false, map[string]*Schema{
+			"data":   &Schema{Type: []string{"object"}, AdditionalProperties: labels(1, &Schema{Type: []string{"object"}})},
+			"assert": conditions("An assertion."),
+		})),
+		"moved": blockValue(object("Records that an object has moved to a new address.", false, map[string]*Schema{
+			"from": expr("The old address, written with the !ref tag."),
+			"to":   expr("The new address, written with the !ref tag."),
+		})),
+		"import": blockValue(object("Imports an existing object.", false, map[string]*Schema{
+			"to":       expr("The address to import to, written with the !ref tag."),
+			"id":       anything("The provider-specific ID of the object."),
+			"provider": expr("The provider configuration to use."),
+			"for_each": anything("A map or set to import an object for each element of."),
+		})),
+		"removed": blockValue(object("Records that an object has been removed from the configuration.", false, map[string]*Schema{
+			"from":        expr("The address of the removed object, written with the !ref tag."),
+			"lifecycle":   object("", false, map[string]*Schema{"destroy": anything("Whether to destroy the object.")}),
+			"provisioner": labels(1, blockValue(object("", true, map[string]*Schema{}))),
+			"connection":  object("", true, map[string]*Schema{}),
+		})),
+	}
+}
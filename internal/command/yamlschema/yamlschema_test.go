@@ -0,0 +1,214 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package yamlschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/providers"
+)
+
+func testSchemas() map[addrs.Provider]providers.ProviderSchema {
+	return map[addrs.Provider]providers.ProviderSchema{
+		addrs.NewDefaultProvider("test"): {
+			Provider: providers.Schema{
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"region": {Type: cty.String, Optional: true},
+					},
+				},
+			},
+			ResourceTypes: map[string]providers.Schema{
+				"test_instance": {
+					Block: &configschema.Block{
+						Description: "An instance.",
+						Attributes: map[string]*configschema.Attribute{
+							"id":  {Type: cty.String, Computed: true},
+							"ami": {Type: cty.String, Required: true, Description: "The image."},
+							"volumes": {
+								NestedType: &configschema.Object{
+									Nesting: configschema.NestingList,
+									Attributes: map[string]*configschema.Attribute{
+										"size": {Type: cty.Number, Required: true},
+									},
+								},
+								Optional: true,
+							},
+						},
+						BlockTypes: map[string]*configschema.NestedBlock{
+							"network": {
+								Nesting: configschema.NestingList,
+								Block: configschema.Block{
+									Attributes: map[string]*configschema.Attribute{
+										"public": {Type: cty.Bool, Optional: true},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			DataSources: map[string]providers.Schema{
+				"test_data": {
+					Block: &configschema.Block{
+						Attributes: map[string]*configschema.Attribute{
+							"tags": {Type: cty.Map(cty.String), Optional: true},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	got := Generate(testSchemas())
+
+	if got.Schema != draft07 {
+		t.Errorf("wrong $schema %q", got.Schema)
+	}
+	for _, name := range []string{"terraform", "variable", "output", "locals", "module", "provider", "resource", "data", "ephemeral", "moved", "import", "removed", "check", "//"} {
+		if got.Properties[name] == nil {
+			t.Errorf("missing top-level property %q", name)
+		}
+	}
+
+	// resource: test_instance: <name>: body or [body]
+	resource := got.Properties["resource"]
+	if resource.AdditionalProperties != false {
+		t.Errorf("unknown resource types are accepted")
+	}
+	ref := &Schema{Ref: "#/definitions/resource.test_instance"}
+	wantInstance := labels(1, blockValue(ref))
+	if diff := cmp.Diff(wantInstance, resource.Properties["test_instance"]); diff != "" {
+		t.Errorf("wrong resource type schema\n%s", diff)
+	}
+
+	def := got.Definitions["resource.test_instance"]
+	if def == nil {
+		t.Fatalf("missing definition for test_instance")
+	}
+	if def.Description != "An instance." {
+		t.Errorf("wrong description %q", def.Description)
+	}
+	if diff := cmp.Diff([]string{"ami"}, def.Required); diff != "" {
+		t.Errorf("wrong required attributes\n%s", diff)
+	}
+	if got, want := def.Properties["ami"].Description, "The image."; got != want {
+		t.Errorf("wrong attribute description %q; want %q", got, want)
+	}
+	for _, name := range []string{"id", "ami", "volumes", "network", "dynamic", "count", "for_each", "provider", "depends_on", "lifecycle", "provisioner", "connection", "//"} {
+		if def.Properties[name] == nil {
+			t.Errorf("missing property %q", name)
+		}
+	}
+	wantNetwork := blockValue(&Schema{Ref: "#/definitions/resource.test_instance.network"})
+	if diff := cmp.Diff(wantNetwork, def.Properties["network"]); diff != "" {
+		t.Errorf("wrong nested block schema\n%s", diff)
+	}
+	if got.Definitions["resource.test_instance.network"] == nil {
+		t.Errorf("missing definition for nested block")
+	}
+	if got := def.Properties["volumes"].Items.Properties["size"].Type; !cmp.Equal(got, []string{"number", "string", "null"}) {
+		t.Errorf("wrong nested attribute type %q", got)
+	}
+
+	// Data sources accept only the meta-arguments that apply to them.
+	data := got.Definitions["data.test_data"]
+	if data == nil {
+		t.Fatalf("missing definition for test_data")
+	}
+	for _, name := range []string{"provisioner", "connection", "dynamic"} {
+		if data.Properties[name] != nil {
+			t.Errorf("unexpected property %q", name)
+		}
+	}
+
+	if got.Properties["provider"].Properties["test"] == nil {
+		t.Errorf("missing provider configuration schema")
+	}
+	if got.Definitions["provider.test"].Properties["alias"] == nil {
+		t.Errorf("missing alias in provider configuration schema")
+	}
+
+	// The result must always be valid JSON.
+	if _, err := Marshal(testSchemas()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMarshal_empty(t *testing.T) {
+	got, err := Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(got, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["$schema"] != draft07 {
+		t.Errorf("wrong $schema %v", doc["$schema"])
+	}
+	if _, ok := doc["definitions"]; ok {
+		t.Errorf("unexpected definitions without any providers")
+	}
+}
+
+func TestValueType(t *testing.T) {
+	tests := map[string]struct {
+		ty   cty.Type
+		want *Schema
+	}{
+		"string": {
+			cty.String,
+			&Schema{Type: []string{"string", "number", "boolean", "null"}},
+		},
+		"bool": {
+			cty.Bool,
+			&Schema{Type: []string{"boolean", "string", "null"}},
+		},
+		"list": {
+			cty.List(cty.Number),
+			&Schema{
+				Type:  []string{"array", "string", "null"},
+				Items: &Schema{Type: []string{"number", "string", "null"}},
+			},
+		},
+		"map": {
+			cty.Map(cty.Bool),
+			&Schema{
+				Type:                 []string{"object", "string", "null"},
+				AdditionalProperties: &Schema{Type: []string{"boolean", "string", "null"}},
+			},
+		},
+		"object": {
+			cty.Object(map[string]cty.Type{"a": cty.Number}),
+			&Schema{
+				Type: []string{"object", "string", "null"},
+				Properties: map[string]*Schema{
+					"a": {Type: []string{"number", "string", "null"}},
+				},
+				AdditionalProperties: false,
+			},
+		},
+		"any": {
+			cty.DynamicPseudoType,
+			&Schema{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, valueType(test.ty)); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
@@ -53,10 +53,18 @@ func buildTestModules(ctx context.Context, root *Config, walker ModuleWalker) hc
 	var diags hcl.Diagnostics
 
 	for name, file := range root.Module.Tests {
+		// Runs expanded from the same run block by for_each share their
+		// module, so we only load it once.
+		loaded := make(map[*TestRunModuleCall]*Config)
+
 		for _, run := range file.Runs {
 			if run.Module == nil {
 				continue
 			}
+			if cfg, ok := loaded[run.Module]; ok {
+				run.ConfigUnderTest = cfg
+				continue
+			}
 
 			// We want to make sure the path for the testing modules are unique
 			// so we create a dedicated path for them.
@@ -73,9 +81,9 @@ func buildTestModules(ctx context.Context, root *Config, walker ModuleWalker) hc
 			if dir != "." {
 				path = append(path, strings.Split(dir, "/")...)
 			}
-			path = append(path, strings.TrimSuffix(base, ".tftest.hcl"), run.Name)
+			path = append(path, strings.TrimSuffix(base, ".tftest.hcl"), run.BlockName)
 			req := ModuleRequest{
-				Name:              run.Name,
+				Name:              run.BlockName,
 				Path:              path,
 				SourceAddr:        run.Module.Source,
 				SourceAddrRange:   run.Module.SourceDeclRange,
@@ -108,6 +116,7 @@ func buildTestModules(ctx context.Context, root *Config, walker ModuleWalker) hc
 				// Finally, link the new config back into our test run so
 				// it can be retrieved later.
 				run.ConfigUnderTest = cfg
+				loaded[run.Module] = cfg
 			}
 		}
 	}
@@ -69,12 +69,17 @@ func decodeImportBlock(block *hcl.Block) (*Import, hcl.Diagnostics) {
 	}
 
 	if attr, exists := content.Attributes["to"]; exists {
-		toExpr := attr.Expr
+		toExpr, unwrapDiags := yamlbody.UnwrapNativeSyntax(attr.Expr)
+		diags = append(diags, unwrapDiags...)
+		if unwrapDiags.HasErrors() {
+			return imp, diags
+		}
+
 		// Since we are manually parsing the 'to' argument, we need to specially
 		// handle json and yaml configs, in which case the values will be strings
 		// rather than hcl
-		isJSON := hcljson.IsJSONExpression(attr.Expr)
-		isYAML := yamlbody.IsYAMLExpression(attr.Expr)
+		isJSON := hcljson.IsJSONExpression(toExpr)
+		isYAML := yamlbody.IsYAMLExpression(toExpr)
 
 		if isJSON || isYAML {
 			convertedExpr, convertDiags := hcl2shim.ConvertJSONExpressionToHCL(toExpr)
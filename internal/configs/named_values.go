@@ -19,6 +19,7 @@ import (
 	"github.com/zclconf/go-cty/cty/convert"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs/yamlbody"
 	"github.com/opentofu/opentofu/internal/didyoumean"
 	"github.com/opentofu/opentofu/internal/lang/lint"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -114,6 +115,10 @@ func decodeVariableBlock(block *hcl.Block, override bool) (*Variable, hcl.Diagno
 		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &v.Description)
 		diags = append(diags, valDiags...)
 		v.DescriptionSet = true
+	} else {
+		// In YAML, a comment written before the variable is its default
+		// description.
+		v.Description = yamlbody.HeadComment(block.Body)
 	}
 
 	if attr, exists := content.Attributes["type"]; exists {
@@ -528,6 +533,10 @@ func decodeOutputBlock(block *hcl.Block, override bool) (*Output, hcl.Diagnostic
 		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &o.Description)
 		diags = append(diags, valDiags...)
 		o.DescriptionSet = true
+	} else {
+		// In YAML, a comment written before the output value is its default
+		// description.
+		o.Description = yamlbody.HeadComment(block.Body)
 	}
 
 	if attr, exists := content.Attributes["value"]; exists {
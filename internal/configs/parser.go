@@ -60,6 +60,17 @@ func NewParser(fs afero.Fs) *Parser {
 // ends with ".json", in which case the HCL JSON syntax will be used, or
 // ends with ".yaml" or ".yml", in which case the YAML syntax will be used.
 func (p *Parser) LoadHCLFile(path string) (hcl.Body, hcl.Diagnostics) {
+	return p.loadHCLFile(path, true)
+}
+
+// LoadSingleDocumentHCLFile is like LoadHCLFile, except that a YAML file must
+// contain only a single document. This is for files whose content is not
+// expected to be split up in the same way as the files of a module.
+func (p *Parser) LoadSingleDocumentHCLFile(path string) (hcl.Body, hcl.Diagnostics) {
+	return p.loadHCLFile(path, false)
+}
+
+func (p *Parser) loadHCLFile(path string, multiDoc bool) (hcl.Body, hcl.Diagnostics) {
 	src, err := p.fs.ReadFile(path)
 
 	if err != nil {
@@ -78,7 +89,11 @@ func (p *Parser) LoadHCLFile(path string) (hcl.Body, hcl.Diagnostics) {
 	case strings.HasSuffix(path, ".json"):
 		file, diags = p.p.ParseJSON(src, path)
 	case isYAMLFile(path):
-		file, diags = p.parseYAML(src, path)
+		if multiDoc {
+			file, diags = p.parseYAML(src, path)
+		} else {
+			file, diags = p.parseYAMLDocument(src, path)
+		}
 		// Register YAML files with the parser cache so source code
 		// is available for diagnostic snippets
 		if file != nil {
@@ -6,6 +6,8 @@
 package configs
 
 import (
+	"path/filepath"
+
 	"github.com/hashicorp/hcl/v2"
 	"github.com/opentofu/opentofu/internal/encryption/config"
 )
@@ -46,7 +48,7 @@ func (p *Parser) LoadTestFile(path string) (*TestFile, hcl.Diagnostics) {
 		return nil, diags
 	}
 
-	test, testDiags := loadTestFile(body)
+	test, testDiags := loadTestFile(body, p.fs, filepath.Dir(path))
 	diags = append(diags, testDiags...)
 	return test, diags
 }
@@ -408,6 +408,15 @@ func isTestFileExt(ext string) bool {
 	return false
 }
 
+// TrimTestFileExt returns the given path without its test file extension, or
+// the path unchanged if it is not a test file.
+func TrimTestFileExt(path string) string {
+	if ext := fileExt(path); isTestFileExt(ext) {
+		return strings.TrimSuffix(path, ext)
+	}
+	return path
+}
+
 // IsIgnoredFile returns true if the given filename (which must not have a
 // directory path ahead of it) should be ignored as e.g. an editor swap file.
 func IsIgnoredFile(name string) bool {
@@ -4,12 +4,10 @@
 package configs
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/opentofu/opentofu/internal/configs/yamlbody"
-	"gopkg.in/yaml.v3"
 )
 
 // parseYAML parses YAML source into an hcl.File with accurate source positions
@@ -18,34 +16,17 @@ import (
 // This implementation uses yaml.Node to preserve:
 // - Exact line/column positions for error reporting
 // - All comment types (head, line, foot) for tooling support
+//
+// A file can contain a stream of several documents, each of which is decoded
+// as a separate body in the same way as separate files in a module.
 func (p *Parser) parseYAML(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
-	// Parse YAML into a yaml.Node tree to preserve positions and comments
-	var root yaml.Node
-	if err := yaml.Unmarshal(src, &root); err != nil {
-		return nil, hcl.Diagnostics{
-			{
-				Severity: hcl.DiagError,
-				Summary:  "Invalid YAML syntax",
-				Detail:   fmt.Sprintf("The file %q contains invalid YAML: %s", filename, err),
-			},
-		}
-	}
-
-	// Handle empty YAML files - create an empty mapping node
-	if root.Kind == 0 || (root.Kind == yaml.DocumentNode && len(root.Content) == 0) {
-		root = yaml.Node{
-			Kind:    yaml.DocumentNode,
-			Content: []*yaml.Node{{Kind: yaml.MappingNode}},
-		}
-	}
-
-	// Create an hcl.File with our YAML body implementation
-	file := &hcl.File{
-		Body:  yamlbody.NewBody(&root, filename, src),
-		Bytes: src,
-	}
+	return yamlbody.ParseDocuments(src, filename)
+}
 
-	return file, nil
+// parseYAMLDocument is like parseYAML, but returns an error if the source
+// contains more than one document.
+func (p *Parser) parseYAMLDocument(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
+	return yamlbody.Parse(src, filename)
 }
 
 // isYAMLFile returns true if the given path has a YAML extension.
@@ -4,7 +4,10 @@
 package configs
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/hashicorp/hcl/v2"
 )
 
 func TestParserLoadYAMLFile(t *testing.T) {
@@ -222,6 +225,56 @@ output:
 	}
 }
 
+func TestYAMLHeadCommentDescriptions(t *testing.T) {
+	src := `
+variable:
+  # The number of instances
+  # to create.
+  instance_count:
+    type: !ref number
+  # Not used, because there's an explicit description.
+  region:
+    description: The region
+  zone: {}
+
+output:
+  # The instance IP
+  instance_ip:
+    value: test_value
+`
+	parser := testParser(map[string]string{
+		"main.tf.yaml": src,
+	})
+
+	file, diags := parser.LoadConfigFile("main.tf.yaml")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+
+	wantVars := map[string]string{
+		"instance_count": "The number of instances\nto create.",
+		"region":         "The region",
+		"zone":           "",
+	}
+	for _, v := range file.Variables {
+		if got, want := v.Description, wantVars[v.Name]; got != want {
+			t.Errorf("wrong description for variable %q\ngot:  %q\nwant: %q", v.Name, got, want)
+		}
+		// A comment only provides a default, so that it doesn't take
+		// precedence over a description in an override file.
+		if got, want := v.DescriptionSet, v.Name == "region"; got != want {
+			t.Errorf("wrong DescriptionSet for variable %q: %t", v.Name, got)
+		}
+	}
+
+	if len(file.Outputs) != 1 {
+		t.Fatalf("expected 1 output, got %d", len(file.Outputs))
+	}
+	if got, want := file.Outputs[0].Description, "The instance IP"; got != want {
+		t.Errorf("wrong description for output\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
 func TestYAMLLocalsParsing(t *testing.T) {
 	src := `
 locals:
@@ -443,6 +496,25 @@ func TestYAMLSourcesAvailableForDiagnostics(t *testing.T) {
 	}
 }
 
+func TestParserLoadSingleDocumentHCLFile_yaml(t *testing.T) {
+	src := `path: hello
+---
+path: world
+`
+	parser := testParser(map[string]string{
+		"backend.yaml": src,
+	})
+
+	if _, diags := parser.LoadHCLFile("backend.yaml"); diags.HasErrors() {
+		t.Fatalf("unexpected errors from LoadHCLFile: %v", diags)
+	}
+
+	_, diags := parser.LoadSingleDocumentHCLFile("backend.yaml")
+	if len(diags) != 1 || diags[0].Summary != "Multiple YAML documents" {
+		t.Fatalf("expected a single multiple documents error, got: %v", diags)
+	}
+}
+
 func TestYAMLSourcesAvailableForMultipleFiles(t *testing.T) {
 	// Test that multiple YAML files are all registered in Sources()
 	files := map[string]string{
@@ -488,3 +560,162 @@ func TestYAMLSourcesAvailableForMultipleFiles(t *testing.T) {
 		}
 	}
 }
+
+func TestYAMLMergeKeys(t *testing.T) {
+	src := `x-common: &common
+  tags:
+    team: platform
+  lifecycle: &lifecycle
+    create_before_destroy: true
+
+resource:
+  test_instance:
+    web:
+      <<: *common
+      name: web
+    db:
+      <<: *common
+      name: db
+      lifecycle:
+        <<: *lifecycle
+        prevent_destroy: true
+`
+	parser := testParser(map[string]string{
+		"main.tf.yaml": src,
+	})
+
+	file, diags := parser.LoadConfigFile("main.tf.yaml")
+	// The top-level "x-common" key is not a valid block type, but merging
+	// it into the resources must not produce any further errors.
+	if len(diags) != 1 || diags[0].Summary != "Extraneous YAML property" {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	if len(file.ManagedResources) != 2 {
+		t.Fatalf("expected 2 resources, got %d", len(file.ManagedResources))
+	}
+
+	for _, res := range file.ManagedResources {
+		if !res.Managed.CreateBeforeDestroy {
+			t.Errorf("expected %s to inherit create_before_destroy", res.Name)
+		}
+		if got, want := res.Managed.PreventDestroy != nil, res.Name == "db"; got != want {
+			t.Errorf("wrong prevent_destroy presence for %s: got %t, want %t", res.Name, got, want)
+		}
+	}
+}
+
+func TestYAMLExpressionTags(t *testing.T) {
+	src := `resource:
+  test_instance:
+    web:
+      count: !expr "var.enabled ? 1 : 0"
+      name: !literal "${not_a_template}"
+      lifecycle:
+        replace_triggered_by:
+          - !ref test_instance.db.id
+          - test_instance.cache
+
+import:
+  - to: !expr test_instance.imported[0]
+    id: i-12345
+`
+	parser := testParser(map[string]string{
+		"main.tf.yaml": src,
+	})
+
+	file, diags := parser.LoadConfigFile("main.tf.yaml")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+
+	if len(file.ManagedResources) != 1 {
+		t.Fatalf("expected 1 resource, got %d", len(file.ManagedResources))
+	}
+	res := file.ManagedResources[0]
+	if vars := res.Count.Variables(); len(vars) != 1 || vars[0].RootName() != "var" {
+		t.Errorf("expected count to refer to var.enabled, got %#v", vars)
+	}
+	if got := len(res.TriggersReplacement); got != 2 {
+		t.Errorf("expected 2 replace_triggered_by expressions, got %d", got)
+	}
+
+	if len(file.Import) != 1 {
+		t.Fatalf("expected 1 import, got %d", len(file.Import))
+	}
+	if got, want := file.Import[0].StaticTo.String(), "test_instance.imported"; got != want {
+		t.Errorf("wrong import target %q; want %q", got, want)
+	}
+}
+
+func TestYAMLMultipleDocuments(t *testing.T) {
+	src := `resource:
+  test_instance:
+    web:
+      ami: ami-123
+---
+# An empty document is allowed
+---
+resource:
+  test_instance:
+    db:
+      ami: ami-456
+variable:
+  name: {}
+`
+	parser := testParser(map[string]string{
+		"main.tf.yaml": src,
+	})
+
+	file, diags := parser.LoadConfigFile("main.tf.yaml")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+	if len(file.ManagedResources) != 2 {
+		t.Fatalf("expected 2 resources, got %d", len(file.ManagedResources))
+	}
+	if got := file.ManagedResources[1].DeclRange.Start.Line; got != 11 {
+		t.Errorf("wrong line for the resource in the third document: %d", got)
+	}
+	if len(file.Variables) != 1 {
+		t.Fatalf("expected 1 variable, got %d", len(file.Variables))
+	}
+}
+
+func TestYAMLMultipleDocumentsDiagnostics(t *testing.T) {
+	src := `resource:
+  test_instance:
+    web:
+      ami: ami-123
+---
+resource:
+  test_instance:
+    db:
+      amy: ami-456
+`
+	parser := testParser(map[string]string{
+		"main.tf.yaml": src,
+	})
+
+	file, diags := parser.LoadConfigFile("main.tf.yaml")
+	if len(file.ManagedResources) != 2 {
+		t.Fatalf("expected 2 resources, got %d", len(file.ManagedResources))
+	}
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	// The resource body isn't decoded until its provider schema is known.
+	_, diags = file.ManagedResources[1].Config.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "ami"}},
+	})
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1: %v", len(diags), diags)
+	}
+	if got, want := diags[0].Detail, "This is in YAML document 2 of the file."; !strings.HasSuffix(got, want) {
+		t.Errorf("wrong detail %q; want suffix %q", got, want)
+	}
+	if got := diags[0].Subject.Start.Line; got != 9 {
+		t.Errorf("wrong line %d; want 9", got)
+	}
+}
@@ -794,12 +794,21 @@ func decodeReplaceTriggeredBy(expr hcl.Expression) ([]hcl.Expression, hcl.Diagno
 	// will be strings rather than hcl. To simplify parsing however we will
 	// decode the individual list elements, rather than the entire expression.
 	isJSON := hcljson.IsJSONExpression(expr)
-	isYAML := yamlbody.IsYAMLExpression(expr)
 
 	exprs, diags := hcl.ExprList(expr)
 
 	for i, expr := range exprs {
-		if isJSON || isYAML {
+		// The elements of a YAML sequence may each be given as a string or
+		// using an !expr or !ref tag, so we must check them separately.
+		var unwrapDiags hcl.Diagnostics
+		expr, unwrapDiags = yamlbody.UnwrapNativeSyntax(expr)
+		diags = diags.Extend(unwrapDiags)
+		if unwrapDiags.HasErrors() {
+			continue
+		}
+		exprs[i] = expr
+
+		if isJSON || yamlbody.IsYAMLExpression(expr) {
 			var convertDiags hcl.Diagnostics
 			expr, convertDiags = hcl2shim.ConvertJSONExpressionToHCL(expr)
 			diags = diags.Extend(convertDiags)
@@ -921,6 +930,12 @@ func decodeProviderConfigRef(expr hcl.Expression, argName string) (*ProviderConf
 			return nil, diags
 		}
 	}
+	var unwrapDiags hcl.Diagnostics
+	expr, unwrapDiags = yamlbody.UnwrapNativeSyntax(expr)
+	diags = append(diags, unwrapDiags...)
+	if diags.HasErrors() {
+		return nil, diags
+	}
 
 	// name.alias[expr_key]
 	if iex, ok := expr.(*hclsyntax.IndexExpr); ok {
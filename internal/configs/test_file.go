@@ -7,11 +7,17 @@ package configs
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/spf13/afero"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/gocty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/getmodules"
@@ -126,6 +132,20 @@ func (file *TestFile) getTestProviderOrMock(addr string) (*Provider, bool) {
 type TestRun struct {
 	Name string
 
+	// BlockName is the label of the run block. It is the same as Name, unless
+	// the run block was expanded by ForEach, in which case Name also includes
+	// the key of the element the run was expanded for, like "name[key]".
+	BlockName string
+
+	// ForEach is the expression that expanded the run block into a run for
+	// each of its elements, or nil if the run block was not expanded.
+	//
+	// The expression must evaluate to a map or a list of objects, and is
+	// evaluated when the test file is loaded so it can only refer to
+	// functions. The attributes of each object set variables for its run,
+	// taking precedence over the run block's own variables block.
+	ForEach hcl.Expression
+
 	// Command is the OpenTofu command to execute.
 	//
 	// One of ['apply', 'plan'].
@@ -179,6 +199,12 @@ type TestRun struct {
 	// run.
 	ExpectFailures []hcl.Traversal
 
+	// ExpectDiagnostics is a list of diagnostics that are expected to be
+	// reported by the command executed by this run block. Unlike
+	// ExpectFailures, these can describe the text and severity of the
+	// diagnostic, and so can also expect warnings.
+	ExpectDiagnostics []*TestRunExpectedDiagnostic
+
 	// OverrideResources is a list of resources to be overridden with static values.
 	// Underlying providers shouldn't be called for overridden resources.
 	OverrideResources []*OverrideResource
@@ -187,7 +213,12 @@ type TestRun struct {
 	// Underlying modules shouldn't be called.
 	OverrideModules []*OverrideModule
 
+	// Snapshot indicates that the plan created by this run block should be
+	// compared against a stored snapshot of the expected plan.
+	Snapshot bool
+
 	NameDeclRange      hcl.Range
+	SnapshotDeclRange  hcl.Range
 	VariablesDeclRange hcl.Range
 	DeclRange          hcl.Range
 }
@@ -222,6 +253,31 @@ func (run *TestRun) Validate() tfdiags.Diagnostics {
 
 	}
 
+	// The same goes for the objects referenced by expect_diagnostic blocks.
+	for _, expected := range run.ExpectDiagnostics {
+		if expected.Object == nil {
+			continue
+		}
+
+		reference, refDiags := addrs.ParseRefFromTestingScope(expected.Object)
+		diags = diags.Append(refDiags)
+		if refDiags.HasErrors() {
+			continue
+		}
+
+		switch reference.Subject.(type) {
+		case addrs.OutputValue, addrs.InputVariable, addrs.Check, addrs.ResourceInstance, addrs.Resource:
+			// Do nothing, these are okay!
+		default:
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid `expect_diagnostic` object",
+				Detail:   fmt.Sprintf("You cannot expect diagnostics from %s. You can only expect diagnostics from checkable objects such as input variables, output values, check blocks, managed resources and data sources.", reference.Subject.String()),
+				Subject:  reference.SourceRange.ToHCL().Ptr(),
+			})
+		}
+	}
+
 	// It's not allowed to have multiple `override_resource`, `override_data` or `override_module` blocks
 	// inside a single run block with the same target address so we want to ensure there's no such cases.
 	diags = diags.Append(checkForDuplicatedOverrideResources(run.OverrideResources))
@@ -260,6 +316,36 @@ type TestRunOptions struct {
 	DeclRange hcl.Range
 }
 
+// TestRunExpectedDiagnostic describes a diagnostic that a run block expects
+// the command it executes to report.
+//
+// A diagnostic matches if it has the expected severity and satisfies all of
+// the other fields that are set. A run block fails if any of its expected
+// diagnostics is not matched by at least one diagnostic.
+type TestRunExpectedDiagnostic struct {
+	// Severity is the expected severity of the diagnostic. Defaults to error.
+	//
+	// Failed assertions within check blocks are treated as errors, as they
+	// are elsewhere in the testing framework.
+	Severity tfdiags.Severity
+
+	// Object optionally references the checkable object whose custom
+	// conditions should report the diagnostic.
+	Object hcl.Traversal
+
+	// Summary and Detail are the exact summary and detail of the diagnostic,
+	// if set.
+	Summary string
+	Detail  string
+
+	// SummaryRegex and DetailRegex are regular expressions that must match
+	// the summary and detail of the diagnostic, if set.
+	SummaryRegex *regexp.Regexp
+	DetailRegex  *regexp.Regexp
+
+	DeclRange hcl.Range
+}
+
 const (
 	blockNameOverrideResource = "override_resource"
 	blockNameOverrideData     = "override_data"
@@ -417,7 +503,10 @@ func (r MockResource) getBlockName() string {
 	}
 }
 
-func loadTestFile(body hcl.Body) (*TestFile, hcl.Diagnostics) {
+// loadTestFile decodes the given body of a test file. The functions called
+// by the for_each arguments of its run blocks read files from the given
+// filesystem, relative to the given directory containing the test file.
+func loadTestFile(body hcl.Body, fs afero.Fs, baseDir string) (*TestFile, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 
 	content, contentDiags := body.Content(testFileSchema)
@@ -433,9 +522,17 @@ func loadTestFile(body hcl.Body) (*TestFile, hcl.Diagnostics) {
 		case "run":
 			run, runDiags := decodeTestRunBlock(block)
 			diags = append(diags, runDiags...)
-			if !runDiags.HasErrors() {
-				tf.Runs = append(tf.Runs, run)
+			if runDiags.HasErrors() {
+				continue
+			}
+
+			if run.ForEach != nil {
+				runs, expandDiags := expandTestRun(run, testRunForEachFunctions(fs, baseDir))
+				diags = append(diags, expandDiags...)
+				tf.Runs = append(tf.Runs, runs...)
+				continue
 			}
+			tf.Runs = append(tf.Runs, run)
 
 		case "variables":
 			if tf.Variables != nil {
@@ -510,6 +607,7 @@ func decodeTestRunBlock(block *hcl.Block) (*TestRun, hcl.Diagnostics) {
 
 	r := TestRun{
 		Name:          block.Labels[0],
+		BlockName:     block.Labels[0],
 		NameDeclRange: block.LabelRanges[0],
 		DeclRange:     block.DefRange,
 	}
@@ -582,6 +680,13 @@ func decodeTestRunBlock(block *hcl.Block) (*TestRun, hcl.Diagnostics) {
 				r.Module = module
 			}
 
+		case "expect_diagnostic":
+			expected, expectedDiags := decodeTestRunExpectedDiagnosticBlock(block)
+			diags = append(diags, expectedDiags...)
+			if !expectedDiags.HasErrors() {
+				r.ExpectDiagnostics = append(r.ExpectDiagnostics, expected)
+			}
+
 		case blockNameOverrideResource, blockNameOverrideData:
 			overrideRes, overrideResDiags := decodeOverrideResourceBlock(block)
 			diags = append(diags, overrideResDiags...)
@@ -644,9 +749,101 @@ func decodeTestRunBlock(block *hcl.Block) (*TestRun, hcl.Diagnostics) {
 		r.ExpectFailures = failures
 	}
 
+	if attr, exists := content.Attributes["for_each"]; exists {
+		r.ForEach = attr.Expr
+	}
+
+	if attr, exists := content.Attributes["snapshot"]; exists {
+		diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &r.Snapshot)...)
+		r.SnapshotDeclRange = attr.Range
+	}
+
 	return &r, diags
 }
 
+// expandTestRun evaluates the for_each expression of the given run block and
+// returns a copy of the run block for each of its elements.
+func expandTestRun(run *TestRun, functions map[string]function.Function) ([]*TestRun, hcl.Diagnostics) {
+	// The for_each expression is evaluated before any variables are known, so
+	// it can only call functions, like csvdecode(file("cases.csv")).
+	val, diags := run.ForEach.Value(&hcl.EvalContext{
+		Functions: functions,
+	})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	ty := val.Type()
+	if val.IsNull() || !val.IsWhollyKnown() || !(ty.IsMapType() || ty.IsObjectType() || ty.IsListType() || ty.IsTupleType()) {
+		return nil, append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid for_each argument",
+			Detail:   "The for_each argument of a run block must be a known map or list of objects, where the attributes of each object set the variables for one run.",
+			Subject:  run.ForEach.Range().Ptr(),
+		})
+	}
+
+	var runs []*TestRun
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+
+		var key string
+		if k.Type() == cty.String {
+			key = k.AsString()
+		} else {
+			var ix int64
+			if err := gocty.FromCtyValue(k, &ix); err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid for_each argument",
+					Detail:   fmt.Sprintf("The for_each argument has an invalid element index: %s.", err),
+					Subject:  run.ForEach.Range().Ptr(),
+				})
+				continue
+			}
+			key = strconv.FormatInt(ix, 10)
+		}
+
+		if !validTestRunKey(key) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid for_each argument",
+				Detail:   fmt.Sprintf("The for_each key %q can't be used in a run name. Run names are also used as file names for plan snapshots and recorded provider interactions, so keys must not be empty, \".\" or \"..\", and must not contain slashes.", key),
+				Subject:  run.ForEach.Range().Ptr(),
+			})
+			continue
+		}
+
+		if v.IsNull() || !(v.Type().IsObjectType() || v.Type().IsMapType()) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid for_each argument",
+				Detail:   fmt.Sprintf("The for_each element %q must be an object, where the attributes set the variables for its run.", key),
+				Subject:  run.ForEach.Range().Ptr(),
+			})
+			continue
+		}
+
+		expanded := *run
+		expanded.Name = fmt.Sprintf("%s[%s]", run.BlockName, key)
+		expanded.Variables = make(map[string]hcl.Expression, len(run.Variables))
+		for name, expr := range run.Variables {
+			expanded.Variables[name] = expr
+		}
+		for name, value := range v.AsValueMap() {
+			expanded.Variables[name] = hcl.StaticExpr(value, run.ForEach.Range())
+		}
+		runs = append(runs, &expanded)
+	}
+	return runs, diags
+}
+
+// validTestRunKey returns true if the given for_each key can be used in the
+// name of a run block, which must be usable as a single path segment.
+func validTestRunKey(key string) bool {
+	return key != "" && key != "." && key != ".." && !strings.ContainsAny(key, `/\`)
+}
+
 func decodeTestRunModuleBlock(block *hcl.Block) (*TestRunModuleCall, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 
@@ -798,6 +995,85 @@ func decodeTestRunOptionsBlock(block *hcl.Block) (*TestRunOptions, hcl.Diagnosti
 	return &opts, diags
 }
 
+func decodeTestRunExpectedDiagnosticBlock(block *hcl.Block) (*TestRunExpectedDiagnostic, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	content, contentDiags := block.Body.Content(testRunExpectedDiagnosticBlockSchema)
+	diags = append(diags, contentDiags...)
+
+	expected := TestRunExpectedDiagnostic{
+		Severity:  tfdiags.Error,
+		DeclRange: block.DefRange,
+	}
+
+	if attr, exists := content.Attributes["severity"]; exists {
+		switch hcl.ExprAsKeyword(attr.Expr) {
+		case "error":
+			expected.Severity = tfdiags.Error
+		case "warning":
+			expected.Severity = tfdiags.Warning
+		default:
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid \"severity\" keyword",
+				Detail:   "The \"severity\" argument requires one of the following keywords without quotes: error or warning.",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+
+	if attr, exists := content.Attributes["object"]; exists {
+		traversal, travDiags := hcl.AbsTraversalForExpr(attr.Expr)
+		diags = append(diags, travDiags...)
+		if !travDiags.HasErrors() {
+			expected.Object = traversal
+		}
+	}
+
+	decodeText := func(exact, regex string, text *string, re **regexp.Regexp) {
+		exactAttr, exactExists := content.Attributes[exact]
+		if exactExists {
+			diags = append(diags, gohcl.DecodeExpression(exactAttr.Expr, nil, text)...)
+		}
+
+		regexAttr, regexExists := content.Attributes[regex]
+		if !regexExists {
+			return
+		}
+		if exactExists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Conflicting expected diagnostic arguments",
+				Detail:   fmt.Sprintf("Only one of the %q and %q arguments can be set.", exact, regex),
+				Subject:  regexAttr.Range.Ptr(),
+			})
+			return
+		}
+
+		var raw string
+		rawDiags := gohcl.DecodeExpression(regexAttr.Expr, nil, &raw)
+		diags = append(diags, rawDiags...)
+		if rawDiags.HasErrors() {
+			return
+		}
+		compiled, err := regexp.Compile(raw)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid regular expression",
+				Detail:   fmt.Sprintf("The %q argument must be a valid regular expression: %s.", regex, err),
+				Subject:  regexAttr.Expr.Range().Ptr(),
+			})
+			return
+		}
+		*re = compiled
+	}
+	decodeText("summary", "summary_regex", &expected.Summary, &expected.SummaryRegex)
+	decodeText("detail", "detail_regex", &expected.Detail, &expected.DetailRegex)
+
+	return &expected, diags
+}
+
 func decodeOverrideResourceBlock(block *hcl.Block) (*OverrideResource, hcl.Diagnostics) {
 	parseTarget := func(attr *hcl.Attribute) (hcl.Traversal, *addrs.ConfigResource, hcl.Diagnostics) {
 		traversal, traversalDiags := hcl.AbsTraversalForExpr(attr.Expr)
@@ -1088,6 +1364,10 @@ var testRunBlockSchema = &hcl.BodySchema{
 		{Name: "providers"},
 		// expect_failures indicates whether test failures are expected.
 		{Name: "expect_failures"},
+		// snapshot indicates whether the plan should be compared against a stored snapshot.
+		{Name: "snapshot"},
+		// for_each expands the run block into a run for each element of a map or list.
+		{Name: "for_each"},
 	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{
@@ -1106,6 +1386,10 @@ var testRunBlockSchema = &hcl.BodySchema{
 			// module block specifies the module to be tested.
 			Type: "module",
 		},
+		{
+			// expect_diagnostic block describes a diagnostic the test expects to be reported.
+			Type: "expect_diagnostic",
+		},
 		{
 			Type: blockNameOverrideResource,
 		},
@@ -1133,6 +1417,23 @@ var testRunOptionsBlockSchema = &hcl.BodySchema{
 	},
 }
 
+// testRunExpectedDiagnosticBlockSchema defines the structure of the
+// expect_diagnostic block within a test run.
+var testRunExpectedDiagnosticBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		// severity is the expected severity of the diagnostic, error or warning.
+		{Name: "severity"},
+		// object references the checkable object expected to report the diagnostic.
+		{Name: "object"},
+		// summary and detail are the exact text expected in the diagnostic.
+		{Name: "summary"},
+		{Name: "detail"},
+		// summary_regex and detail_regex are regular expressions the diagnostic text must match.
+		{Name: "summary_regex"},
+		{Name: "detail_regex"},
+	},
+}
+
 // testRunModuleBlockSchema defines the structure of the module block within a test run,
 // including attributes for the module's source and version.
 var testRunModuleBlockSchema = &hcl.BodySchema{
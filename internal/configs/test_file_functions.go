@@ -0,0 +1,104 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/afero"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/opentofu/opentofu/internal/lang"
+)
+
+// testRunForEachFunctions returns the functions that can be called from the
+// for_each argument of a run block in a test file within the given directory.
+//
+// The file and fileexists functions read from the given filesystem, relative
+// to the directory of the test file. The other functions that read files
+// can only read from the real filesystem, so they are not available.
+func testRunForEachFunctions(fs afero.Fs, baseDir string) map[string]function.Function {
+	scope := &lang.Scope{BaseDir: baseDir}
+
+	ret := make(map[string]function.Function)
+	for name, fn := range scope.Functions() {
+		switch name := strings.TrimPrefix(name, "core::"); {
+		case strings.HasPrefix(name, "file"), name == "templatefile":
+			continue
+		}
+		ret[name] = fn
+	}
+
+	file := makeTestFileFunc(fs, baseDir)
+	fileExists := makeTestFileExistsFunc(fs, baseDir)
+	ret["file"], ret["core::file"] = file, file
+	ret["fileexists"], ret["core::fileexists"] = fileExists, fileExists
+	return ret
+}
+
+func makeTestFileFunc(fs afero.Fs, baseDir string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path := testFunctionPath(baseDir, args[0].AsString())
+			src, err := afero.ReadFile(fs, path)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return cty.UnknownVal(cty.String), function.NewArgErrorf(0, "no file exists at %s", path)
+				}
+				return cty.UnknownVal(cty.String), function.NewArgError(0, err)
+			}
+			if !utf8.Valid(src) {
+				return cty.UnknownVal(cty.String), function.NewArgErrorf(0, "contents of %s are not valid UTF-8", path)
+			}
+			return cty.StringVal(string(src)), nil
+		},
+	})
+}
+
+func makeTestFileExistsFunc(fs afero.Fs, baseDir string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.Bool),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path := testFunctionPath(baseDir, args[0].AsString())
+			fi, err := fs.Stat(path)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return cty.False, nil
+				}
+				return cty.UnknownVal(cty.Bool), fmt.Errorf("failed to stat %s", path)
+			}
+			if !fi.Mode().IsRegular() {
+				return cty.UnknownVal(cty.Bool), function.NewArgErrorf(0, "%s is not a regular file", path)
+			}
+			return cty.True, nil
+		},
+	})
+}
+
+// testFunctionPath returns the given path relative to the given base
+// directory, unless it is already absolute.
+func testFunctionPath(baseDir, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	return filepath.Clean(path)
+}
@@ -13,6 +13,11 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hcltest"
+	"github.com/spf13/afero"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
 func TestTestRun_Validate(t *testing.T) {
@@ -178,3 +183,330 @@ func TestDecodeTestRunModuleBlock(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeTestRunBlock_snapshot(t *testing.T) {
+	tcs := map[string]struct {
+		src           string
+		want          bool
+		expectedDiags hcl.Diagnostics
+	}{
+		"default": {
+			src:  `run "test" {}`,
+			want: false,
+		},
+		"enabled": {
+			src:  "run \"test\" {\n  snapshot = true\n}",
+			want: true,
+		},
+		"invalid": {
+			src: "run \"test\" {\n  snapshot = \"yes please\"\n}",
+			expectedDiags: hcl.Diagnostics{
+				{
+					Summary: "Unsuitable value type",
+				},
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(tc.src), "main.tftest.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			content, diags := file.Body.Content(testFileSchema)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			run, diags := decodeTestRunBlock(content.Blocks[0])
+			if tc.expectedDiags != nil || diags != nil {
+				assertDiagsSummaryMatch(t, tc.expectedDiags, diags)
+				return
+			}
+
+			if run.Snapshot != tc.want {
+				t.Errorf("got snapshot %t; want %t", run.Snapshot, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecodeTestRunBlock_expectDiagnostic(t *testing.T) {
+	type expected struct {
+		Severity     tfdiags.Severity
+		Object       string
+		Summary      string
+		Detail       string
+		SummaryRegex string
+		DetailRegex  string
+	}
+
+	tcs := map[string]struct {
+		src           string
+		want          []expected
+		expectedDiags hcl.Diagnostics
+	}{
+		"defaults": {
+			src: `
+run "test" {
+  expect_diagnostic {}
+}`,
+			want: []expected{
+				{Severity: tfdiags.Error},
+			},
+		},
+		"all arguments": {
+			src: `
+run "test" {
+  expect_diagnostic {
+    severity      = warning
+    object        = var.input
+    summary       = "Deprecated variable"
+    detail_regex  = "^The variable .* is deprecated"
+  }
+
+  expect_diagnostic {
+    summary_regex = "Invalid value"
+    detail        = "Bad input."
+  }
+}`,
+			want: []expected{
+				{
+					Severity:    tfdiags.Warning,
+					Object:      "var.input",
+					Summary:     "Deprecated variable",
+					DetailRegex: "^The variable .* is deprecated",
+				},
+				{
+					Severity:     tfdiags.Error,
+					Detail:       "Bad input.",
+					SummaryRegex: "Invalid value",
+				},
+			},
+		},
+		"invalid severity": {
+			src: `
+run "test" {
+  expect_diagnostic {
+    severity = fatal
+  }
+}`,
+			expectedDiags: hcl.Diagnostics{
+				{
+					Summary: "Invalid \"severity\" keyword",
+				},
+			},
+		},
+		"invalid regex": {
+			src: `
+run "test" {
+  expect_diagnostic {
+    summary_regex = "(unclosed"
+  }
+}`,
+			expectedDiags: hcl.Diagnostics{
+				{
+					Summary: "Invalid regular expression",
+				},
+			},
+		},
+		"conflicting arguments": {
+			src: `
+run "test" {
+  expect_diagnostic {
+    detail       = "exact"
+    detail_regex = "regex"
+  }
+}`,
+			expectedDiags: hcl.Diagnostics{
+				{
+					Summary: "Conflicting expected diagnostic arguments",
+				},
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(tc.src), "main.tftest.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			content, diags := file.Body.Content(testFileSchema)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			run, diags := decodeTestRunBlock(content.Blocks[0])
+			if tc.expectedDiags != nil || diags != nil {
+				assertDiagsSummaryMatch(t, tc.expectedDiags, diags)
+				return
+			}
+
+			var got []expected
+			for _, e := range run.ExpectDiagnostics {
+				g := expected{
+					Severity: e.Severity,
+					Summary:  e.Summary,
+					Detail:   e.Detail,
+				}
+				if e.Object != nil {
+					ref, refDiags := addrs.ParseRefFromTestingScope(e.Object)
+					if refDiags.HasErrors() {
+						t.Fatal(refDiags.Err())
+					}
+					g.Object = ref.Subject.String()
+				}
+				if e.SummaryRegex != nil {
+					g.SummaryRegex = e.SummaryRegex.String()
+				}
+				if e.DetailRegex != nil {
+					g.DetailRegex = e.DetailRegex.String()
+				}
+				got = append(got, g)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("wrong expected diagnostics\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadTestFile_forEach(t *testing.T) {
+	src := `
+run "cases" {
+  for_each = {
+    small = { size = 1 }
+    large = { size = 100, name = "override" }
+  }
+
+  variables {
+    name = "default"
+  }
+}
+
+run "rows" {
+  for_each = csvdecode("size\n1\n2\n")
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "main.tftest.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	tf, diags := loadTestFile(file.Body, afero.NewMemMapFs(), ".")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	type result struct {
+		Name      string
+		BlockName string
+		Variables map[string]string
+	}
+	var got []result
+	for _, run := range tf.Runs {
+		r := result{Name: run.Name, BlockName: run.BlockName, Variables: make(map[string]string)}
+		for name, expr := range run.Variables {
+			val, diags := expr.Value(nil)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			if val.Type() == cty.String {
+				r.Variables[name] = val.AsString()
+			} else {
+				r.Variables[name] = val.AsBigFloat().String()
+			}
+		}
+		got = append(got, r)
+	}
+
+	want := []result{
+		{Name: "cases[large]", BlockName: "cases", Variables: map[string]string{"name": "override", "size": "100"}},
+		{Name: "cases[small]", BlockName: "cases", Variables: map[string]string{"name": "default", "size": "1"}},
+		{Name: "rows[0]", BlockName: "rows", Variables: map[string]string{"size": "1"}},
+		{Name: "rows[1]", BlockName: "rows", Variables: map[string]string{"size": "2"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong runs\n%s", diff)
+	}
+}
+
+func TestParserLoadTestFile_forEachFiles(t *testing.T) {
+	parser := testParser(map[string]string{
+		"tests/main.tftest.hcl": `
+run "rows" {
+  for_each = fileexists("missing.csv") ? [] : csvdecode(file("cases.csv"))
+}
+`,
+		"tests/cases.csv": "size\n1\n2\n",
+	})
+
+	tf, diags := parser.LoadTestFile("tests/main.tftest.hcl")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	var got []string
+	for _, run := range tf.Runs {
+		got = append(got, run.Name)
+	}
+	if diff := cmp.Diff([]string{"rows[0]", "rows[1]"}, got); diff != "" {
+		t.Errorf("wrong runs\n%s", diff)
+	}
+}
+
+func TestLoadTestFile_forEachInvalid(t *testing.T) {
+	tcs := map[string]string{
+		"not a collection": `run "cases" {
+  for_each = "nope"
+}`,
+		"not an object": `run "cases" {
+  for_each = ["a", "b"]
+}`,
+		"refers to a variable": `run "cases" {
+  for_each = var.cases
+}`,
+		"reads an unavailable file": `run "cases" {
+  for_each = csvdecode(file("cases.csv"))
+}`,
+		"calls an unavailable function": `run "cases" {
+  for_each = jsondecode(templatefile("cases.json.tftpl", {}))
+}`,
+		"key with a slash": `run "cases" {
+  for_each = {
+    "nested/case" = {}
+  }
+}`,
+		"key with a backslash": `run "cases" {
+  for_each = {
+    "nested\\case" = {}
+  }
+}`,
+		"key that leaves the directory": `run "cases" {
+  for_each = {
+    ".." = {}
+  }
+}`,
+		"empty key": `run "cases" {
+  for_each = {
+    "" = {}
+  }
+}`,
+	}
+	for name, src := range tcs {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(src), "main.tftest.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			tf, diags := loadTestFile(file.Body, afero.NewMemMapFs(), ".")
+			if !diags.HasErrors() {
+				t.Fatalf("expected errors, got none")
+			}
+			if len(tf.Runs) != 0 {
+				t.Errorf("expected no runs, got %d", len(tf.Runs))
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ package yamlbody
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"gopkg.in/yaml.v3"
@@ -19,7 +20,8 @@ import (
 // Like JSON expressions, YAML expressions have special behavior where
 // string values can be interpreted as HCL template syntax when evaluated
 // with an EvalContext, or returned as literal strings when evaluated with
-// nil EvalContext.
+// nil EvalContext. Scalars tagged with !expr or !ref are the exception, as
+// described for UnwrapNativeSyntax.
 func IsYAMLExpression(maybeYAMLExpr hcl.Expression) bool {
 	_, ok := maybeYAMLExpr.(*expression)
 	return ok
@@ -32,12 +34,47 @@ func IsYAMLBody(maybeYAMLBody hcl.Body) bool {
 	return ok
 }
 
+// HeadComment returns the text of the YAML comment written immediately
+// before the block that the given body belongs to, with the comment markers
+// removed. For a block with labels this is the comment before its last
+// label, and for a block in a sequence it is the comment before the
+// sequence item, if there is one.
+//
+// The result is an empty string if there is no such comment or if the body
+// did not originate from YAML.
+func HeadComment(maybeYAMLBody hcl.Body) string {
+	b, ok := maybeYAMLBody.(*body)
+	if !ok {
+		return ""
+	}
+	return commentText(b.headComment)
+}
+
+// commentText removes the "#" markers from the raw text of a YAML comment,
+// along with a single space following each, if present.
+func commentText(raw string) string {
+	lines := strings.Split(strings.TrimSpace(raw), "\n")
+	for i, line := range lines {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "#")
+		lines[i] = strings.TrimPrefix(line, " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
 // body implements hcl.Body backed by a yaml.Node.
 type body struct {
 	node     *yaml.Node
 	filename string
 	src      []byte
 
+	// document is the 1-based index of the YAML document containing this
+	// body, or zero if the source has only one document.
+	document int
+
+	// headComment is the raw text of the YAML comment immediately
+	// preceding the block that this body belongs to, if any.
+	headComment string
+
 	// hiddenAttrs tracks attributes that have already been consumed
 	// by PartialContent calls, so they won't appear in subsequent calls.
 	hiddenAttrs map[string]struct{}
@@ -73,8 +110,9 @@ func (b *body) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostic
 		nameSuggestions = append(nameSuggestions, blockS.Type)
 	}
 
-	// Find any attributes that weren't in the schema
-	attrs := b.collectAttrs()
+	// Find any attributes that weren't in the schema. Any problems with
+	// merge keys were already reported by PartialContent above.
+	attrs, _ := b.collectAttrs()
 	for _, attr := range attrs {
 		name := attr.keyNode.Value
 		if name == "//" {
@@ -97,7 +135,7 @@ func (b *body) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostic
 		}
 	}
 
-	return content, diags
+	return content, inDocument(diags, b.document)
 }
 
 // PartialContent implements hcl.Body.
@@ -128,7 +166,8 @@ func (b *body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 	}
 
 	// Process YAML attributes
-	attrs := b.collectAttrs()
+	attrs, attrDiags := b.collectAttrs()
+	diags = append(diags, attrDiags...)
 	for _, attr := range attrs {
 		attrName := attr.keyNode.Value
 		if _, used := b.hiddenAttrs[attrName]; used {
@@ -153,7 +192,7 @@ func (b *body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 
 			content.Attributes[attrS.Name] = &hcl.Attribute{
 				Name:      attrS.Name,
-				Expr:      &expression{src: attr.valNode, filename: b.filename, srcBytes: b.src},
+				Expr:      &expression{src: attr.valNode, filename: b.filename, srcBytes: b.src, document: b.document},
 				Range:     attrRange,
 				NameRange: keyRange,
 			}
@@ -162,7 +201,7 @@ func (b *body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 		} else if blockS, defined := blockSchemas[attrName]; defined {
 			// This is a block
 			keyRange := nodeRange(attr.keyNode, b.filename, b.src)
-			blockDiags := b.unpackBlock(attr.valNode, blockS.Type, &keyRange, blockS.LabelNames, nil, nil, &content.Blocks)
+			blockDiags := b.unpackBlock(attr.valNode, blockS.Type, &keyRange, blockS.LabelNames, nil, nil, attr.keyNode.HeadComment, &content.Blocks)
 			diags = append(diags, blockDiags...)
 			usedNames[attrName] = struct{}{}
 		}
@@ -188,10 +227,12 @@ func (b *body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 		node:        b.node,
 		filename:    b.filename,
 		src:         b.src,
+		document:    b.document,
+		headComment: b.headComment,
 		hiddenAttrs: usedNames,
 	}
 
-	return content, unusedBody, diags
+	return content, unusedBody, inDocument(diags, b.document)
 }
 
 // JustAttributes implements hcl.Body.
@@ -209,10 +250,11 @@ func (b *body) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
 				Subject:  &startRange,
 			})
 		}
-		return attrs, diags
+		return attrs, inDocument(diags, b.document)
 	}
 
-	yamlAttrs := b.collectAttrs()
+	yamlAttrs, attrDiags := b.collectAttrs()
+	diags = append(diags, attrDiags...)
 	for _, attr := range yamlAttrs {
 		name := attr.keyNode.Value
 		if name == "//" {
@@ -238,13 +280,13 @@ func (b *body) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
 
 		attrs[name] = &hcl.Attribute{
 			Name:      name,
-			Expr:      &expression{src: attr.valNode, filename: b.filename, srcBytes: b.src},
+			Expr:      &expression{src: attr.valNode, filename: b.filename, srcBytes: b.src, document: b.document},
 			Range:     attrRange,
 			NameRange: keyRange,
 		}
 	}
 
-	return attrs, diags
+	return attrs, inDocument(diags, b.document)
 }
 
 // MissingItemRange implements hcl.Body.
@@ -271,29 +313,29 @@ type yamlAttr struct {
 	valNode *yaml.Node
 }
 
-// collectAttrs extracts key-value pairs from a mapping node.
-func (b *body) collectAttrs() []yamlAttr {
+// collectAttrs extracts key-value pairs from a mapping node, expanding
+// any merge keys ("<<") into the pairs of the mappings they refer to.
+func (b *body) collectAttrs() ([]yamlAttr, hcl.Diagnostics) {
 	if b.node == nil || b.node.Kind != yaml.MappingNode {
-		return nil
+		return nil, nil
 	}
 
-	var attrs []yamlAttr
-	// YAML mapping Content is [key, val, key, val, ...]
-	for i := 0; i+1 < len(b.node.Content); i += 2 {
-		attrs = append(attrs, yamlAttr{
-			keyNode: b.node.Content[i],
-			valNode: b.node.Content[i+1],
-		})
-	}
-	return attrs
+	return mappingPairs(b.node, b.filename, b.src)
 }
 
 // unpackBlock recursively extracts block structures from YAML.
 // YAML blocks are represented as nested mappings where each level
 // of nesting corresponds to a label.
-func (b *body) unpackBlock(v *yaml.Node, typeName string, typeRange *hcl.Range, labelsLeft []string, labelsUsed []string, labelRanges []hcl.Range, blocks *hcl.Blocks) hcl.Diagnostics {
+//
+// comment is the head comment of the innermost mapping key seen so far,
+// which becomes the head comment of the resulting blocks.
+func (b *body) unpackBlock(v *yaml.Node, typeName string, typeRange *hcl.Range, labelsLeft []string, labelsUsed []string, labelRanges []hcl.Range, comment string, blocks *hcl.Blocks) hcl.Diagnostics {
 	var diags hcl.Diagnostics
 
+	// Block content and label mappings can be shared between blocks
+	// using anchors and aliases.
+	v = ResolveAlias(v)
+
 	if len(labelsLeft) > 0 {
 		// We still have labels to extract
 		labelName := labelsLeft[0]
@@ -310,14 +352,16 @@ func (b *body) unpackBlock(v *yaml.Node, typeName string, typeRange *hcl.Range,
 		}
 
 		// Each key in this mapping is a label value
-		for i := 0; i+1 < len(v.Content); i += 2 {
-			keyNode := v.Content[i]
-			valNode := v.Content[i+1]
+		pairs, pairDiags := mappingPairs(v, b.filename, b.src)
+		diags = append(diags, pairDiags...)
+		for _, pair := range pairs {
+			keyNode := pair.keyNode
+			valNode := pair.valNode
 
 			newLabelsUsed := append(labelsUsed, keyNode.Value)
 			newLabelRanges := append(labelRanges, nodeRange(keyNode, b.filename, b.src))
 
-			diags = append(diags, b.unpackBlock(valNode, typeName, typeRange, labelsLeft[1:], newLabelsUsed, newLabelRanges, blocks)...)
+			diags = append(diags, b.unpackBlock(valNode, typeName, typeRange, labelsLeft[1:], newLabelsUsed, newLabelRanges, keyNode.HeadComment, blocks)...)
 		}
 		return diags
 	}
@@ -350,7 +394,7 @@ func (b *body) unpackBlock(v *yaml.Node, typeName string, typeRange *hcl.Range,
 		*blocks = append(*blocks, &hcl.Block{
 			Type:        typeName,
 			Labels:      labels,
-			Body:        &body{node: v, filename: b.filename, src: b.src},
+			Body:        &body{node: v, filename: b.filename, src: b.src, document: b.document, headComment: comment},
 			DefRange:    defRange,
 			TypeRange:   *typeRange,
 			LabelRanges: labelR,
@@ -359,11 +403,17 @@ func (b *body) unpackBlock(v *yaml.Node, typeName string, typeRange *hcl.Range,
 	case yaml.SequenceNode:
 		// Multiple block instances
 		for _, item := range v.Content {
+			item = ResolveAlias(item)
 			defRange := nodeRange(item, b.filename, b.src)
+			// A comment on an individual item describes just that block.
+			itemComment := comment
+			if item.HeadComment != "" {
+				itemComment = item.HeadComment
+			}
 			*blocks = append(*blocks, &hcl.Block{
 				Type:        typeName,
 				Labels:      labels,
-				Body:        &body{node: item, filename: b.filename, src: b.src},
+				Body:        &body{node: item, filename: b.filename, src: b.src, document: b.document, headComment: itemComment},
 				DefRange:    defRange,
 				TypeRange:   *typeRange,
 				LabelRanges: labelR,
@@ -6,8 +6,11 @@ package yamlbody
 import (
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
 	"gopkg.in/yaml.v3"
 )
 
@@ -370,3 +373,456 @@ count: 5
 		t.Errorf("expected 2 attributes, got %d", len(attrs))
 	}
 }
+
+func TestBodyMergeKeys(t *testing.T) {
+	src := []byte(`defaults: &defaults
+  ami: ami-12345
+  instance_type: t2.micro
+extra: &extra
+  monitoring: true
+  instance_type: t3.large
+
+single:
+  <<: *defaults
+  instance_type: m5.large
+multiple:
+  <<: [*extra, *defaults]
+`)
+	var root yaml.Node
+	if err := yaml.Unmarshal(src, &root); err != nil {
+		t.Fatal(err)
+	}
+
+	body := NewBody(&root, "test.yaml", src)
+	content, diags := body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "defaults"},
+			{Type: "extra"},
+			{Type: "single"},
+			{Type: "multiple"},
+		},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+
+	blocks := make(map[string]*hcl.Block)
+	for _, block := range content.Blocks {
+		blocks[block.Type] = block
+	}
+
+	t.Run("single alias with local override", func(t *testing.T) {
+		attrs, diags := blocks["single"].Body.JustAttributes()
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %v", diags)
+		}
+		if len(attrs) != 2 {
+			t.Fatalf("expected 2 attributes, got %d", len(attrs))
+		}
+		got, _ := attrs["instance_type"].Expr.Value(nil)
+		if want := cty.StringVal("m5.large"); !got.RawEquals(want) {
+			t.Errorf("wrong instance_type\ngot:  %#v\nwant: %#v", got, want)
+		}
+		// Merged attributes must point back at the anchored mapping.
+		if line := attrs["ami"].NameRange.Start.Line; line != 2 {
+			t.Errorf("expected merged 'ami' on line 2, got line %d", line)
+		}
+	})
+
+	t.Run("sequence of aliases", func(t *testing.T) {
+		attrs, diags := blocks["multiple"].Body.JustAttributes()
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %v", diags)
+		}
+		if len(attrs) != 3 {
+			t.Fatalf("expected 3 attributes, got %d", len(attrs))
+		}
+		// Earlier mappings in the sequence take precedence.
+		got, _ := attrs["instance_type"].Expr.Value(nil)
+		if want := cty.StringVal("t3.large"); !got.RawEquals(want) {
+			t.Errorf("wrong instance_type\ngot:  %#v\nwant: %#v", got, want)
+		}
+		if line := attrs["instance_type"].NameRange.Start.Line; line != 6 {
+			t.Errorf("expected merged 'instance_type' on line 6, got line %d", line)
+		}
+	})
+}
+
+func TestBodyMergeKeysExtraneous(t *testing.T) {
+	src := []byte(`base: &base
+  name: test
+  bogus: true
+resource:
+  <<: *base
+`)
+	var root yaml.Node
+	if err := yaml.Unmarshal(src, &root); err != nil {
+		t.Fatal(err)
+	}
+
+	body := NewBody(&root, "test.yaml", src)
+	attrs, diags := body.JustAttributes()
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+
+	val, _ := attrs["resource"].Expr.Value(nil)
+	want := cty.ObjectVal(map[string]cty.Value{
+		"name":  cty.StringVal("test"),
+		"bogus": cty.True,
+	})
+	if !val.RawEquals(want) {
+		t.Fatalf("wrong value\ngot:  %#v\nwant: %#v", val, want)
+	}
+
+	content, _, _ := NewBody(&root, "test.yaml", src).PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "resource"}},
+	})
+	_, diags = content.Blocks[0].Body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "name"}},
+	})
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d: %v", len(diags), diags)
+	}
+	if got, want := diags[0].Summary, "Extraneous YAML property"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	// The diagnostic should point at the anchored definition, not the merge key.
+	if got, want := diags[0].Subject.Start.Line, 3; got != want {
+		t.Errorf("wrong diagnostic line %d; want %d", got, want)
+	}
+}
+
+func TestBodyMergeKeysInvalid(t *testing.T) {
+	src := []byte(`resource:
+  <<: not-a-mapping
+  name: test
+`)
+	var root yaml.Node
+	if err := yaml.Unmarshal(src, &root); err != nil {
+		t.Fatal(err)
+	}
+
+	body := NewBody(&root, "test.yaml", src)
+	content, _, diags := body.PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "resource"}},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+
+	_, diags = content.Blocks[0].Body.JustAttributes()
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d: %v", len(diags), diags)
+	}
+	if got, want := diags[0].Summary, "Invalid YAML merge key"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+}
+
+func TestExpressionTags(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var": cty.ObjectVal(map[string]cty.Value{
+				"count": cty.NumberIntVal(3),
+				"name":  cty.StringVal("web"),
+			}),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		yaml     string
+		ctx      *hcl.EvalContext
+		expected cty.Value
+		vars     int
+	}{
+		{
+			name:     "expr conditional",
+			yaml:     `value: !expr "var.count > 0 ? 1 : 0"`,
+			ctx:      ctx,
+			expected: cty.NumberIntVal(1),
+			vars:     1,
+		},
+		{
+			name:     "expr for expression",
+			yaml:     `value: !expr "[for i in range(2) : i * var.count]"`,
+			ctx:      &hcl.EvalContext{Variables: ctx.Variables, Functions: map[string]function.Function{"range": stdlib.RangeFunc}},
+			expected: cty.TupleVal([]cty.Value{cty.NumberIntVal(0), cty.NumberIntVal(3)}),
+			vars:     1,
+		},
+		{
+			name:     "expr without context",
+			yaml:     `value: !expr 1 + 1`,
+			expected: cty.NumberIntVal(2),
+		},
+		{
+			name:     "ref",
+			yaml:     `value: !ref var.count`,
+			ctx:      ctx,
+			expected: cty.NumberIntVal(3),
+			vars:     1,
+		},
+		{
+			name:     "tpl forces a template",
+			yaml:     `value: !tpl 12`,
+			ctx:      ctx,
+			expected: cty.StringVal("12"),
+		},
+		{
+			name:     "literal",
+			yaml:     `value: !literal "${var.name}"`,
+			ctx:      ctx,
+			expected: cty.StringVal("${var.name}"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var root yaml.Node
+			if err := yaml.Unmarshal([]byte(tc.yaml), &root); err != nil {
+				t.Fatal(err)
+			}
+
+			body := NewBody(&root, "test.yaml", []byte(tc.yaml))
+			attrs, diags := body.JustAttributes()
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %v", diags)
+			}
+
+			expr := attrs["value"].Expr
+			val, valDiags := expr.Value(tc.ctx)
+			if valDiags.HasErrors() {
+				t.Fatalf("unexpected errors: %v", valDiags)
+			}
+			if !val.RawEquals(tc.expected) {
+				t.Errorf("wrong value\ngot:  %#v\nwant: %#v", val, tc.expected)
+			}
+			if got := len(expr.Variables()); got != tc.vars {
+				t.Errorf("expected %d variables, got %d", tc.vars, got)
+			}
+		})
+	}
+}
+
+func TestExpressionTagsStatic(t *testing.T) {
+	src := []byte(`ref: !ref aws_instance.web.id
+list: !expr "[aws_instance.a, aws_instance.b]"
+items:
+  - !ref aws_instance.c
+  - aws_instance.d
+`)
+	var root yaml.Node
+	if err := yaml.Unmarshal(src, &root); err != nil {
+		t.Fatal(err)
+	}
+
+	body := NewBody(&root, "test.yaml", src)
+	attrs, diags := body.JustAttributes()
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+
+	traversal, diags := hcl.AbsTraversalForExpr(attrs["ref"].Expr)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+	if got, want := len(traversal), 3; got != want {
+		t.Errorf("wrong traversal length %d; want %d", got, want)
+	}
+	// The traversal must point at the value, after the tag.
+	if got, want := traversal.SourceRange().Start.Column, 11; got != want {
+		t.Errorf("wrong traversal start column %d; want %d", got, want)
+	}
+
+	for _, name := range []string{"list", "items"} {
+		exprs, diags := hcl.ExprList(attrs[name].Expr)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors for %s: %v", name, diags)
+		}
+		if len(exprs) != 2 {
+			t.Fatalf("expected 2 expressions for %s, got %d", name, len(exprs))
+		}
+		for _, expr := range exprs {
+			if _, diags := hcl.AbsTraversalForExpr(expr); diags.HasErrors() {
+				t.Errorf("unexpected errors for %s: %v", name, diags)
+			}
+		}
+	}
+}
+
+func TestExpressionTagsInvalid(t *testing.T) {
+	tests := map[string]struct {
+		yaml    string
+		summary string
+	}{
+		"unsupported tag": {
+			yaml:    `value: !exp var.foo`,
+			summary: "Unsupported YAML tag",
+		},
+		"tag on a mapping": {
+			yaml:    "value: !expr\n  foo: bar",
+			summary: "Invalid YAML tag",
+		},
+		"ref that is not a traversal": {
+			yaml:    `value: !ref var.foo + 1`,
+			summary: "Invalid character",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var root yaml.Node
+			if err := yaml.Unmarshal([]byte(tc.yaml), &root); err != nil {
+				t.Fatal(err)
+			}
+
+			body := NewBody(&root, "test.yaml", []byte(tc.yaml))
+			attrs, diags := body.JustAttributes()
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %v", diags)
+			}
+
+			_, diags = attrs["value"].Expr.Value(nil)
+			if !diags.HasErrors() {
+				t.Fatal("expected errors, got none")
+			}
+			if got := diags[0].Summary; got != tc.summary {
+				t.Errorf("wrong summary %q; want %q", got, tc.summary)
+			}
+		})
+	}
+}
+
+func TestPlainScalarText(t *testing.T) {
+	tests := []struct {
+		yaml   string
+		want   string
+		wantOK bool
+	}{
+		{`value: 1.10`, "1.10", true},
+		{`value: 0x1F`, "0x1F", true},
+		{`value: True`, "True", true},
+		{`value: hello`, "", false},
+		{`value: "1.10"`, "", false},
+		{`value: !!float 1.10`, "", false},
+		{`value: !expr 1.10`, "", false},
+		{`value: null`, "", false},
+		{`value: [1]`, "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.yaml, func(t *testing.T) {
+			file, diags := Parse([]byte(tc.yaml), "test.yaml")
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %v", diags)
+			}
+			attrs, diags := file.Body.JustAttributes()
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %v", diags)
+			}
+
+			got, ok := PlainScalarText(attrs["value"].Expr)
+			if got != tc.want || ok != tc.wantOK {
+				t.Errorf("got %q, %t; want %q, %t", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestHeadComment(t *testing.T) {
+	src := []byte(`# Not about any one block
+resource:
+  # Not about any one resource
+  aws_instance:
+    #Without a space
+    #
+    # after a blank line
+    web:
+      # A lifecycle comment
+      lifecycle: {}
+    db:
+      ami: x
+    workers:
+      # The first worker
+      - ami: a
+      - ami: b
+`)
+	file, diags := Parse(src, "test.yaml")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+	content, diags := file.Body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "resource", LabelNames: []string{"type", "name"}}},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+
+	var got []string
+	for _, block := range content.Blocks {
+		got = append(got, block.Labels[1]+": "+HeadComment(block.Body))
+	}
+	want := []string{
+		"web: Without a space\n\nafter a blank line",
+		"db: ",
+		"workers: The first worker",
+		"workers: ",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong comments\n%s", diff)
+	}
+
+	nested, diags := content.Blocks[0].Body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "lifecycle"}},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+	if got, want := HeadComment(nested.Blocks[0].Body), "A lifecycle comment"; got != want {
+		t.Errorf("wrong nested block comment %q; want %q", got, want)
+	}
+
+	if got := HeadComment(hcl.EmptyBody()); got != "" {
+		t.Errorf("unexpected comment %q for a non-YAML body", got)
+	}
+}
+
+func TestParseMultipleDocuments(t *testing.T) {
+	src := []byte("a: 1\n---\nb: 2\n---\na: 3\n")
+
+	_, diags := Parse(src, "test.yaml")
+	if len(diags) != 1 || diags[0].Summary != "Multiple YAML documents" {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if got := diags[0].Subject.Start.Line; got != 2 {
+		t.Errorf("wrong line %d; want 2", got)
+	}
+
+	file, diags := ParseDocuments(src, "test.yaml")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+	attrs, diags := file.Body.JustAttributes()
+	if len(diags) != 1 || diags[0].Summary != "Duplicate argument" {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if _, ok := attrs["b"]; !ok {
+		t.Errorf("missing attribute from the second document")
+	}
+
+	_, diags = file.Body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "a"}},
+	})
+	var details []string
+	for _, diag := range diags {
+		details = append(details, diag.Summary+": "+diag.Detail)
+	}
+	want := []string{
+		"Extraneous YAML property: No argument or block type is named \"b\". Did you mean \"a\"?\n\nThis is in YAML document 2 of the file.",
+		"Duplicate argument: Argument \"a\" was already set at test.yaml:1,1-2\n\nThis is in YAML document 3 of the file.",
+	}
+	if diff := cmp.Diff(want, details); diff != "" {
+		t.Errorf("wrong diagnostics\n%s", diff)
+	}
+}
@@ -20,16 +20,29 @@ type expression struct {
 	src      *yaml.Node
 	filename string
 	srcBytes []byte
+
+	// document is the 1-based index of the YAML document containing this
+	// expression, or zero if the source has only one document.
+	document int
 }
 
 var _ hcl.Expression = (*expression)(nil)
 
 // Value evaluates the expression to produce a cty.Value.
 func (e *expression) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	val, diags := e.value(ctx)
+	return val, inDocument(diags, e.document)
+}
+
+func (e *expression) value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	if e.src == nil {
 		return cty.NullVal(cty.DynamicPseudoType), nil
 	}
 
+	if diags := e.checkTag(); diags.HasErrors() {
+		return cty.DynamicVal, diags
+	}
+
 	switch e.src.Kind {
 	case yaml.ScalarNode:
 		return e.evalScalar(ctx)
@@ -43,7 +56,7 @@ func (e *expression) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	case yaml.AliasNode:
 		// Resolve the alias and evaluate the target
 		if e.src.Alias != nil {
-			return (&expression{src: e.src.Alias, filename: e.filename, srcBytes: e.srcBytes}).Value(ctx)
+			return (&expression{src: e.src.Alias, filename: e.filename, srcBytes: e.srcBytes, document: e.document}).Value(ctx)
 		}
 		return cty.DynamicVal, nil
 
@@ -52,17 +65,45 @@ func (e *expression) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	}
 }
 
+// IsNullScalar returns true if the given scalar node evaluates to null.
+// Apart from the values that YAML itself resolves to null, this includes
+// the strings "null" and "~" even when they are quoted, but not scalars
+// with one of the local tags.
+func IsNullScalar(node *yaml.Node) bool {
+	if IsLocalTag(node.Tag) {
+		return false
+	}
+	return node.Tag == "!!null" || node.Value == "null" || node.Value == "~"
+}
+
 // evalScalar evaluates a scalar YAML node based on its tag.
 func (e *expression) evalScalar(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	node := e.src
 	value := node.Value
 	tag := node.Tag
 
-	// Handle explicit null
-	if tag == "!!null" || value == "null" || value == "~" || value == "" && tag == "" {
-		if tag == "!!null" || value == "null" || value == "~" {
-			return cty.NullVal(cty.DynamicPseudoType), nil
+	switch tag {
+	case ExprTag, RefTag:
+		expr, diags := e.nativeExpr()
+		if diags.HasErrors() {
+			return cty.DynamicVal, diags
+		}
+		val, valDiags := expr.Value(ctx)
+		return val, append(diags, valDiags...)
+
+	case TplTag:
+		if ctx != nil {
+			return e.evalStringTemplate(value, ctx)
 		}
+		return cty.StringVal(value), nil
+
+	case LiteralTag:
+		return cty.StringVal(value), nil
+	}
+
+	// Handle explicit null
+	if IsNullScalar(node) {
+		return cty.NullVal(cty.DynamicPseudoType), nil
 	}
 
 	// Handle booleans
@@ -120,7 +161,7 @@ func (e *expression) evalSequence(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnost
 	vals := make([]cty.Value, 0, len(e.src.Content))
 
 	for _, item := range e.src.Content {
-		val, itemDiags := (&expression{src: item, filename: e.filename, srcBytes: e.srcBytes}).Value(ctx)
+		val, itemDiags := (&expression{src: item, filename: e.filename, srcBytes: e.srcBytes, document: e.document}).Value(ctx)
 		vals = append(vals, val)
 		diags = append(diags, itemDiags...)
 	}
@@ -135,18 +176,19 @@ func (e *expression) evalMapping(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 	attrRanges := make(map[string]hcl.Range)
 	known := true
 
-	// YAML mapping Content is [key, val, key, val, ...]
-	for i := 0; i+1 < len(e.src.Content); i += 2 {
-		keyNode := e.src.Content[i]
-		valNode := e.src.Content[i+1]
+	pairs, pairDiags := mappingPairs(e.src, e.filename, e.srcBytes)
+	diags = append(diags, pairDiags...)
+	for _, pair := range pairs {
+		keyNode := pair.keyNode
+		valNode := pair.valNode
 
 		// Evaluate key - keys can potentially contain interpolation
-		keyExpr := &expression{src: keyNode, filename: e.filename, srcBytes: e.srcBytes}
+		keyExpr := &expression{src: keyNode, filename: e.filename, srcBytes: e.srcBytes, document: e.document}
 		name, nameDiags := keyExpr.Value(ctx)
 		diags = append(diags, nameDiags...)
 
 		// Evaluate value
-		valExpr := &expression{src: valNode, filename: e.filename, srcBytes: e.srcBytes}
+		valExpr := &expression{src: valNode, filename: e.filename, srcBytes: e.srcBytes, document: e.document}
 		val, valDiags := valExpr.Value(ctx)
 		diags = append(diags, valDiags...)
 
@@ -219,6 +261,17 @@ func (e *expression) Variables() []hcl.Traversal {
 
 	switch e.src.Kind {
 	case yaml.ScalarNode:
+		switch e.src.Tag {
+		case ExprTag, RefTag:
+			expr, diags := e.nativeExpr()
+			if diags.HasErrors() {
+				return nil
+			}
+			return expr.Variables()
+		case LiteralTag:
+			return nil
+		}
+
 		// Parse string as HCL template to find variables
 		if e.src.Tag != "!!binary" {
 			srcRange := nodeRange(e.src, e.filename, e.srcBytes)
@@ -234,21 +287,20 @@ func (e *expression) Variables() []hcl.Traversal {
 
 	case yaml.SequenceNode:
 		for _, item := range e.src.Content {
-			vars = append(vars, (&expression{src: item, filename: e.filename, srcBytes: e.srcBytes}).Variables()...)
+			vars = append(vars, (&expression{src: item, filename: e.filename, srcBytes: e.srcBytes, document: e.document}).Variables()...)
 		}
 
 	case yaml.MappingNode:
-		for i := 0; i+1 < len(e.src.Content); i += 2 {
-			keyNode := e.src.Content[i]
-			valNode := e.src.Content[i+1]
+		pairs, _ := mappingPairs(e.src, e.filename, e.srcBytes)
+		for _, pair := range pairs {
 			// Keys can also contain interpolation
-			vars = append(vars, (&expression{src: keyNode, filename: e.filename, srcBytes: e.srcBytes}).Variables()...)
-			vars = append(vars, (&expression{src: valNode, filename: e.filename, srcBytes: e.srcBytes}).Variables()...)
+			vars = append(vars, (&expression{src: pair.keyNode, filename: e.filename, srcBytes: e.srcBytes, document: e.document}).Variables()...)
+			vars = append(vars, (&expression{src: pair.valNode, filename: e.filename, srcBytes: e.srcBytes, document: e.document}).Variables()...)
 		}
 
 	case yaml.AliasNode:
 		if e.src.Alias != nil {
-			vars = append(vars, (&expression{src: e.src.Alias, filename: e.filename, srcBytes: e.srcBytes}).Variables()...)
+			vars = append(vars, (&expression{src: e.src.Alias, filename: e.filename, srcBytes: e.srcBytes, document: e.document}).Variables()...)
 		}
 	}
 
@@ -272,6 +324,18 @@ func (e *expression) AsTraversal() hcl.Traversal {
 		return nil
 	}
 
+	if e.isNativeSyntax() {
+		expr, diags := e.nativeExpr()
+		if diags.HasErrors() {
+			return nil
+		}
+		traversal, diags := hcl.AbsTraversalForExpr(expr)
+		if diags.HasErrors() {
+			return nil
+		}
+		return traversal
+	}
+
 	srcRange := nodeRange(e.src, e.filename, e.srcBytes)
 	traversal, diags := hclsyntax.ParseTraversalAbs(
 		[]byte(e.src.Value),
@@ -287,16 +351,11 @@ func (e *expression) AsTraversal() hcl.Traversal {
 
 // ExprCall attempts to interpret the expression as a static function call.
 func (e *expression) ExprCall() *hcl.StaticCall {
-	if e.src == nil || e.src.Kind != yaml.ScalarNode {
+	if e.src == nil || e.src.Kind != yaml.ScalarNode || e.src.Tag == LiteralTag {
 		return nil
 	}
 
-	srcRange := nodeRange(e.src, e.filename, e.srcBytes)
-	expr, diags := hclsyntax.ParseExpression(
-		[]byte(e.src.Value),
-		e.filename,
-		srcRange.Start,
-	)
+	expr, diags := e.nativeExpr()
 	if diags.HasErrors() {
 		return nil
 	}
@@ -311,31 +370,53 @@ func (e *expression) ExprCall() *hcl.StaticCall {
 
 // ExprList returns sub-expressions if this is a sequence expression.
 func (e *expression) ExprList() []hcl.Expression {
+	if e.isNativeSyntax() {
+		expr, diags := e.nativeExpr()
+		if diags.HasErrors() {
+			return nil
+		}
+		exprs, diags := hcl.ExprList(expr)
+		if diags.HasErrors() {
+			return nil
+		}
+		return exprs
+	}
+
 	if e.src == nil || e.src.Kind != yaml.SequenceNode {
 		return nil
 	}
 
 	ret := make([]hcl.Expression, len(e.src.Content))
 	for i, node := range e.src.Content {
-		ret[i] = &expression{src: node, filename: e.filename, srcBytes: e.srcBytes}
+		ret[i] = &expression{src: node, filename: e.filename, srcBytes: e.srcBytes, document: e.document}
 	}
 	return ret
 }
 
 // ExprMap returns key-value pairs if this is a mapping expression.
 func (e *expression) ExprMap() []hcl.KeyValuePair {
+	if e.isNativeSyntax() {
+		expr, diags := e.nativeExpr()
+		if diags.HasErrors() {
+			return nil
+		}
+		pairs, diags := hcl.ExprMap(expr)
+		if diags.HasErrors() {
+			return nil
+		}
+		return pairs
+	}
+
 	if e.src == nil || e.src.Kind != yaml.MappingNode {
 		return nil
 	}
 
-	numPairs := len(e.src.Content) / 2
-	ret := make([]hcl.KeyValuePair, numPairs)
-	for i := 0; i < numPairs; i++ {
-		keyNode := e.src.Content[i*2]
-		valNode := e.src.Content[i*2+1]
+	pairs, _ := mappingPairs(e.src, e.filename, e.srcBytes)
+	ret := make([]hcl.KeyValuePair, len(pairs))
+	for i, pair := range pairs {
 		ret[i] = hcl.KeyValuePair{
-			Key:   &expression{src: keyNode, filename: e.filename, srcBytes: e.srcBytes},
-			Value: &expression{src: valNode, filename: e.filename, srcBytes: e.srcBytes},
+			Key:   &expression{src: pair.keyNode, filename: e.filename, srcBytes: e.srcBytes, document: e.document},
+			Value: &expression{src: pair.valNode, filename: e.filename, srcBytes: e.srcBytes, document: e.document},
 		}
 	}
 	return ret
@@ -0,0 +1,146 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package yamlbody
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// mergeTag is the resolved tag yaml.v3 assigns to the "<<" merge key.
+const mergeTag = "!!merge"
+
+// isMergeKey returns true if the given mapping key node is a YAML 1.1
+// merge key. A quoted "<<" is an ordinary string key and is not treated
+// as a merge key.
+func isMergeKey(node *yaml.Node) bool {
+	return node != nil && node.Kind == yaml.ScalarNode && node.ShortTag() == mergeTag
+}
+
+// mappingPairs returns the key/value pairs of the given mapping node with
+// any merge keys ("<<") expanded, following https://yaml.org/type/merge.html.
+//
+// Keys defined directly in the mapping always take precedence over merged
+// keys, and when a sequence of mappings is merged the earlier mappings take
+// precedence over the later ones. Merged pairs keep the key and value nodes
+// of the mapping they came from, so any ranges derived from them point back
+// at the anchored definition rather than at the merge key.
+func mappingPairs(node *yaml.Node, filename string, src []byte) ([]yamlAttr, hcl.Diagnostics) {
+	return mappingPairsVisiting(node, filename, src, map[*yaml.Node]struct{}{})
+}
+
+// MappingPairs returns the key and value nodes of the given mapping node
+// with any merge keys expanded in the same way as for the attributes of a
+// body, for callers that work with the YAML node tree directly.
+func MappingPairs(node *yaml.Node, filename string, src []byte) ([][2]*yaml.Node, hcl.Diagnostics) {
+	attrs, diags := mappingPairs(node, filename, src)
+	ret := make([][2]*yaml.Node, len(attrs))
+	for i, attr := range attrs {
+		ret[i] = [2]*yaml.Node{attr.keyNode, attr.valNode}
+	}
+	return ret, diags
+}
+
+func mappingPairsVisiting(node *yaml.Node, filename string, src []byte, visiting map[*yaml.Node]struct{}) ([]yamlAttr, hcl.Diagnostics) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var diags hcl.Diagnostics
+	visiting[node] = struct{}{}
+	defer delete(visiting, node)
+
+	// Local keys win over merged ones regardless of where the merge key
+	// appears in the mapping, so we need to know all of them up front.
+	local := make(map[string]struct{})
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if keyNode := node.Content[i]; !isMergeKey(keyNode) {
+			local[keyNode.Value] = struct{}{}
+		}
+	}
+
+	var attrs []yamlAttr
+	merged := make(map[string]struct{})
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		valNode := node.Content[i+1]
+
+		if !isMergeKey(keyNode) {
+			attrs = append(attrs, yamlAttr{keyNode: keyNode, valNode: valNode})
+			continue
+		}
+
+		sources, srcDiags := mergeSources(valNode, filename, src)
+		diags = append(diags, srcDiags...)
+		for _, source := range sources {
+			if _, cycle := visiting[source]; cycle {
+				subject := nodeRange(valNode, filename, src)
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid YAML merge key",
+					Detail:   "A mapping cannot be merged into itself.",
+					Subject:  &subject,
+				})
+				continue
+			}
+
+			sourceAttrs, sourceDiags := mappingPairsVisiting(source, filename, src, visiting)
+			diags = append(diags, sourceDiags...)
+			for _, attr := range sourceAttrs {
+				name := attr.keyNode.Value
+				if _, exists := local[name]; exists {
+					continue
+				}
+				if _, exists := merged[name]; exists {
+					continue
+				}
+				merged[name] = struct{}{}
+				attrs = append(attrs, attr)
+			}
+		}
+	}
+
+	return attrs, diags
+}
+
+// mergeSources returns the mappings referenced by the value of a merge key,
+// which must be either a mapping, an alias of a mapping, or a sequence of
+// those.
+func mergeSources(valNode *yaml.Node, filename string, src []byte) ([]*yaml.Node, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	var candidates []*yaml.Node
+	if valNode.Kind == yaml.SequenceNode {
+		candidates = valNode.Content
+	} else {
+		candidates = []*yaml.Node{valNode}
+	}
+
+	sources := make([]*yaml.Node, 0, len(candidates))
+	for _, candidate := range candidates {
+		target := ResolveAlias(candidate)
+		if target == nil || target.Kind != yaml.MappingNode {
+			subject := nodeRange(candidate, filename, src)
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid YAML merge key",
+				Detail:   "The value of a merge key (\"<<\") must be a mapping, an alias of a mapping, or a sequence of aliases of mappings.",
+				Subject:  &subject,
+			})
+			continue
+		}
+		sources = append(sources, target)
+	}
+
+	return sources, diags
+}
+
+// ResolveAlias follows alias nodes until reaching the anchored node they
+// refer to. Other nodes are returned as-is.
+func ResolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
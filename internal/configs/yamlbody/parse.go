@@ -0,0 +1,204 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package yamlbody
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Parse parses the given YAML source and returns an hcl.File whose body is
+// backed by the root node of the document, in the same way that hcljson.Parse
+// does for JSON. An empty document is treated as an empty mapping.
+//
+// The source must contain only one document. Use ParseDocuments where a
+// stream of several documents is allowed.
+func Parse(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
+	docs, diags := parseDocuments(src, filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if len(docs) > 1 {
+		subject := nodeStartRange(docs[1], filename, src)
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Multiple YAML documents",
+				Detail:   fmt.Sprintf("The file %q contains %d YAML documents, but only one is allowed here.", filename, len(docs)),
+				Subject:  &subject,
+			},
+		}
+	}
+
+	return &hcl.File{
+		Body:  NewBody(docs[0], filename, src),
+		Bytes: src,
+	}, nil
+}
+
+// ParseDocuments is like Parse, but allows a stream of several documents
+// separated by "---" lines. Each document is decoded as a separate body, and
+// the body of the returned file merges them in the same way as separate
+// files in a module, so that an argument can't be set in more than one.
+//
+// The detail of any diagnostics about the content of a stream of several
+// documents includes the index of the document that they relate to.
+func ParseDocuments(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
+	docs, diags := parseDocuments(src, filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if len(docs) == 1 {
+		return &hcl.File{
+			Body:  NewBody(docs[0], filename, src),
+			Bytes: src,
+		}, nil
+	}
+
+	bodies := make([]hcl.Body, len(docs))
+	starts := make([]int, len(docs))
+	for i, doc := range docs {
+		bodies[i] = &body{
+			node:     doc.Content[0],
+			filename: filename,
+			src:      src,
+			document: i + 1,
+		}
+		starts[i] = byteOffsetForLineCol(src, doc.Line, doc.Column)
+	}
+	return &hcl.File{
+		Body: &documentsBody{
+			Body:   hcl.MergeBodies(bodies),
+			starts: starts,
+		},
+		Bytes: src,
+	}, nil
+}
+
+// documentsBody merges the bodies of a stream of several documents. The
+// diagnostics produced by the merge itself, such as for an argument that's
+// set in more than one document, are given the index of the document that
+// their subject is in, as for the diagnostics from each document's body.
+type documentsBody struct {
+	hcl.Body
+
+	// starts are the byte offsets of the start of each document.
+	starts []int
+}
+
+// Content implements hcl.Body.
+func (b *documentsBody) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
+	content, diags := b.Body.Content(schema)
+	return content, b.inDocuments(diags)
+}
+
+// PartialContent implements hcl.Body.
+func (b *documentsBody) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
+	content, remain, diags := b.Body.PartialContent(schema)
+	return content, &documentsBody{Body: remain, starts: b.starts}, b.inDocuments(diags)
+}
+
+// JustAttributes implements hcl.Body.
+func (b *documentsBody) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
+	attrs, diags := b.Body.JustAttributes()
+	return attrs, b.inDocuments(diags)
+}
+
+func (b *documentsBody) inDocuments(diags hcl.Diagnostics) hcl.Diagnostics {
+	for _, diag := range diags {
+		if diag.Subject == nil {
+			continue
+		}
+		document := 0
+		for i, start := range b.starts {
+			if diag.Subject.Start.Byte >= start {
+				document = i + 1
+			}
+		}
+		inDocument(hcl.Diagnostics{diag}, document)
+	}
+	return diags
+}
+
+// parseDocuments decodes each of the documents in the given YAML source,
+// returning at least one document node with exactly one content node.
+func parseDocuments(src []byte, filename string) ([]*yaml.Node, hcl.Diagnostics) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid YAML syntax",
+					Detail:   fmt.Sprintf("The file %q contains invalid YAML: %s", filename, err),
+				},
+			}
+		}
+		docs = append(docs, &doc)
+	}
+
+	// Handle empty YAML files and documents - create an empty mapping node
+	if len(docs) == 0 {
+		docs = append(docs, &yaml.Node{Kind: yaml.DocumentNode})
+	}
+	for _, doc := range docs {
+		if len(doc.Content) == 0 {
+			doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Line: doc.Line, Column: doc.Column}}
+		}
+	}
+
+	return docs, nil
+}
+
+// inDocument adds the index of the YAML document to the detail of each of
+// the given diagnostics, unless the index is zero because the source has
+// only one document.
+func inDocument(diags hcl.Diagnostics, document int) hcl.Diagnostics {
+	if document == 0 {
+		return diags
+	}
+	note := fmt.Sprintf("This is in YAML document %d of the file.", document)
+	for _, diag := range diags {
+		switch {
+		case strings.HasSuffix(diag.Detail, note):
+			// Already noted by a nested body or expression.
+		case diag.Detail == "":
+			diag.Detail = note
+		default:
+			diag.Detail += "\n\n" + note
+		}
+	}
+	return diags
+}
+
+// PlainScalarText returns the source text of the given expression if it is
+// an untagged plain scalar that YAML resolved as a number or a bool.
+//
+// This allows callers that expect a string to use the value as it was
+// written, such as "1.10", rather than the string conversion of the
+// resolved value, which would be "1.1".
+func PlainScalarText(expr hcl.Expression) (string, bool) {
+	e, ok := expr.(*expression)
+	if !ok || e.src == nil || e.src.Kind != yaml.ScalarNode || e.src.Style != 0 {
+		return "", false
+	}
+	switch e.src.Tag {
+	case "!!int", "!!float", "!!bool":
+		return e.src.Value, true
+	default:
+		return "", false
+	}
+}
@@ -92,12 +92,12 @@ func calculateEndPos(node *yaml.Node, src []byte, startByte int) (line, col, byt
 	switch node.Kind {
 	case yaml.ScalarNode:
 		// For scalars, end is start + value length
-		// But we need to account for quoted strings and multiline values
+		// But we need to account for tags, quoted strings and multiline values
 		valueLen := len(node.Value)
-		if node.Style == yaml.DoubleQuotedStyle || node.Style == yaml.SingleQuotedStyle {
+		if isQuotedScalar(node) {
 			valueLen += 2 // account for quotes
 		}
-		endByte := startByte + valueLen
+		endByte := scalarValueOffset(node, src, startByte) + valueLen
 		if endByte > len(src) {
 			endByte = len(src)
 		}
@@ -128,6 +128,28 @@ func calculateEndPos(node *yaml.Node, src []byte, startByte int) (line, col, byt
 	}
 }
 
+// scalarValueOffset returns the byte offset at which the value of a scalar
+// node begins, skipping over any explicit tag that precedes it. yaml.v3
+// reports the position of the tag rather than the value for tagged nodes.
+func scalarValueOffset(node *yaml.Node, src []byte, startByte int) int {
+	i := startByte
+	if node.Style&yaml.TaggedStyle == 0 || i >= len(src) || src[i] != '!' {
+		return i
+	}
+	for i < len(src) && src[i] != ' ' && src[i] != '\t' && src[i] != '\n' {
+		i++
+	}
+	for i < len(src) && (src[i] == ' ' || src[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+// isQuotedScalar returns true if the node is a single or double quoted scalar.
+func isQuotedScalar(node *yaml.Node) bool {
+	return node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0
+}
+
 // lineColForByteOffset calculates the 1-based line and column for a byte offset.
 func lineColForByteOffset(src []byte, offset int) (line, col int) {
 	if offset > len(src) {
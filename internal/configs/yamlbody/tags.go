@@ -0,0 +1,137 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package yamlbody
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"gopkg.in/yaml.v3"
+)
+
+// Local YAML tags that select how a scalar value is interpreted.
+//
+// Untagged strings are HCL templates, matching the behavior of the JSON
+// syntax, so these tags exist for the cases a template can't express
+// cleanly: a non-string expression, a bare reference, or a string that
+// must not be interpolated at all.
+const (
+	// ExprTag marks a scalar as an arbitrary HCL native syntax expression,
+	// such as "!expr var.count > 0 ? 1 : 0".
+	ExprTag = "!expr"
+
+	// RefTag marks a scalar as a reference to a named object, such as
+	// "!ref aws_instance.example.id".
+	RefTag = "!ref"
+
+	// TplTag marks a scalar as an HCL template. This is the default for
+	// strings, but can be used to force template interpretation of values
+	// that YAML would otherwise resolve as a number or bool.
+	TplTag = "!tpl"
+
+	// LiteralTag marks a scalar as a literal string which is never
+	// interpreted as a template.
+	LiteralTag = "!literal"
+)
+
+// UnwrapNativeSyntax returns the HCL native syntax expression written in a
+// YAML scalar tagged with !expr or !ref. Any other expression is returned
+// unchanged.
+//
+// Callers that reparse the string value of a YAML expression as HCL native
+// syntax, as is done for JSON, must unwrap the expression first because
+// tagged expressions don't evaluate to their source text.
+func UnwrapNativeSyntax(expr hcl.Expression) (hcl.Expression, hcl.Diagnostics) {
+	e, ok := expr.(*expression)
+	if !ok || !e.isNativeSyntax() {
+		return expr, nil
+	}
+	native, diags := e.nativeExpr()
+	diags = inDocument(diags, e.document)
+	if diags.HasErrors() {
+		return expr, diags
+	}
+	return native, diags
+}
+
+// IsLocalTag returns true for tags using the "!" local tag prefix, as
+// opposed to the "!!" prefix of the YAML core schema tags.
+func IsLocalTag(tag string) bool {
+	return strings.HasPrefix(tag, "!") && !strings.HasPrefix(tag, "!!")
+}
+
+// isNativeSyntax returns true if this expression is written in the HCL
+// native syntax.
+func (e *expression) isNativeSyntax() bool {
+	return e.src != nil && (e.src.Tag == ExprTag || e.src.Tag == RefTag)
+}
+
+// checkTag returns error diagnostics if the expression's node uses a local
+// tag that isn't supported, or uses a supported tag on a non-scalar node.
+func (e *expression) checkTag() hcl.Diagnostics {
+	node := e.src
+	if node == nil || !IsLocalTag(node.Tag) {
+		return nil
+	}
+
+	switch node.Tag {
+	case ExprTag, RefTag, TplTag, LiteralTag:
+		if node.Kind == yaml.ScalarNode {
+			return nil
+		}
+		subject := nodeStartRange(node, e.filename, e.srcBytes)
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid YAML tag",
+			Detail:   fmt.Sprintf("The %s tag can only be used with a single string value.", node.Tag),
+			Subject:  &subject,
+		}}
+	default:
+		subject := nodeStartRange(node, e.filename, e.srcBytes)
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported YAML tag",
+			Detail:   fmt.Sprintf("The tag %s is not supported. Valid tags are %s, %s, %s and %s.", node.Tag, ExprTag, RefTag, TplTag, LiteralTag),
+			Subject:  &subject,
+		}}
+	}
+}
+
+// nativeExpr parses the value of a scalar as an HCL native syntax
+// expression. Scalars tagged with !ref must contain only a traversal.
+func (e *expression) nativeExpr() (hclsyntax.Expression, hcl.Diagnostics) {
+	if diags := e.checkTag(); diags.HasErrors() {
+		return nil, diags
+	}
+
+	start := scalarValueStart(e.src, e.srcBytes)
+	if e.src.Tag == RefTag {
+		traversal, diags := hclsyntax.ParseTraversalAbs([]byte(e.src.Value), e.filename, start)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		return &hclsyntax.ScopeTraversalExpr{
+			Traversal: traversal,
+			SrcRange:  traversal.SourceRange(),
+		}, diags
+	}
+
+	return hclsyntax.ParseExpression([]byte(e.src.Value), e.filename, start)
+}
+
+// scalarValueStart returns the position of the first character of a
+// scalar's value, skipping over its tag and the opening quote of a quoted
+// scalar so that positions within a parsed expression line up with the
+// source.
+func scalarValueStart(node *yaml.Node, src []byte) hcl.Pos {
+	startByte := byteOffsetForLineCol(src, node.Line, node.Column)
+	offset := scalarValueOffset(node, src, startByte)
+	if isQuotedScalar(node) {
+		offset++
+	}
+	line, col := lineColForByteOffset(src, offset)
+	return hcl.Pos{Line: line, Column: col, Byte: offset}
+}
@@ -0,0 +1,19 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package yamlconv translates OpenTofu configuration files between the HCL
+// native syntax and the YAML syntax understood by package yamlbody.
+//
+// Blocks are written using the nested-mapping layout that yamlbody expects,
+// where each block label adds a level of nesting and repeated blocks are
+// written as a sequence. Literal values become YAML scalars, collections of
+// values become YAML sequences and mappings, and any other expression is
+// written either as a template string or using one of the !ref and !expr
+// tags.
+//
+// Comments are carried across wherever there is an equivalent position to
+// put them in the other syntax. Constructs that cannot be translated without
+// changing the meaning of the configuration produce error diagnostics, while
+// those that would lose only presentation details, or that need information
+// from provider schemas to translate reliably, produce warnings.
+package yamlconv
@@ -0,0 +1,793 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package yamlconv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/opentofu/opentofu/internal/configs/yamlbody"
+)
+
+// FromYAML converts a configuration file written in the YAML syntax to the
+// HCL native syntax.
+//
+// YAML writes nested blocks in the same way as arguments whose values are
+// objects, so FromYAML uses the block types of the OpenTofu language to
+// tell them apart. The content of provider, provisioner, backend and
+// encryption blocks is defined by schemas that aren't available here, so
+// mappings in those bodies are converted to arguments and a warning is
+// returned for each, since some of them may need to be rewritten as nested
+// blocks.
+func FromYAML(src []byte, filename string) ([]byte, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	c := &yamlConverter{
+		filename: filename,
+		src:      src,
+	}
+
+	// A stream of several documents is decoded like separate files in a
+	// module, so their content is written one after another.
+	var buf bytes.Buffer
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	for {
+		var root yaml.Node
+		err := dec.Decode(&root)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid YAML syntax",
+				Detail:   fmt.Sprintf("The file %q contains invalid YAML: %s", filename, err),
+			})
+			return nil, diags
+		}
+		diags = append(diags, c.document(&buf, &root)...)
+	}
+	if c.expanded != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "YAML aliases were expanded",
+			Detail:   "The HCL native syntax has no equivalent of YAML anchors, aliases and merge keys, so the values they refer to have been copied to each place they are used.",
+			Subject:  c.expanded,
+		})
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	result := hclwrite.Format(buf.Bytes())
+	if _, parseDiags := hclsyntax.ParseConfig(result, filename, hcl.InitialPos); parseDiags.HasErrors() {
+		// This suggests a bug in the conversion rather than a problem with
+		// the input, which would've been reported above.
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to generate HCL",
+			Detail:   fmt.Sprintf("The converted content of %s is not valid HCL: %s. This is a bug in OpenTofu; please report it.", filename, parseDiags.Error()),
+		})
+		return nil, diags
+	}
+	return result, diags
+}
+
+// document writes the content of a single YAML document.
+func (c *yamlConverter) document(buf *bytes.Buffer, root *yaml.Node) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	c.separate(buf)
+	c.writeComment(buf, root.HeadComment)
+	if root.HeadComment != "" {
+		buf.WriteByte('\n')
+	}
+	if len(root.Content) > 0 {
+		node := root.Content[0]
+		if node.Kind != yaml.MappingNode {
+			rng := c.nodeRange(node)
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Incorrect YAML value type",
+				Detail:   "The top level of a configuration file must be a YAML mapping.",
+				Subject:  &rng,
+			})
+			return diags
+		}
+		c.writeComment(buf, node.HeadComment)
+		diags = append(diags, c.body(buf, node, configFileSchema)...)
+		c.writeComment(buf, node.FootComment)
+	}
+	c.writeComment(buf, root.FootComment)
+	return diags
+}
+
+// yamlConverter holds the state for converting a single YAML file to the
+// HCL native syntax.
+type yamlConverter struct {
+	filename string
+	src      []byte
+
+	// expanded is the range of the first alias or merge key in the file, if
+	// any, for which we return a warning once the conversion is complete.
+	expanded *hcl.Range
+
+	// depth is the number of blocks we're currently nested within.
+	depth int
+}
+
+// body writes the arguments and blocks of a YAML mapping.
+func (c *yamlConverter) body(buf *bytes.Buffer, node *yaml.Node, schema *blockSchema) hcl.Diagnostics {
+	pairs, diags := c.mappingPairs(node)
+	for _, pair := range pairs {
+		key, val := pair[0], pair[1]
+		own := isOwnPair(node, key)
+		c.separate(buf)
+		if own {
+			c.writeComment(buf, key.HeadComment)
+		}
+
+		switch {
+		case key.Kind != yaml.ScalarNode || yamlbody.IsLocalTag(key.Tag):
+			rng := c.nodeRange(key)
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid YAML property name",
+				Detail:   "The names of arguments and blocks must be plain strings.",
+				Subject:  &rng,
+			})
+
+		case key.Value == "//":
+			// yamlbody allows "//" properties as comments, as in the JSON
+			// syntax, so we turn them into real comments.
+			diags = append(diags, c.commentProperty(buf, key, val)...)
+
+		case schema.block(key.Value) != nil:
+			diags = append(diags, c.blocks(buf, key.Value, key, val, schema.block(key.Value), nil)...)
+
+		default:
+			diags = append(diags, c.attribute(buf, key, val, schema)...)
+		}
+
+		if own {
+			c.writeComment(buf, key.FootComment)
+		}
+	}
+	return diags
+}
+
+// commentProperty writes the value of a "//" property as a comment.
+func (c *yamlConverter) commentProperty(buf *bytes.Buffer, key, val *yaml.Node) hcl.Diagnostics {
+	val = c.resolveAlias(val)
+	if val.Kind != yaml.ScalarNode {
+		rng := c.nodeRange(key)
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagWarning,
+			Summary:  "Comment property not converted",
+			Detail:   `The value of a "//" property is ignored when the configuration is decoded, but this one can't be written as a comment because it isn't a string. It has been left out of the result.`,
+			Subject:  &rng,
+		}}
+	}
+	for _, line := range strings.Split(strings.TrimRight(val.Value, "\n"), "\n") {
+		buf.WriteString(strings.TrimRight("# "+line, " "))
+		buf.WriteByte('\n')
+	}
+	return nil
+}
+
+// attribute writes a single argument.
+func (c *yamlConverter) attribute(buf *bytes.Buffer, key, val *yaml.Node, schema *blockSchema) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	name := key.Value
+	rng := c.nodeRange(key)
+	if !hclsyntax.ValidIdentifier(name) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid argument name",
+			Detail:   fmt.Sprintf("The property name %q is not a valid argument name, so it can't be written in the HCL native syntax. Argument names must start with a letter and contain only letters, digits, underscores and dashes.", name),
+			Subject:  &rng,
+		})
+		return diags
+	}
+
+	if schema.pluginDefined && maybeBlock(c.resolveAlias(val)) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Possible nested block converted to an argument",
+			Detail:   fmt.Sprintf("The value of %q could be either an argument or a nested block whose type is defined by the provider, which can't be determined without the provider's schema. It has been converted to an argument. If the schema declares %q as a block type, rewrite it using the block syntax.", name, name),
+			Subject:  &rng,
+		})
+	}
+
+	expr, exprDiags := c.expr(val, true)
+	diags = append(diags, exprDiags...)
+	if exprDiags.HasErrors() {
+		return diags
+	}
+	if bytes.HasPrefix(expr, []byte("<<")) {
+		// Nothing can follow the closing delimiter of a heredoc, so any
+		// line comment goes above the argument instead.
+		var comment bytes.Buffer
+		c.writeLineComment(&comment, key, val)
+		c.writeComment(buf, strings.TrimSpace(comment.String()))
+		buf.WriteString(name + " = ")
+		buf.Write(expr)
+		buf.WriteByte('\n')
+		return diags
+	}
+	buf.WriteString(name + " = ")
+	buf.Write(expr)
+	c.writeLineComment(buf, key, val)
+	buf.WriteByte('\n')
+	return diags
+}
+
+// maybeBlock returns true if the given value has the form of one or more
+// nested blocks.
+func maybeBlock(val *yaml.Node) bool {
+	switch val.Kind {
+	case yaml.MappingNode:
+		return true
+	case yaml.SequenceNode:
+		if len(val.Content) == 0 {
+			return false
+		}
+		for _, item := range val.Content {
+			if item = yamlbody.ResolveAlias(item); item.Kind != yaml.MappingNode {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// blocks writes the blocks of the given type found in a YAML value,
+// following the same rules as yamlbody for nested label mappings and for
+// sequences of blocks with the same labels.
+func (c *yamlConverter) blocks(buf *bytes.Buffer, typeName string, key, val *yaml.Node, schema *blockSchema, labels []string) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	val = c.resolveAlias(val)
+
+	if len(labels) < schema.labels {
+		if val.Kind != yaml.MappingNode {
+			rng := c.nodeRange(val)
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Missing block label",
+				Detail:   fmt.Sprintf("At least one mapping property is required, whose name represents a label of the %s block.", typeName),
+				Subject:  &rng,
+			})
+			return diags
+		}
+
+		pairs, pairDiags := c.mappingPairs(val)
+		diags = append(diags, pairDiags...)
+		for _, pair := range pairs {
+			labelKey, labelVal := pair[0], pair[1]
+			own := isOwnPair(val, labelKey)
+			c.separate(buf)
+			if own {
+				c.writeComment(buf, labelKey.HeadComment)
+			}
+			diags = append(diags, c.blocks(buf, typeName, labelKey, labelVal, schema, append(labels, labelKey.Value))...)
+			if own {
+				c.writeComment(buf, labelKey.FootComment)
+			}
+		}
+		return diags
+	}
+
+	switch val.Kind {
+	case yaml.ScalarNode:
+		if val.Tag == "!!null" || val.Value == "null" || val.Value == "~" || val.Value == "" {
+			// A null value means there's no block, as in yamlbody.
+			return diags
+		}
+		rng := c.nodeRange(val)
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Incorrect YAML value type",
+			Detail:   "A YAML mapping is required here to define block content.",
+			Subject:  &rng,
+		})
+
+	case yaml.MappingNode:
+		diags = append(diags, c.block(buf, typeName, labels, key, val, schema)...)
+
+	case yaml.SequenceNode:
+		for _, item := range val.Content {
+			c.separate(buf)
+			c.writeComment(buf, item.HeadComment)
+			item = c.resolveAlias(item)
+			if item.Kind != yaml.MappingNode {
+				rng := c.nodeRange(item)
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Incorrect YAML value type",
+					Detail:   "A YAML mapping is required here to define block content.",
+					Subject:  &rng,
+				})
+				continue
+			}
+			diags = append(diags, c.block(buf, typeName, labels, item, item, schema)...)
+		}
+	}
+
+	return diags
+}
+
+// block writes a single block whose content is the given mapping.
+func (c *yamlConverter) block(buf *bytes.Buffer, typeName string, labels []string, key, content *yaml.Node, schema *blockSchema) hcl.Diagnostics {
+	buf.WriteString(typeName)
+	for _, label := range labels {
+		buf.WriteByte(' ')
+		buf.Write(hclwrite.TokensForValue(cty.StringVal(label)).Bytes())
+	}
+	buf.WriteString(" {")
+	c.writeLineComment(buf, key, content)
+	buf.WriteByte('\n')
+	c.depth++
+	diags := c.body(buf, content, schema)
+	c.depth--
+	buf.WriteString("}\n")
+	return diags
+}
+
+// separate starts a new paragraph before each top-level item, unless
+// there's already a blank line.
+func (c *yamlConverter) separate(buf *bytes.Buffer) {
+	if c.depth == 0 && buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n\n")) {
+		buf.WriteByte('\n')
+	}
+}
+
+// expr returns the HCL native syntax for a YAML value. Multi-line templates
+// are written as heredocs only where allowHeredoc is set, since a heredoc
+// must be followed by a newline.
+func (c *yamlConverter) expr(node *yaml.Node, allowHeredoc bool) ([]byte, hcl.Diagnostics) {
+	node = c.resolveAlias(node)
+	if diags := c.checkTag(node); diags.HasErrors() {
+		return nil, diags
+	}
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return c.tuple(node)
+	case yaml.MappingNode:
+		return c.object(node)
+	case yaml.ScalarNode:
+		return c.scalar(node, allowHeredoc)
+	default:
+		return []byte("null"), nil
+	}
+}
+
+// scalar returns the HCL native syntax for a YAML scalar, interpreting it
+// in the same way as yamlbody.
+func (c *yamlConverter) scalar(node *yaml.Node, allowHeredoc bool) ([]byte, hcl.Diagnostics) {
+	value := node.Value
+	if yamlbody.IsNullScalar(node) {
+		return []byte("null"), nil
+	}
+
+	switch node.Tag {
+	case yamlbody.ExprTag:
+		src := strings.TrimSpace(value)
+		_, diags := hclsyntax.ParseExpression([]byte(src), c.filename, c.nodeRange(node).Start)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		if strings.Contains(src, "\n") && !validAttributeValue(src) {
+			// Newlines are only ignored within brackets, so an expression
+			// that was only valid on multiple lines as a YAML scalar
+			// needs to be wrapped in parentheses.
+			return []byte("(" + src + "\n)"), diags
+		}
+		return []byte(src), diags
+
+	case yamlbody.RefTag:
+		src := strings.TrimSpace(value)
+		_, diags := hclsyntax.ParseTraversalAbs([]byte(src), c.filename, c.nodeRange(node).Start)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		return []byte(src), diags
+
+	case yamlbody.LiteralTag:
+		return hclwrite.TokensForValue(cty.StringVal(value)).Bytes(), nil
+
+	case "!!bool":
+		switch value {
+		case "true", "True", "TRUE", "yes", "Yes", "YES", "on", "On", "ON":
+			return []byte("true"), nil
+		case "false", "False", "FALSE", "no", "No", "NO", "off", "Off", "OFF":
+			return []byte("false"), nil
+		}
+
+	case "!!int", "!!float":
+		if f, _, err := big.ParseFloat(value, 10, 512, big.ToNearestEven); err == nil {
+			if lit, ok := parseLiteral(value); ok && lit.Type() == cty.Number {
+				return []byte(value), nil
+			}
+			return hclwrite.TokensForValue(cty.NumberVal(f)).Bytes(), nil
+		}
+	}
+
+	return c.template(node, allowHeredoc)
+}
+
+// validAttributeValue returns true if src can be used as the value of an
+// argument as-is.
+func validAttributeValue(src string) bool {
+	_, diags := hclsyntax.ParseConfig([]byte("x = "+src+"\n"), "", hcl.InitialPos)
+	return !diags.HasErrors()
+}
+
+// parseLiteral returns the value of src if it is a literal value in the
+// HCL native syntax.
+func parseLiteral(src string) (cty.Value, bool) {
+	expr, diags := hclsyntax.ParseExpression([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilVal, false
+	}
+	lit, ok := expr.(*hclsyntax.LiteralValueExpr)
+	if !ok {
+		return cty.NilVal, false
+	}
+	return lit.Val, true
+}
+
+// template returns the HCL native syntax for a YAML string, which yamlbody
+// interprets as a template. Multi-line templates ending with a newline are
+// written as heredocs where possible, and anything else as a quoted
+// template.
+func (c *yamlConverter) template(node *yaml.Node, allowHeredoc bool) ([]byte, hcl.Diagnostics) {
+	value := node.Value
+	if !strings.Contains(value, "${") && !strings.Contains(value, "%{") {
+		return hclwrite.TokensForValue(cty.StringVal(value)).Bytes(), nil
+	}
+
+	_, diags := hclsyntax.ParseTemplate([]byte(value), c.filename, c.nodeRange(node).Start)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	if allowHeredoc && strings.HasSuffix(value, "\n") && strings.Count(value, "\n") > 1 {
+		// Heredoc templates use the same escaping rules as templates in
+		// YAML strings, so the value can be used as-is.
+		delim := heredocDelimiter(value)
+		return []byte("<<" + delim + "\n" + value + delim), diags
+	}
+
+	// In a quoted template, the literal parts also allow the usual string
+	// escape sequences, so we need to escape those parts without changing
+	// the interpolation and directive sequences between them.
+	tokens, _ := hclsyntax.LexTemplate([]byte(value), c.filename, hcl.InitialPos)
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	depth := 0
+	for i, tok := range tokens {
+		if tok.Type == hclsyntax.TokenEOF {
+			break
+		}
+		switch {
+		case depth == 0 && tok.Type == hclsyntax.TokenStringLit:
+			buf.WriteString(quotedTemplateEscaper.Replace(string(tok.Bytes)))
+		default:
+			end := len(value)
+			if i+1 < len(tokens) {
+				end = tokens[i+1].Range.Start.Byte
+			}
+			buf.WriteString(value[tok.Range.Start.Byte:end])
+		}
+		switch tok.Type {
+		case hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl:
+			depth++
+		case hclsyntax.TokenTemplateSeqEnd:
+			depth--
+		}
+	}
+	buf.WriteByte('"')
+	return buf.Bytes(), diags
+}
+
+var quotedTemplateEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+)
+
+// heredocDelimiter chooses a delimiter for a heredoc template that doesn't
+// appear as a line of its content.
+func heredocDelimiter(content string) string {
+	lines := strings.Split(content, "\n")
+	for i := 0; ; i++ {
+		delim := "EOT"
+		if i > 0 {
+			delim = fmt.Sprintf("EOT%d", i)
+		}
+		conflict := false
+		for _, line := range lines {
+			if strings.TrimSpace(line) == delim {
+				conflict = true
+				break
+			}
+		}
+		if !conflict {
+			return delim
+		}
+	}
+}
+
+// tuple returns a tuple constructor for a YAML sequence.
+func (c *yamlConverter) tuple(node *yaml.Node) ([]byte, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	multiline := node.Style&yaml.FlowStyle == 0 || hasComments(node)
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	if multiline && len(node.Content) > 0 {
+		buf.WriteByte('\n')
+	}
+	for i, item := range node.Content {
+		if multiline {
+			c.writeComment(&buf, item.HeadComment)
+		} else if i > 0 {
+			buf.WriteString(", ")
+		}
+		expr, exprDiags := c.expr(item, false)
+		diags = append(diags, exprDiags...)
+		buf.Write(expr)
+		if multiline {
+			buf.WriteByte(',')
+			c.writeLineComment(&buf, item)
+			buf.WriteByte('\n')
+			c.writeComment(&buf, item.FootComment)
+		}
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), diags
+}
+
+// object returns an object constructor for a YAML mapping.
+func (c *yamlConverter) object(node *yaml.Node) ([]byte, hcl.Diagnostics) {
+	pairs, diags := c.mappingPairs(node)
+	multiline := node.Style&yaml.FlowStyle == 0 || hasComments(node)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	if multiline && len(pairs) > 0 {
+		buf.WriteByte('\n')
+	}
+	for i, pair := range pairs {
+		key, val := pair[0], pair[1]
+		own := isOwnPair(node, key)
+		if multiline && own {
+			c.writeComment(&buf, key.HeadComment)
+		} else if !multiline && i > 0 {
+			buf.WriteString(", ")
+		}
+
+		keyExpr, keyDiags := c.objectKey(key)
+		diags = append(diags, keyDiags...)
+		expr, exprDiags := c.expr(val, false)
+		diags = append(diags, exprDiags...)
+		buf.Write(keyExpr)
+		buf.WriteString(" = ")
+		buf.Write(expr)
+		if multiline {
+			if own {
+				c.writeLineComment(&buf, key, val)
+			}
+			buf.WriteByte('\n')
+			if own {
+				c.writeComment(&buf, key.FootComment)
+			}
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), diags
+}
+
+// objectKey returns the HCL native syntax for a key in an object
+// constructor. yamlbody evaluates keys in the same way as values, and then
+// converts the result to a string.
+func (c *yamlConverter) objectKey(key *yaml.Node) ([]byte, hcl.Diagnostics) {
+	if diags := c.checkTag(key); diags.HasErrors() {
+		return nil, diags
+	}
+	rng := c.nodeRange(key)
+	if key.Kind != yaml.ScalarNode {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid object key",
+			Detail:   "An object key must be a string.",
+			Subject:  &rng,
+		}}
+	}
+
+	if yamlbody.IsNullScalar(key) {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid object key",
+			Detail:   "Cannot use null value as an object key.",
+			Subject:  &rng,
+		}}
+	}
+
+	switch key.Tag {
+	case yamlbody.ExprTag, yamlbody.RefTag:
+		expr, diags := c.scalar(key, false)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		if key.Tag == yamlbody.RefTag && strings.ContainsAny(string(expr), ".[") {
+			// A traversal with more than one step is not taken as a
+			// literal key, and some arguments such as the providers map
+			// of a module call require the key to be a bare traversal.
+			return expr, diags
+		}
+		return []byte("(" + string(expr) + ")"), diags
+
+	case "!!int", "!!float", "!!bool":
+		expr, diags := c.scalar(key, false)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		if val, ok := parseLiteral(string(expr)); ok {
+			if str, err := convert.Convert(val, cty.String); err == nil {
+				return hclwrite.TokensForValue(str).Bytes(), diags
+			}
+		}
+		return expr, diags
+
+	case yamlbody.LiteralTag:
+		return hclwrite.TokensForValue(cty.StringVal(key.Value)).Bytes(), nil
+	}
+
+	if hclsyntax.ValidIdentifier(key.Value) && !reservedObjectKeys[key.Value] && !strings.Contains(key.Value, "${") {
+		return []byte(key.Value), nil
+	}
+	return c.template(key, false)
+}
+
+// reservedObjectKeys are identifiers that must be quoted when used as
+// object keys so that they're not interpreted as keywords.
+var reservedObjectKeys = map[string]bool{
+	"null":  true,
+	"true":  true,
+	"false": true,
+	"for":   true,
+}
+
+// checkTag returns error diagnostics for the same unsupported uses of local
+// tags that yamlbody reports when evaluating an expression.
+func (c *yamlConverter) checkTag(node *yaml.Node) hcl.Diagnostics {
+	if !yamlbody.IsLocalTag(node.Tag) {
+		return nil
+	}
+	rng := c.nodeRange(node)
+	switch node.Tag {
+	case yamlbody.ExprTag, yamlbody.RefTag, yamlbody.TplTag, yamlbody.LiteralTag:
+		if node.Kind == yaml.ScalarNode {
+			return nil
+		}
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid YAML tag",
+			Detail:   fmt.Sprintf("The %s tag can only be used with a single string value.", node.Tag),
+			Subject:  &rng,
+		}}
+	default:
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported YAML tag",
+			Detail:   fmt.Sprintf("The tag %s is not supported. Valid tags are %s, %s, %s and %s.", node.Tag, yamlbody.ExprTag, yamlbody.RefTag, yamlbody.TplTag, yamlbody.LiteralTag),
+			Subject:  &rng,
+		}}
+	}
+}
+
+// mappingPairs returns the pairs of a mapping with merge keys expanded,
+// noting the use of any merge keys for the warning returned by FromYAML.
+func (c *yamlConverter) mappingPairs(node *yaml.Node) ([][2]*yaml.Node, hcl.Diagnostics) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i]; key.Kind == yaml.ScalarNode && key.ShortTag() == "!!merge" {
+			c.noteExpanded(key)
+			break
+		}
+	}
+	return yamlbody.MappingPairs(node, c.filename, c.src)
+}
+
+// resolveAlias follows alias nodes to the node they refer to, noting their
+// use for the warning returned by FromYAML.
+func (c *yamlConverter) resolveAlias(node *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.AliasNode {
+		c.noteExpanded(node)
+	}
+	return yamlbody.ResolveAlias(node)
+}
+
+func (c *yamlConverter) noteExpanded(node *yaml.Node) {
+	if c.expanded == nil {
+		rng := c.nodeRange(node)
+		c.expanded = &rng
+	}
+}
+
+// isOwnPair returns true if the given key node belongs to the mapping
+// itself, rather than to a mapping merged into it. Comments of merged
+// pairs stay with the mapping they are defined in.
+func isOwnPair(mapping, key *yaml.Node) bool {
+	for i := 0; i < len(mapping.Content); i += 2 {
+		if mapping.Content[i] == key {
+			return true
+		}
+	}
+	return false
+}
+
+// hasComments returns true if any node within the given collection has a
+// comment.
+func hasComments(node *yaml.Node) bool {
+	for _, child := range node.Content {
+		if child.HeadComment != "" || child.LineComment != "" || child.FootComment != "" || hasComments(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeComment writes the lines of a YAML comment. Comment lines begin with
+// "#" in both syntaxes.
+func (c *yamlConverter) writeComment(buf *bytes.Buffer, comment string) {
+	if comment == "" {
+		return
+	}
+	buf.WriteString(comment)
+	buf.WriteByte('\n')
+}
+
+// writeLineComment writes the line comments of the given nodes, if any, at
+// the end of the current line.
+func (c *yamlConverter) writeLineComment(buf *bytes.Buffer, nodes ...*yaml.Node) {
+	for _, node := range nodes {
+		if node.LineComment != "" {
+			buf.WriteString(" ")
+			buf.WriteString(node.LineComment)
+		}
+	}
+}
+
+// nodeRange returns a zero-length range at the start of a node.
+func (c *yamlConverter) nodeRange(node *yaml.Node) hcl.Range {
+	pos := hcl.Pos{Line: node.Line, Column: node.Column}
+	line := 1
+	for i, b := range c.src {
+		if line == node.Line {
+			pos.Byte = i + node.Column - 1
+			break
+		}
+		if b == '\n' {
+			line++
+		}
+	}
+	return hcl.Range{Filename: c.filename, Start: pos, End: pos}
+}
@@ -0,0 +1,271 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package yamlconv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestFromYAML(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"literals": {
+			src: `
+locals:
+  str: hello
+  num: 12
+  float: 1.5
+  hex: 0x1F
+  flag: true
+  nothing: null
+  quoted_null: "null"
+  numstr: "123"
+  literal: !literal ${not_interpolated}
+`,
+			want: `locals {
+  str         = "hello"
+  num         = 12
+  float       = 1.5
+  hex         = "0x1F"
+  flag        = true
+  nothing     = null
+  quoted_null = null
+  numstr      = "123"
+  literal     = "$${not_interpolated}"
+}
+`,
+		},
+		"expressions": {
+			src: `
+locals:
+  ref: !ref var.name
+  template: web-${var.name}
+  quotes: say "${var.greeting}"\n
+  call: !expr length(var.list)
+  multiline: !expr |
+    var.a
+    ? 1
+    : 2
+  heredoc: |
+    hello
+    ${var.name}
+`,
+			want: `locals {
+  ref      = var.name
+  template = "web-${var.name}"
+  quotes   = "say \"${var.greeting}\"\\n"
+  call     = length(var.list)
+  multiline = (var.a
+    ? 1
+    : 2
+  )
+  heredoc = <<EOT
+hello
+${var.name}
+EOT
+}
+`,
+		},
+		"collections": {
+			src: `
+module:
+  child:
+    source: ./child
+    list: [a, !ref var.b]
+    object: {name: x, !expr var.key: 1, with space: 2, "true": 3, 4: 5}
+    providers:
+      !ref aws.west: !ref aws.east
+    multi:
+      - 1 # one
+      - 2
+`,
+			want: `module "child" {
+  source = "./child"
+  list   = ["a", var.b]
+  object = { name = "x", (var.key) = 1, "with space" = 2, "true" = 3, "4" = 5 }
+  providers = {
+    aws.west = aws.east
+  }
+  multi = [
+    1, # one
+    2,
+  ]
+}
+`,
+		},
+		"blocks": {
+			src: `
+resource:
+  aws_instance:
+    web:
+      ami: ami-123
+      provisioner:
+        local-exec:
+          - command: echo one
+          - command: echo two
+      lifecycle:
+        precondition:
+          condition: !expr var.ok
+          error_message: Not OK.
+    db:
+      ami: ami-456
+variable:
+  name:
+    validation:
+      - condition: !expr var.name != ""
+        error_message: Empty.
+`,
+			want: `resource "aws_instance" "web" {
+  ami = "ami-123"
+  provisioner "local-exec" {
+    command = "echo one"
+  }
+  provisioner "local-exec" {
+    command = "echo two"
+  }
+  lifecycle {
+    precondition {
+      condition     = var.ok
+      error_message = "Not OK."
+    }
+  }
+}
+
+resource "aws_instance" "db" {
+  ami = "ami-456"
+}
+
+variable "name" {
+  validation {
+    condition     = var.name != ""
+    error_message = "Empty."
+  }
+}
+`,
+		},
+		"comments": {
+			src: `
+# A variable
+variable:
+  # Its name
+  name:
+    type: !ref string # the type
+    "//": A comment property
+    default: x
+# The end
+`,
+			want: `# A variable
+
+# Its name
+variable "name" {
+  type = string # the type
+  # A comment property
+  default = "x"
+}
+# The end
+`,
+		},
+		"documents": {
+			src: `
+locals:
+  a: 1
+---
+# Second
+locals:
+  b: 2
+`,
+			want: `locals {
+  a = 1
+}
+
+# Second
+locals {
+  b = 2
+}
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, diags := FromYAML([]byte(strings.TrimPrefix(test.src, "\n")), "test.tf.yaml")
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			if diff := cmp.Diff(test.want, string(got)); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFromYAML_diagnostics(t *testing.T) {
+	tests := map[string]struct {
+		src      string
+		severity hcl.DiagnosticSeverity
+		want     string
+	}{
+		"invalid YAML": {
+			src:      "locals: [",
+			severity: hcl.DiagError,
+			want:     "Invalid YAML syntax",
+		},
+		"not a mapping": {
+			src:      "- a",
+			severity: hcl.DiagError,
+			want:     "Incorrect YAML value type",
+		},
+		"invalid argument name": {
+			src:      "locals:\n  not valid: 1",
+			severity: hcl.DiagError,
+			want:     "Invalid argument name",
+		},
+		"invalid expression": {
+			src:      "locals:\n  a: !expr 1 +",
+			severity: hcl.DiagError,
+			want:     "Missing expression",
+		},
+		"unsupported tag": {
+			src:      "locals:\n  a: !foo bar",
+			severity: hcl.DiagError,
+			want:     "Unsupported YAML tag",
+		},
+		"missing label": {
+			src:      "resource:\n  aws_instance: 1",
+			severity: hcl.DiagError,
+			want:     "Missing block label",
+		},
+		"possible nested block": {
+			src:      "resource:\n  aws_instance:\n    web:\n      root_block_device:\n        volume_size: 10",
+			severity: hcl.DiagWarning,
+			want:     "Possible nested block converted to an argument",
+		},
+		"alias": {
+			src:      "locals:\n  a: &x 1\n  b: *x",
+			severity: hcl.DiagWarning,
+			want:     "YAML aliases were expanded",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, diags := FromYAML([]byte(test.src), "test.tf.yaml")
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got := diags[0].Severity; got != test.severity {
+				t.Errorf("wrong severity %v; want %v", got, test.severity)
+			}
+			if got := diags[0].Summary; got != test.want {
+				t.Errorf("wrong summary\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
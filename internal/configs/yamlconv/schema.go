@@ -0,0 +1,180 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package yamlconv
+
+// blockSchema describes the nested block types that can appear in a body.
+//
+// In YAML, a nested block and an argument whose value is an object (or a
+// list of objects) are written the same way, so FromYAML needs to know
+// which names are blocks. This mirrors the block types in the schemas that
+// package configs decodes each body with, including those that are reserved
+// for future use so that they're reported in the same way after conversion.
+type blockSchema struct {
+	labels int
+	blocks map[string]*blockSchema
+
+	// pluginDefined is set for bodies whose content is defined by a
+	// provider, provisioner, backend or encryption schema that isn't
+	// available during conversion. Mappings in these bodies could be
+	// either nested blocks or object arguments.
+	pluginDefined bool
+}
+
+// block returns the schema for the nested block type with the given name,
+// or nil if the name isn't a known block type.
+func (s *blockSchema) block(name string) *blockSchema {
+	if ret, ok := s.blocks[name]; ok {
+		return ret
+	}
+	if s.pluginDefined && name == "dynamic" {
+		return dynamicBlockSchema
+	}
+	return nil
+}
+
+var dynamicBlockSchema = &blockSchema{
+	labels: 1,
+	blocks: map[string]*blockSchema{
+		"content": {pluginDefined: true},
+	},
+}
+
+var conditionBlockSchema = &blockSchema{}
+
+var connectionBlockSchema = &blockSchema{}
+
+var provisionerBlockSchema = &blockSchema{
+	labels:        1,
+	pluginDefined: true,
+	blocks: map[string]*blockSchema{
+		"connection": connectionBlockSchema,
+		"_":          {pluginDefined: true},
+	},
+}
+
+var resourceLifecycleBlockSchema = &blockSchema{
+	blocks: map[string]*blockSchema{
+		"precondition":  conditionBlockSchema,
+		"postcondition": conditionBlockSchema,
+	},
+}
+
+var resourceBlockSchema = &blockSchema{
+	labels:        2,
+	pluginDefined: true,
+	blocks: map[string]*blockSchema{
+		"lifecycle":   resourceLifecycleBlockSchema,
+		"locals":      {},
+		"connection":  connectionBlockSchema,
+		"provisioner": provisionerBlockSchema,
+		"_":           {pluginDefined: true},
+	},
+}
+
+var dataBlockSchema = &blockSchema{
+	labels:        2,
+	pluginDefined: true,
+	blocks: map[string]*blockSchema{
+		"lifecycle": resourceLifecycleBlockSchema,
+		"locals":    {},
+		"_":         {pluginDefined: true},
+	},
+}
+
+// encryptionTargetBlockSchema returns the schema for the state, plan and
+// remote state data source blocks in the encryption block, each of which
+// can have a fallback that follows the same schema.
+func encryptionTargetBlockSchema(labels int) *blockSchema {
+	fallback := &blockSchema{blocks: map[string]*blockSchema{}}
+	fallback.blocks["fallback"] = fallback
+	return &blockSchema{
+		labels: labels,
+		blocks: map[string]*blockSchema{
+			"fallback": fallback,
+		},
+	}
+}
+
+var encryptionBlockSchema = &blockSchema{
+	blocks: map[string]*blockSchema{
+		"key_provider": {labels: 2, pluginDefined: true},
+		"method":       {labels: 2, pluginDefined: true},
+		"state":        encryptionTargetBlockSchema(0),
+		"plan":         encryptionTargetBlockSchema(0),
+		"remote_state_data_sources": {
+			blocks: map[string]*blockSchema{
+				"default":                  encryptionTargetBlockSchema(0),
+				"remote_state_data_source": encryptionTargetBlockSchema(1),
+			},
+		},
+	},
+}
+
+// configFileSchema is the schema for the top-level of a configuration file.
+var configFileSchema = &blockSchema{
+	blocks: map[string]*blockSchema{
+		"terraform": {
+			blocks: map[string]*blockSchema{
+				"backend": {labels: 1, pluginDefined: true},
+				"cloud": {
+					blocks: map[string]*blockSchema{
+						"workspaces": {},
+					},
+				},
+				"required_providers": {},
+				"provider_meta":      {labels: 1, pluginDefined: true},
+				"encryption":         encryptionBlockSchema,
+			},
+		},
+		"provider": {
+			labels:        1,
+			pluginDefined: true,
+			blocks: map[string]*blockSchema{
+				"_":         {pluginDefined: true},
+				"lifecycle": {},
+				"locals":    {},
+			},
+		},
+		"variable": {
+			labels: 1,
+			blocks: map[string]*blockSchema{
+				"validation": conditionBlockSchema,
+			},
+		},
+		"locals": {},
+		"output": {
+			labels: 1,
+			blocks: map[string]*blockSchema{
+				"precondition": conditionBlockSchema,
+			},
+		},
+		"module": {
+			labels: 1,
+			blocks: map[string]*blockSchema{
+				"_":         {},
+				"lifecycle": {},
+				"locals":    {},
+				"provider":  {labels: 1},
+			},
+		},
+		"resource":  resourceBlockSchema,
+		"data":      dataBlockSchema,
+		"ephemeral": dataBlockSchema,
+		"moved":     {},
+		"import":    {},
+		"check": {
+			labels: 1,
+			blocks: map[string]*blockSchema{
+				"data":   dataBlockSchema,
+				"assert": conditionBlockSchema,
+			},
+		},
+		"removed": {
+			blocks: map[string]*blockSchema{
+				"lifecycle":   {},
+				"provisioner": provisionerBlockSchema,
+			},
+		},
+	},
+}
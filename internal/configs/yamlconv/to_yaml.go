@@ -0,0 +1,809 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package yamlconv
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+
+	"github.com/opentofu/opentofu/internal/configs/yamlbody"
+)
+
+// yamlIndent is the number of spaces used for each level of nesting in
+// generated YAML, matching tofu fmt.
+const yamlIndent = 2
+
+// ToYAML converts a configuration file written in the HCL native syntax to
+// the YAML syntax.
+//
+// The block structure is taken from the syntax alone, so ToYAML works for
+// any body regardless of the schema it will be decoded with. Error
+// diagnostics are returned if the file isn't valid HCL, or if it contains
+// nested blocks whose relative order would change when they're grouped by
+// type and labels, in which case no result is returned.
+func ToYAML(src []byte, filename string) ([]byte, hcl.Diagnostics) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	tokens, _ := hclsyntax.LexConfig(src, filename, hcl.InitialPos)
+
+	c := &hclConverter{
+		src:      src,
+		tokens:   tokens,
+		comments: hclComments(tokens),
+	}
+	header := c.claimFileHeader()
+	root, bodyDiags := c.body(file.Body.(*hclsyntax.Body), configFileSchema)
+	diags = append(diags, bodyDiags...)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	doc := &yaml.Node{Kind: yaml.DocumentNode, HeadComment: header, Content: []*yaml.Node{root}}
+	if rest := c.claimBefore(len(src)); rest != "" {
+		if len(root.Content) == 0 {
+			doc.HeadComment = rest
+		} else {
+			appendComment(&root.Content[len(root.Content)-2].FootComment, rest)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(yamlIndent)
+	if err := enc.Encode(doc); err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to generate YAML",
+			Detail:   fmt.Sprintf("The converted content of %s could not be encoded as YAML: %s.", filename, err),
+		})
+		return nil, diags
+	}
+	if err := enc.Close(); err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to generate YAML",
+			Detail:   fmt.Sprintf("The converted content of %s could not be encoded as YAML: %s.", filename, err),
+		})
+		return nil, diags
+	}
+
+	return separateTopLevelKeys(buf.Bytes()), diags
+}
+
+// hclConverter holds the state for converting a single HCL native syntax
+// file to YAML.
+type hclConverter struct {
+	src    []byte
+	tokens hclsyntax.Tokens
+
+	// comments are all of the comments in the file in source order. Each
+	// is claimed by the first YAML node it's attached to, and any that are
+	// left over at the end of the file are written after the last item.
+	comments []*hclComment
+}
+
+// hclComment is a comment from the HCL source, already rewritten in the
+// YAML comment syntax.
+type hclComment struct {
+	rng      hcl.Range
+	lastLine int
+	text     string
+	claimed  bool
+}
+
+// hclComments collects the comments from the given tokens.
+func hclComments(tokens hclsyntax.Tokens) []*hclComment {
+	var ret []*hclComment
+	for _, tok := range tokens {
+		if tok.Type != hclsyntax.TokenComment {
+			continue
+		}
+		ret = append(ret, &hclComment{
+			rng:      tok.Range,
+			lastLine: tok.Range.Start.Line + bytes.Count(bytes.TrimRight(tok.Bytes, "\r\n"), []byte("\n")),
+			text:     yamlCommentText(tok.Bytes),
+		})
+	}
+	return ret
+}
+
+// yamlCommentText rewrites an HCL comment, in any of its three styles, as
+// one or more lines of YAML comment.
+func yamlCommentText(raw []byte) string {
+	text := strings.TrimRight(string(raw), "\r\n")
+	switch {
+	case strings.HasPrefix(text, "//"):
+		return "#" + text[2:]
+	case strings.HasPrefix(text, "/*"):
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+		lines := strings.Split(text, "\n")
+		for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+			lines = lines[1:]
+		}
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		if len(lines) == 0 {
+			return "#"
+		}
+		for i, line := range lines {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "*") {
+				// Leading asterisks are only decoration in this style.
+				line = strings.TrimSpace(line[1:])
+			}
+			if line == "" {
+				lines[i] = "#"
+			} else {
+				lines[i] = "# " + line
+			}
+		}
+		return strings.Join(lines, "\n")
+	default:
+		return text
+	}
+}
+
+// claimFileHeader claims the comments at the start of the file that are
+// separated from the first item by a blank line, which describe the file
+// as a whole rather than that item. These become the head comment of the
+// YAML document.
+func (c *hclConverter) claimFileHeader() string {
+	var first hcl.Range
+	for _, tok := range c.tokens {
+		if tok.Type != hclsyntax.TokenComment && tok.Type != hclsyntax.TokenNewline {
+			first = tok.Range
+			break
+		}
+	}
+
+	end := -1
+	for i, comment := range c.comments {
+		if comment.rng.Start.Byte >= first.Start.Byte {
+			break
+		}
+		nextLine := first.Start.Line
+		if i+1 < len(c.comments) && c.comments[i+1].rng.Start.Byte < first.Start.Byte {
+			nextLine = c.comments[i+1].rng.Start.Line
+		}
+		if nextLine > comment.lastLine+1 {
+			end = i
+		}
+	}
+	if end < 0 || first.Start.Byte >= len(c.src) {
+		// A file with only comments keeps them all together.
+		return ""
+	}
+	return c.claimBefore(c.comments[end].rng.End.Byte)
+}
+
+// claimBefore claims all of the unclaimed comments that start before the
+// given byte offset, returning them as a single YAML comment.
+func (c *hclConverter) claimBefore(offset int) string {
+	var lines []string
+	for _, comment := range c.comments {
+		if comment.rng.Start.Byte >= offset {
+			break
+		}
+		if comment.claimed {
+			continue
+		}
+		comment.claimed = true
+		lines = append(lines, comment.text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// claimLineComment claims a single-line comment that follows the given
+// position on the same line, if there is one.
+func (c *hclConverter) claimLineComment(end hcl.Pos) string {
+	for _, comment := range c.comments {
+		if comment.claimed || comment.rng.Start.Byte < end.Byte {
+			continue
+		}
+		if comment.rng.Start.Line != end.Line || strings.Contains(comment.text, "\n") {
+			break
+		}
+		comment.claimed = true
+		return comment.text
+	}
+	return ""
+}
+
+// claimWithin claims all of the comments within the given range, which
+// are retained as part of the source text of a native syntax expression.
+func (c *hclConverter) claimWithin(rng hcl.Range) {
+	for _, comment := range c.comments {
+		if comment.rng.Start.Byte >= rng.Start.Byte && comment.rng.End.Byte <= rng.End.Byte {
+			comment.claimed = true
+		}
+	}
+}
+
+// appendComment appends a comment to the given comment field of a node.
+func appendComment(field *string, comment string) {
+	if *field == "" {
+		*field = comment
+	} else {
+		*field += "\n" + comment
+	}
+}
+
+// body converts a block body to a YAML mapping. The schema is used only to
+// check the number of labels of known block types, and is nil for bodies
+// whose block types aren't known.
+//
+// Blocks are grouped by their type and then nested by each of their labels,
+// as expected by yamlbody, with a sequence where there's more than one
+// block with the same type and labels. The order of top-level blocks is not
+// significant, but the order of nested blocks can be, so for those we check
+// that the grouping doesn't change the relative order of any blocks that
+// could depend on it.
+func (c *hclConverter) body(b *hclsyntax.Body, schema *blockSchema) (*yaml.Node, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	ret := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+	type item struct {
+		attr  *hclsyntax.Attribute
+		block *hclsyntax.Block
+		start int
+	}
+	items := make([]item, 0, len(b.Attributes)+len(b.Blocks))
+	for _, attr := range b.Attributes {
+		items = append(items, item{attr: attr, start: attr.SrcRange.Start.Byte})
+	}
+	for _, block := range b.Blocks {
+		items = append(items, item{block: block, start: block.TypeRange.Start.Byte})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].start < items[j].start
+	})
+
+	keys := make(map[string]*yaml.Node)
+	blockKeys := make(map[*yaml.Node]struct{})
+	blockIndex := make(map[*yaml.Node]int)
+	var blocks []*hclsyntax.Block
+	for _, item := range items {
+		switch {
+		case item.attr != nil:
+			attr := item.attr
+			if existing, exists := keys[attr.Name]; exists {
+				_, isBlock := blockKeys[existing]
+				diags = append(diags, duplicateKeyDiagnostic(attr.Name, isBlock, attr.NameRange))
+				continue
+			}
+			key := StringNode(attr.Name)
+			key.HeadComment = c.claimBefore(attr.SrcRange.Start.Byte)
+			val, valDiags := c.expr(attr.Expr)
+			diags = append(diags, valDiags...)
+			if line := c.claimLineComment(attr.SrcRange.End); line != "" {
+				if val.Kind == yaml.ScalarNode || val.Style&yaml.FlowStyle != 0 {
+					val.LineComment = line
+				} else {
+					key.LineComment = line
+				}
+			}
+			keys[attr.Name] = key
+			ret.Content = append(ret.Content, key, val)
+
+		case item.block != nil:
+			block := item.block
+			var blockSchema *blockSchema
+			if schema != nil {
+				blockSchema = schema.block(block.Type)
+			}
+			if blockSchema != nil && len(block.Labels) != blockSchema.labels {
+				// yamlbody would silently find no blocks at all, or treat
+				// the remaining labels as the block's content.
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Incorrect number of block labels",
+					Detail:   fmt.Sprintf("A %s block must have %d labels, so it has no equivalent in YAML. Correct the configuration before converting it.", block.Type, blockSchema.labels),
+					Subject:  block.DefRange().Ptr(),
+				})
+				continue
+			}
+
+			comment := c.claimBefore(block.TypeRange.Start.Byte)
+			content, contentDiags := c.body(block.Body, blockSchema)
+			diags = append(diags, contentDiags...)
+			if rest := c.claimBefore(block.CloseBraceRange.Start.Byte); rest != "" {
+				if len(content.Content) == 0 {
+					appendComment(&comment, rest)
+				} else {
+					appendComment(&content.Content[len(content.Content)-2].FootComment, rest)
+				}
+			}
+			if len(content.Content) == 0 {
+				content.Style = yaml.FlowStyle
+			}
+
+			if key, exists := keys[block.Type]; exists {
+				if _, isBlock := blockKeys[key]; !isBlock {
+					diags = append(diags, duplicateKeyDiagnostic(block.Type, false, block.TypeRange))
+					continue
+				}
+			}
+			key := addBlock(ret, block, content, comment)
+			keys[block.Type] = key
+			blockKeys[key] = struct{}{}
+			blockIndex[content] = len(blocks)
+			blocks = append(blocks, block)
+		}
+	}
+
+	if schema != configFileSchema {
+		diags = append(diags, checkBlockOrder(ret, blockKeys, blocks, blockIndex)...)
+	}
+
+	return ret, diags
+}
+
+// addBlock adds the converted content of a block to the mapping for its
+// parent body, returning the key node for the block type.
+func addBlock(parent *yaml.Node, block *hclsyntax.Block, content *yaml.Node, comment string) *yaml.Node {
+	path := append([]string{block.Type}, block.Labels...)
+
+	mapping := parent
+	var key *yaml.Node
+	for i, name := range path {
+		var slot **yaml.Node
+		key, slot = mappingEntry(mapping, name)
+		if key == nil {
+			key = StringNode(name)
+			mapping.Content = append(mapping.Content, key, nil)
+			slot = &mapping.Content[len(mapping.Content)-1]
+		}
+
+		if i < len(path)-1 {
+			if *slot == nil {
+				*slot = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			}
+			mapping = *slot
+			continue
+		}
+
+		switch {
+		case *slot == nil:
+			*slot = content
+			appendComment(&key.HeadComment, comment)
+		case (*slot).Kind == yaml.SequenceNode:
+			content.HeadComment = comment
+			(*slot).Content = append((*slot).Content, content)
+		default:
+			content.HeadComment = comment
+			*slot = &yaml.Node{
+				Kind:    yaml.SequenceNode,
+				Tag:     "!!seq",
+				Content: []*yaml.Node{*slot, content},
+			}
+		}
+	}
+
+	return parent.Content[indexOfKey(parent, block.Type)]
+}
+
+// mappingEntry finds the key node for the given name in a mapping node we
+// are building, along with a pointer to the slot for its value.
+func mappingEntry(mapping *yaml.Node, name string) (*yaml.Node, **yaml.Node) {
+	if i := indexOfKey(mapping, name); i >= 0 {
+		return mapping.Content[i], &mapping.Content[i+1]
+	}
+	return nil, nil
+}
+
+func indexOfKey(mapping *yaml.Node, name string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkBlockOrder returns error diagnostics if grouping the blocks of a
+// nested body by type and labels would change the relative order of two
+// blocks whose order may be significant. Those are blocks of the same type,
+// such as provisioners with different labels, and dynamic blocks along with
+// the static blocks of the type they generate.
+func checkBlockOrder(mapping *yaml.Node, blockKeys map[*yaml.Node]struct{}, blocks []*hclsyntax.Block, blockIndex map[*yaml.Node]int) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	var order []int
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if idx, ok := blockIndex[node]; ok {
+			order = append(order, idx)
+			return
+		}
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 1; i < len(node.Content); i += 2 {
+				walk(node.Content[i])
+			}
+		case yaml.SequenceNode:
+			for _, item := range node.Content {
+				walk(item)
+			}
+		}
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if _, isBlock := blockKeys[mapping.Content[i]]; isBlock {
+			walk(mapping.Content[i+1])
+		}
+	}
+
+	last := make(map[string]int)
+	for _, idx := range order {
+		block := blocks[idx]
+		orderKey := blockOrderKey(block)
+		if prev, seen := last[orderKey]; seen && prev > idx {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Block order cannot be preserved",
+				Detail: fmt.Sprintf(
+					"In YAML, nested blocks are grouped by their type and labels, so this %s block would be moved after the %s block at %s, which could change the meaning of the configuration. Rearrange the blocks so that those with the same type and labels are next to each other, and then try again.",
+					block.Type, blocks[prev].Type, blocks[prev].DefRange(),
+				),
+				Subject: block.DefRange().Ptr(),
+			})
+			continue
+		}
+		last[orderKey] = idx
+	}
+
+	return diags
+}
+
+// blockOrderKey returns a key that is the same for any blocks whose
+// relative order may be significant.
+func blockOrderKey(block *hclsyntax.Block) string {
+	if block.Type == "dynamic" && len(block.Labels) == 1 {
+		return block.Labels[0]
+	}
+	return block.Type
+}
+
+func duplicateKeyDiagnostic(name string, isBlock bool, subject hcl.Range) *hcl.Diagnostic {
+	what := "argument"
+	if isBlock {
+		what = "block type"
+	}
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Duplicate YAML property",
+		Detail:   fmt.Sprintf("The name %q is already used for an %s in this body, and YAML doesn't allow a property to be defined more than once.", name, what),
+		Subject:  subject.Ptr(),
+	}
+}
+
+// expr converts an expression to a YAML node. Literal values, tuple
+// constructors and object constructors become the equivalent YAML values,
+// templates become YAML strings, references use !ref and anything else is
+// written with !expr.
+func (c *hclConverter) expr(expr hclsyntax.Expression) (*yaml.Node, hcl.Diagnostics) {
+	switch expr := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		return LiteralNode(expr.Val), nil
+
+	case *hclsyntax.TemplateExpr:
+		if expr.IsStringLiteral() {
+			val, diags := expr.Value(nil)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			return LiteralNode(val), diags
+		}
+		return c.template(expr, expr.Parts), nil
+
+	case *hclsyntax.TemplateWrapExpr:
+		return c.template(expr, []hclsyntax.Expression{expr.Wrapped}), nil
+
+	case *hclsyntax.ScopeTraversalExpr:
+		src := expr.SrcRange.SliceBytes(c.src)
+		if _, diags := hclsyntax.ParseTraversalAbs(src, "", expr.SrcRange.Start); diags.HasErrors() {
+			// Some traversals, such as those using the legacy index
+			// syntax, are valid only within a full expression.
+			return c.nativeExpr(expr), nil
+		}
+		return &yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Tag:   yamlbody.RefTag,
+			Value: string(src),
+		}, nil
+
+	case *hclsyntax.TupleConsExpr:
+		return c.tuple(expr)
+
+	case *hclsyntax.ObjectConsExpr:
+		return c.object(expr)
+	}
+
+	return c.nativeExpr(expr), nil
+}
+
+// nativeExpr writes the source text of an expression with the !expr tag.
+func (c *hclConverter) nativeExpr(expr hclsyntax.Expression) *yaml.Node {
+	rng := expr.Range()
+	c.claimWithin(rng)
+	node := &yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Tag:   yamlbody.ExprTag,
+		Value: string(rng.SliceBytes(c.src)),
+	}
+	if strings.Contains(node.Value, "\n") {
+		node.Style = yaml.LiteralStyle
+		node.Value = c.dedent(node.Value, rng)
+	}
+	return node
+}
+
+// dedent removes the indentation of the line where a multi-line expression
+// starts from each of its other lines, since the YAML block scalar is
+// indented separately. Expressions containing heredocs are left as they
+// are because the indentation can be part of the heredoc's value.
+func (c *hclConverter) dedent(text string, rng hcl.Range) string {
+	for _, tok := range c.tokens {
+		if tok.Range.Start.Byte >= rng.End.Byte {
+			break
+		}
+		if tok.Type == hclsyntax.TokenOHeredoc && tok.Range.Start.Byte >= rng.Start.Byte {
+			return text
+		}
+	}
+
+	lineStart := bytes.LastIndexByte(c.src[:rng.Start.Byte], '\n') + 1
+	line := c.src[lineStart:rng.Start.Byte]
+	indent := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]
+	if len(indent) == 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		lines[i] = strings.TrimPrefix(lines[i], string(indent))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// template converts a template with interpolation sequences to a YAML
+// string, which yamlbody interprets as a template. Templates that use
+// directives are written with !expr instead, since their literal parts
+// aren't available separately from the directives.
+func (c *hclConverter) template(expr hclsyntax.Expression, parts []hclsyntax.Expression) *yaml.Node {
+	rng := expr.Range()
+
+	// The interpolation sequences are copied from the source as-is, while
+	// the literal parts between them are escaped from their decoded values
+	// because quoted templates, heredocs and YAML strings each use
+	// different escaping rules.
+	type region struct{ start, end int }
+	var regions []region
+	depth := 0
+	for _, tok := range c.tokens {
+		if tok.Range.Start.Byte < rng.Start.Byte || tok.Range.End.Byte > rng.End.Byte {
+			continue
+		}
+		switch tok.Type {
+		case hclsyntax.TokenTemplateControl:
+			return c.nativeExpr(expr)
+		case hclsyntax.TokenTemplateInterp:
+			if depth == 0 {
+				regions = append(regions, region{start: tok.Range.Start.Byte})
+			}
+			depth++
+		case hclsyntax.TokenTemplateSeqEnd:
+			depth--
+			if depth == 0 && len(regions) > 0 {
+				regions[len(regions)-1].end = tok.Range.End.Byte
+			}
+		}
+	}
+
+	var buf strings.Builder
+	emitted := -1
+	for _, part := range parts {
+		start := part.Range().Start.Byte
+		inRegion := -1
+		for i, r := range regions {
+			if start >= r.start && start < r.end {
+				inRegion = i
+				break
+			}
+		}
+		if inRegion >= 0 {
+			if inRegion > emitted {
+				r := regions[inRegion]
+				buf.Write(c.src[r.start:r.end])
+				emitted = inRegion
+			}
+			continue
+		}
+
+		lit, ok := part.(*hclsyntax.LiteralValueExpr)
+		if !ok || lit.Val.Type() != cty.String || !lit.Val.IsKnown() || lit.Val.IsNull() {
+			return c.nativeExpr(expr)
+		}
+		buf.WriteString(escapeTemplate(lit.Val.AsString()))
+	}
+	c.claimWithin(rng)
+
+	node := &yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Tag:   "!!str",
+		Value: buf.String(),
+	}
+	if strings.Contains(node.Value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+	return node
+}
+
+// escapeTemplate escapes a literal string for use in a template.
+func escapeTemplate(s string) string {
+	return templateEscaper.Replace(s)
+}
+
+var templateEscaper = strings.NewReplacer("${", "$${", "%{", "%%{")
+
+// tuple converts a tuple constructor to a YAML sequence. Tuples written on
+// a single line keep the flow style.
+func (c *hclConverter) tuple(expr *hclsyntax.TupleConsExpr) (*yaml.Node, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	multiline := expr.SrcRange.Start.Line != expr.SrcRange.End.Line
+	if !multiline {
+		node.Style = yaml.FlowStyle
+	}
+
+	for _, elem := range expr.Exprs {
+		var head string
+		if multiline {
+			head = c.claimBefore(elem.Range().Start.Byte)
+		}
+		val, valDiags := c.expr(elem)
+		diags = append(diags, valDiags...)
+		if multiline {
+			appendComment(&val.HeadComment, head)
+			val.LineComment = c.claimLineComment(elem.Range().End)
+		}
+		node.Content = append(node.Content, val)
+	}
+	if len(node.Content) == 0 {
+		node.Style = yaml.FlowStyle
+	}
+	return node, diags
+}
+
+// object converts an object constructor to a YAML mapping. Keys that are
+// not literal names or strings are written with !expr.
+func (c *hclConverter) object(expr *hclsyntax.ObjectConsExpr) (*yaml.Node, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	multiline := expr.SrcRange.Start.Line != expr.SrcRange.End.Line
+	if !multiline {
+		node.Style = yaml.FlowStyle
+	}
+
+	for _, item := range expr.Items {
+		var head string
+		if multiline {
+			head = c.claimBefore(item.KeyExpr.Range().Start.Byte)
+		}
+
+		var key *yaml.Node
+		keyExpr := item.KeyExpr
+		if wrapper, ok := keyExpr.(*hclsyntax.ObjectConsKeyExpr); ok {
+			if name := hcl.ExprAsKeyword(wrapper.Wrapped); name != "" && !wrapper.ForceNonLiteral {
+				key = StringNode(name)
+			} else {
+				keyExpr = wrapper.Wrapped
+			}
+		}
+		if key == nil {
+			var keyDiags hcl.Diagnostics
+			key, keyDiags = c.expr(keyExpr)
+			diags = append(diags, keyDiags...)
+			if key.Kind != yaml.ScalarNode || strings.Contains(key.Value, "\n") {
+				// A collection can't be used as an object key anyway, but
+				// we'll let that be reported when the configuration is
+				// decoded.
+				key = c.nativeExpr(keyExpr)
+				key.Style = 0
+			}
+		}
+
+		val, valDiags := c.expr(item.ValueExpr)
+		diags = append(diags, valDiags...)
+		if multiline {
+			key.HeadComment = head
+			if line := c.claimLineComment(item.ValueExpr.Range().End); line != "" {
+				if val.Kind == yaml.ScalarNode || val.Style&yaml.FlowStyle != 0 {
+					val.LineComment = line
+				} else {
+					key.LineComment = line
+				}
+			}
+		}
+		node.Content = append(node.Content, key, val)
+	}
+	if len(node.Content) == 0 {
+		node.Style = yaml.FlowStyle
+	}
+	return node, diags
+}
+
+// StringNode returns a node for a mapping key or string value that is
+// never interpreted as anything other than a literal string. yamlbody takes
+// "null" and "~" to be null even when quoted, so those need a tag too.
+func StringNode(s string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+	if strings.Contains(s, "${") || strings.Contains(s, "%{") || s == "null" || s == "~" {
+		node.Tag = yamlbody.LiteralTag
+	}
+	return node
+}
+
+// LiteralNode converts a null or primitive value to a YAML scalar that
+// yamlbody decodes back to an equal value.
+func LiteralNode(val cty.Value) *yaml.Node {
+	switch {
+	case val.IsNull():
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	case val.Type() == cty.Bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(val.True())}
+	case val.Type() == cty.Number:
+		bf := val.AsBigFloat()
+		if bf.IsInt() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: bf.Text('f', 0)}
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: bf.Text('g', -1)}
+	default:
+		node := StringNode(val.AsString())
+		if strings.Contains(node.Value, "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+		return node
+	}
+}
+
+// separateTopLevelKeys inserts a blank line before each top-level mapping
+// key after the first, along with any comment lines directly above it, to
+// make the generated YAML easier to read.
+func separateTopLevelKeys(src []byte) []byte {
+	lines := strings.Split(string(src), "\n")
+	var out []string
+	seenKey := false
+	groupStart := -1
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "#"):
+			if groupStart < 0 {
+				groupStart = len(out)
+			}
+		case line != "" && line[0] != ' ' && line[0] != '-':
+			start := len(out)
+			if groupStart >= 0 {
+				start = groupStart
+			}
+			if seenKey && start > 0 && out[start-1] != "" {
+				out = append(out[:start], append([]string{""}, out[start:]...)...)
+			}
+			seenKey = true
+			groupStart = -1
+		default:
+			groupStart = -1
+		}
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, "\n"))
+}
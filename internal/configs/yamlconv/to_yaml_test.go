@@ -0,0 +1,298 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package yamlconv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/opentofu/opentofu/internal/configs"
+)
+
+func TestToYAML(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"literals": {
+			src: `
+locals {
+  str     = "hello"
+  num     = 12
+  float   = 1.5
+  yes     = true
+  nothing = null
+  nullstr = "null"
+  numstr  = "123"
+  dollars = "$${not_interpolated}"
+  lines   = "a\nb\n"
+}
+`,
+			want: `locals:
+  str: hello
+  num: 12
+  float: 1.5
+  yes: true
+  nothing: null
+  nullstr: !literal null
+  numstr: "123"
+  dollars: !literal ${not_interpolated}
+  lines: |
+    a
+    b
+`,
+		},
+		"expressions": {
+			src: `
+locals {
+  ref      = var.name
+  template = "web-${var.name}"
+  wrapped  = "${var.name}"
+  call     = length(var.list)
+  cond     = var.a ? 1 : 2
+  control  = "%{ if var.a }yes%{ endif }"
+  legacy   = aws_instance.foo.list.0
+}
+`,
+			want: `locals:
+  ref: !ref var.name
+  template: web-${var.name}
+  wrapped: ${var.name}
+  call: !expr length(var.list)
+  cond: !expr 'var.a ? 1 : 2'
+  control: !expr '"%{ if var.a }yes%{ endif }"'
+  legacy: !expr aws_instance.foo.list.0
+`,
+		},
+		"collections": {
+			src: `
+locals {
+  inline = ["a", var.b]
+  object = { name = "x", (var.key) = 1, "with space" = 2 }
+  multi = [
+    1, # one
+    2,
+  ]
+}
+`,
+			want: `locals:
+  inline: [a, !ref var.b]
+  object: {name: x, !expr (var.key): 1, with space: 2}
+  multi:
+    - 1 # one
+    - 2
+`,
+		},
+		"blocks": {
+			src: `
+resource "aws_instance" "web" {
+  ami = "ami-123"
+
+  provisioner "local-exec" {
+    command = "echo one"
+  }
+  provisioner "local-exec" {
+    command = "echo two"
+  }
+
+  lifecycle {}
+}
+
+resource "aws_instance" "db" {
+  ami = "ami-456"
+}
+
+locals {
+  a = 1
+}
+`,
+			want: `resource:
+  aws_instance:
+    web:
+      ami: ami-123
+      provisioner:
+        local-exec:
+          - command: echo one
+          - command: echo two
+      lifecycle: {}
+    db:
+      ami: ami-456
+
+locals:
+  a: 1
+`,
+		},
+		"comments": {
+			src: `
+# A variable
+variable "name" {
+  type = string // the type
+  /* The default
+   * value */
+  default = "x"
+}
+# The end
+`,
+			want: `variable:
+  # A variable
+  name:
+    type: !ref string # the type
+    # The default
+    # value
+    default: x
+# The end
+`,
+		},
+		"multi-line expression": {
+			src: `
+locals {
+  json = jsonencode({
+    a = 1
+  })
+}
+`,
+			want: `locals:
+  json: !expr |-
+    jsonencode({
+      a = 1
+    })
+`,
+		},
+		"file header": {
+			src: `
+# About this file
+
+# A variable
+variable "name" {}
+`,
+			want: `# About this file
+
+variable:
+  # A variable
+  name: {}
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ToYAML([]byte(strings.TrimPrefix(test.src, "\n")), "test.tf")
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			if diff := cmp.Diff(test.want, string(got)); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToYAML_errors(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"syntax error": {
+			src:  `resource "a" "b" {`,
+			want: "Unclosed configuration block",
+		},
+		"interleaved provisioners": {
+			src: `
+resource "a" "b" {
+  provisioner "local-exec" {}
+  provisioner "remote-exec" {}
+  provisioner "local-exec" {}
+}
+`,
+			want: "Block order cannot be preserved",
+		},
+		"dynamic and static blocks": {
+			src: `
+resource "a" "b" {
+  setting {}
+  dynamic "setting" {
+    for_each = var.settings
+  }
+  other {}
+  setting {}
+}
+`,
+			want: "Block order cannot be preserved",
+		},
+		"missing label": {
+			src:  `resource "a" {}`,
+			want: "Incorrect number of block labels",
+		},
+		"attribute and block with the same name": {
+			src: `
+resource "a" "b" {
+  thing = 1
+  thing {}
+}
+`,
+			want: "Duplicate YAML property",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ToYAML([]byte(test.src), "test.tf")
+			if !diags.HasErrors() {
+				t.Fatalf("unexpected success\n%s", got)
+			}
+			if got := diags[0].Summary; got != test.want {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
+
+// TestToYAML_validFiles converts each of the valid configuration files
+// used by package configs to YAML and back, checking that each result can
+// be loaded without errors.
+func TestToYAML_validFiles(t *testing.T) {
+	dir := "../testdata/valid-files"
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, info := range files {
+		name := info.Name()
+		if !strings.HasSuffix(name, ".tf") {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			yamlSrc, diags := ToYAML(src, name)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors converting to YAML: %s", diags.Error())
+			}
+			hclSrc, diags := FromYAML(yamlSrc, name+".yaml")
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors converting to HCL: %s", diags.Error())
+			}
+
+			fs := afero.NewMemMapFs()
+			for filename, src := range map[string][]byte{"main.tf.yaml": yamlSrc, "main.tf": hclSrc} {
+				if err := afero.WriteFile(fs, filename, src, 0644); err != nil {
+					t.Fatal(err)
+				}
+				_, diags := configs.NewParser(fs).LoadConfigFile(filename)
+				if diags.HasErrors() {
+					t.Errorf("unexpected errors loading %s: %s\n%s", filename, diags.Error(), src)
+				}
+			}
+		})
+	}
+}
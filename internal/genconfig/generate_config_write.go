@@ -6,10 +6,13 @@
 package genconfig
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
+	"github.com/opentofu/opentofu/internal/configs/yamlconv"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
@@ -56,6 +59,8 @@ func (c *Change) MaybeWriteConfig(writer io.Writer, out string) (io.Writer, bool
 					"Failed to create target generated file",
 					fmt.Sprintf("OpenTofu could not create the generated file (%s) in the target directory: %v. Depending on the error message, this may be a bug in OpenTofu itself. If so, please report it!", out, err)))
 				return nil, false, diags
+			} else if isYAMLFile(out) {
+				writer = &yamlConfigWriter{file: w}
 			} else {
 				writer = w
 			}
@@ -82,3 +87,68 @@ func (c *Change) MaybeWriteConfig(writer io.Writer, out string) (io.Writer, bool
 
 	return writer, wroteConfig, diags
 }
+
+// CloseConfigWriter finishes the generated config file for a writer
+// returned by MaybeWriteConfig, which is nil if no file was created.
+func CloseConfigWriter(writer io.Writer, out string) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	closer, ok := writer.(io.Closer)
+	if !ok {
+		return diags
+	}
+	if err := closer.Close(); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to save generated config",
+			fmt.Sprintf("OpenTofu encountered an error while writing the generated file (%s): %v. Depending on the error message, this may be a bug in OpenTofu itself. If so, please report it!", out, err)))
+	}
+	return diags
+}
+
+// isYAMLFile returns true if generated config written to the given path
+// should use the YAML syntax rather than the native syntax, which is only
+// the case for the same extensions as YAML configuration files.
+func isYAMLFile(path string) bool {
+	for _, ext := range []string{".tf.yaml", ".tf.yml", ".tofu.yaml", ".tofu.yml"} {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlConfigWriter writes generated config to a file in the YAML syntax.
+//
+// The config is generated in the native syntax, and a YAML mapping can't
+// repeat keys in the way that the native syntax repeats blocks, so the
+// writes are accumulated and the whole of the native syntax source is
+// converted to YAML once, when the writer is closed. This groups all of the
+// resources of each type together, and keeps the comments that
+// MaybeWriteConfig adds before each one.
+type yamlConfigWriter struct {
+	file *os.File
+	src  bytes.Buffer
+}
+
+func (w *yamlConfigWriter) Write(p []byte) (int, error) {
+	// Each write is converted on its own first, so that anything that
+	// can't be converted is reported for the resource that it belongs to
+	// rather than spoiling the whole file.
+	if _, diags := yamlconv.ToYAML(p, w.file.Name()); diags.HasErrors() {
+		return 0, diags
+	}
+	return w.src.Write(p)
+}
+
+func (w *yamlConfigWriter) Close() error {
+	result, diags := yamlconv.ToYAML(w.src.Bytes(), w.file.Name())
+	if diags.HasErrors() {
+		w.file.Close()
+		return diags
+	}
+	if _, err := w.file.Write(result); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
@@ -0,0 +1,128 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package genconfig
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+func TestMaybeWriteConfig_yaml(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "generated.tf.yaml")
+
+	changes := []Change{
+		{
+			Addr:     "test_instance.foo",
+			ImportID: "foo-id",
+			GeneratedConfig: `resource "test_instance" "foo" {
+  ami      = "ami-123"
+  password = null # sensitive
+}`,
+		},
+		{
+			Addr:            "test_thing.baz",
+			ImportID:        "baz-id",
+			GeneratedConfig: `resource "test_thing" "baz" {}`,
+		},
+		{
+			Addr:            "test_instance.bar",
+			GeneratedConfig: "", // nothing to write
+		},
+		{
+			Addr:     "test_instance.bar",
+			ImportID: "bar-id",
+			GeneratedConfig: `resource "test_instance" "bar" {
+  provider = test.west
+  tags = jsonencode({
+    Name = "bar"
+  })
+}`,
+		},
+	}
+
+	var writer io.Writer
+	for _, change := range changes {
+		var diags tfdiags.Diagnostics
+		writer, _, diags = change.MaybeWriteConfig(writer, out)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Err())
+		}
+	}
+	if _, ok := writer.(*yamlConfigWriter); !ok {
+		t.Fatalf("wrong writer type %T", writer)
+	}
+	if diags := CloseConfigWriter(writer, out); diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# __generated__ by OpenTofu
+# Please review these resources and move them into your main configuration files.
+
+resource:
+  test_instance:
+    # __generated__ by OpenTofu from "foo-id"
+    foo:
+      ami: ami-123
+      password: null # sensitive
+    # __generated__ by OpenTofu from "bar-id"
+    bar:
+      provider: !ref test.west
+      tags: !expr |-
+        jsonencode({
+          Name = "bar"
+        })
+  test_thing:
+    # __generated__ by OpenTofu from "baz-id"
+    baz: {}
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestMaybeWriteConfig_yamlNotConfig(t *testing.T) {
+	// Only the extensions of YAML configuration files select the YAML
+	// syntax, so any other YAML file gets the native syntax as before.
+	out := filepath.Join(t.TempDir(), "values.yaml")
+
+	change := Change{
+		Addr:            "test_thing.baz",
+		ImportID:        "baz-id",
+		GeneratedConfig: `resource "test_thing" "baz" {}`,
+	}
+	writer, _, diags := change.MaybeWriteConfig(nil, out)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+	if _, ok := writer.(*os.File); !ok {
+		t.Fatalf("wrong writer type %T", writer)
+	}
+	if diags := CloseConfigWriter(writer, out); diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# __generated__ by OpenTofu
+# Please review these resources and move them into your main configuration files.
+
+# __generated__ by OpenTofu from "baz-id"
+resource "test_thing" "baz" {}
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
@@ -0,0 +1,366 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package coverage tracks which objects in the configurations under test are
+// exercised by the run blocks of a test suite.
+package coverage
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/checks"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/lang"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+// ObjectKind is the kind of a configuration object tracked for coverage.
+type ObjectKind string
+
+const (
+	ResourceObject   ObjectKind = "resource"
+	DataSourceObject ObjectKind = "data"
+	OutputObject     ObjectKind = "output"
+	CheckObject      ObjectKind = "check"
+	VariableObject   ObjectKind = "variable"
+)
+
+// RuleKind is the kind of a custom condition tracked for coverage.
+type RuleKind string
+
+const (
+	PreconditionRule  RuleKind = "precondition"
+	PostconditionRule RuleKind = "postcondition"
+	ValidationRule    RuleKind = "validation"
+	AssertRule        RuleKind = "assert"
+)
+
+// Object is a single configuration object, along with what the run blocks
+// did with it.
+type Object struct {
+	Kind ObjectKind
+
+	// Address is the address of the object within the configuration where it
+	// was first seen. The same object can be part of more than one
+	// configuration under test, so objects are identified by Range instead.
+	Address string
+	Range   hcl.Range
+
+	// Planned and Applied are set for resources and data sources that were
+	// included in a plan or in the state after an apply.
+	Planned bool
+	Applied bool
+
+	// Asserted is set for objects that were referred to by the assertions of
+	// a run block.
+	Asserted bool
+
+	// Checked is set for objects whose rules were evaluated to a known result
+	// for any instance, or that a run block expected to fail.
+	//
+	// The results of checks are only tracked per instance rather than per
+	// rule, so the rules of an object are either all considered evaluated or
+	// none of them are.
+	Checked bool
+
+	Rules []*Rule
+}
+
+// Exercised returns true if any run block exercised the object.
+//
+// Resources and data sources are exercised when they are planned, output
+// values when they are asserted, and check blocks and variables when any of
+// their rules are evaluated.
+func (o *Object) Exercised() bool {
+	switch o.Kind {
+	case ResourceObject, DataSourceObject:
+		return o.Planned || o.Applied || o.Asserted
+	case OutputObject:
+		return o.Asserted
+	default:
+		return o.Checked
+	}
+}
+
+// Rule is a single custom condition declared by a configuration object.
+type Rule struct {
+	Kind RuleKind
+
+	// Index is the index of the rule among the rules of the same kind
+	// within the object.
+	Index int
+	Range hcl.Range
+}
+
+// Report collects the coverage of a test suite. It is safe for concurrent use.
+type Report struct {
+	mu      sync.Mutex
+	objects map[string]*Object
+}
+
+// NewReport returns an empty report.
+func NewReport() *Report {
+	return &Report{
+		objects: make(map[string]*Object),
+	}
+}
+
+// AddConfig adds all the objects in the given configuration and its
+// descendants to the report, if they are not already included.
+func (r *Report) AddConfig(config *configs.Config) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	config.DeepEach(func(c *configs.Config) {
+		module := c.Module
+		for _, res := range module.ManagedResources {
+			r.add(ResourceObject, res.Addr().InModule(c.Path).String(), res.DeclRange, resourceRules(res))
+		}
+		for _, res := range module.DataResources {
+			r.add(DataSourceObject, res.Addr().InModule(c.Path).String(), res.DeclRange, resourceRules(res))
+		}
+		for _, output := range module.Outputs {
+			r.add(OutputObject, output.Addr().InModule(c.Path).String(), output.DeclRange, rules(PreconditionRule, output.Preconditions))
+		}
+		for _, check := range module.Checks {
+			r.add(CheckObject, check.Addr().InModule(c.Path).String(), check.DeclRange, rules(AssertRule, check.Asserts))
+		}
+		for _, variable := range module.Variables {
+			if len(variable.Validations) == 0 {
+				// Variables are only interesting for their validations.
+				continue
+			}
+			r.add(VariableObject, variable.Addr().InModule(c.Path).String(), variable.DeclRange, rules(ValidationRule, variable.Validations))
+		}
+	})
+}
+
+func (r *Report) add(kind ObjectKind, addr string, rng hcl.Range, rules []*Rule) {
+	key := rng.String()
+	if _, exists := r.objects[key]; exists {
+		return
+	}
+	r.objects[key] = &Object{
+		Kind:    kind,
+		Address: addr,
+		Range:   rng,
+		Rules:   rules,
+	}
+}
+
+func resourceRules(res *configs.Resource) []*Rule {
+	return append(rules(PreconditionRule, res.Preconditions), rules(PostconditionRule, res.Postconditions)...)
+}
+
+func rules(kind RuleKind, rules []*configs.CheckRule) []*Rule {
+	var ret []*Rule
+	for ix, rule := range rules {
+		ret = append(ret, &Rule{
+			Kind:  kind,
+			Index: ix,
+			Range: rule.DeclRange,
+		})
+	}
+	return ret
+}
+
+// Planned records that the given resource in the given configuration was
+// included in a plan.
+func (r *Report) Planned(config *configs.Config, addr addrs.ConfigResource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if obj := r.resource(config, addr); obj != nil {
+		obj.Planned = true
+	}
+}
+
+// Applied records that the resources in the given state, which was created
+// by applying the given configuration, were applied.
+func (r *Report) Applied(config *configs.Config, state *states.State) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, module := range state.Modules {
+		for _, res := range module.Resources {
+			if obj := r.resource(config, res.Addr.Config()); obj != nil {
+				obj.Applied = true
+			}
+		}
+	}
+}
+
+// Checked records which objects had their rules evaluated according to the
+// given check results, which were produced for the given configuration.
+func (r *Report) Checked(config *configs.Config, results *states.CheckResults) {
+	if results == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, elem := range results.ConfigResults.Elems {
+		evaluated := false
+		for _, object := range elem.Value.ObjectResults.Elems {
+			if object.Value.Status != checks.StatusUnknown {
+				evaluated = true
+				break
+			}
+		}
+		if !evaluated {
+			continue
+		}
+
+		if obj := r.checkable(config, elem.Key); obj != nil {
+			obj.Checked = true
+		}
+	}
+}
+
+// Asserted records the objects in the given configuration that the given
+// assertions of a run block refer to.
+func (r *Report) Asserted(config *configs.Config, assertions []*configs.CheckRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, assertion := range assertions {
+		// Invalid references have already been reported while evaluating the
+		// assertions, so we can ignore them here.
+		refs, _ := lang.ReferencesInExpr(addrs.ParseRefFromTestingScope, assertion.Condition)
+		for _, ref := range refs {
+			var obj *Object
+			switch subject := ref.Subject.(type) {
+			case addrs.Resource:
+				obj = r.resource(config, subject.InModule(addrs.RootModule))
+			case addrs.ResourceInstance:
+				obj = r.resource(config, subject.ContainingResource().InModule(addrs.RootModule))
+			case addrs.OutputValue:
+				obj = r.checkable(config, subject.InModule(addrs.RootModule))
+			case addrs.ModuleCallInstanceOutput:
+				obj = r.checkable(config, addrs.OutputValue{Name: subject.Name}.InModule(addrs.RootModule.Child(subject.Call.Call.Name)))
+			case addrs.Check:
+				obj = r.checkable(config, subject.InModule(addrs.RootModule))
+			}
+			if obj != nil {
+				obj.Asserted = true
+			}
+		}
+	}
+}
+
+// ExpectedFailures records that the objects in the given configuration that a
+// run block expected to fail had their rules evaluated. This must only be
+// called once the expected failures have been verified.
+func (r *Report) ExpectedFailures(config *configs.Config, expectFailures []hcl.Traversal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, traversal := range expectFailures {
+		ref, diags := addrs.ParseRefFromTestingScope(traversal)
+		if diags.HasErrors() {
+			continue
+		}
+
+		var obj *Object
+		switch subject := ref.Subject.(type) {
+		case addrs.InputVariable:
+			obj = r.checkable(config, subject.InModule(addrs.RootModule))
+		case addrs.Resource:
+			obj = r.resource(config, subject.InModule(addrs.RootModule))
+		case addrs.ResourceInstance:
+			obj = r.resource(config, subject.ContainingResource().InModule(addrs.RootModule))
+		case addrs.OutputValue:
+			obj = r.checkable(config, subject.InModule(addrs.RootModule))
+		case addrs.Check:
+			obj = r.checkable(config, subject.InModule(addrs.RootModule))
+		}
+		if obj != nil {
+			obj.Checked = true
+		}
+	}
+}
+
+func (r *Report) resource(config *configs.Config, addr addrs.ConfigResource) *Object {
+	c := config.Descendent(addr.Module)
+	if c == nil {
+		return nil
+	}
+	res := c.Module.ResourceByAddr(addr.Resource)
+	if res == nil {
+		return nil
+	}
+	return r.objects[res.DeclRange.String()]
+}
+
+func (r *Report) checkable(config *configs.Config, addr addrs.ConfigCheckable) *Object {
+	switch addr := addr.(type) {
+	case addrs.ConfigResource:
+		return r.resource(config, addr)
+	case addrs.ConfigOutputValue:
+		if c := config.Descendent(addr.Module); c != nil {
+			if output, ok := c.Module.Outputs[addr.OutputValue.Name]; ok {
+				return r.objects[output.DeclRange.String()]
+			}
+		}
+	case addrs.ConfigCheck:
+		if c := config.Descendent(addr.Module); c != nil {
+			if check, ok := c.Module.Checks[addr.Check.Name]; ok {
+				return r.objects[check.DeclRange.String()]
+			}
+		}
+	case addrs.ConfigInputVariable:
+		if c := config.Descendent(addr.Module); c != nil {
+			if variable, ok := c.Module.Variables[addr.Variable.Name]; ok {
+				return r.objects[variable.DeclRange.String()]
+			}
+		}
+	}
+	return nil
+}
+
+// Objects returns all the objects in the report, ordered by their location
+// in the configuration.
+func (r *Report) Objects() []*Object {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ret := make([]*Object, 0, len(r.objects))
+	for _, obj := range r.objects {
+		ret = append(ret, obj)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		a, b := ret[i].Range, ret[j].Range
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Start.Byte < b.Start.Byte
+	})
+	return ret
+}
+
+// Summary counts the objects in the report.
+type Summary struct {
+	Objects          int
+	ExercisedObjects int
+}
+
+// Summary returns the overall counts of objects in the report.
+func (r *Report) Summary() Summary {
+	var ret Summary
+	for _, obj := range r.Objects() {
+		// Variables are only included because of their validation rules, so
+		// they are not counted as objects in their own right.
+		if obj.Kind != VariableObject {
+			ret.Objects++
+			if obj.Exercised() {
+				ret.ExercisedObjects++
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,217 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package coverage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/spf13/afero"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/checks"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+const testConfig = `
+variable "name" {
+  type = string
+
+  validation {
+    condition     = length(var.name) > 0
+    error_message = "The name must not be empty."
+  }
+}
+
+resource "test_resource" "a" {
+  value = var.name
+
+  lifecycle {
+    precondition {
+      condition     = var.name != "a"
+      error_message = "The name must not be a."
+    }
+    postcondition {
+      condition     = self.value != null
+      error_message = "The value must be set."
+    }
+  }
+}
+
+resource "test_resource" "unused" {
+}
+
+data "test_data" "b" {
+}
+
+output "out" {
+  value = test_resource.a.value
+}
+
+check "c" {
+  assert {
+    condition     = test_resource.a.value != ""
+    error_message = "The value must not be empty."
+  }
+}
+`
+
+func testLoadConfig(t *testing.T) *configs.Config {
+	t.Helper()
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "main.tf", []byte(testConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mod, diags := configs.NewParser(fs).LoadConfigDir(".", configs.RootModuleCallForTesting())
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	config, diags := configs.BuildConfig(context.Background(), mod, configs.DisabledModuleWalker)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	return config
+}
+
+func testAssertion(t *testing.T, src string) *configs.CheckRule {
+	t.Helper()
+
+	expr, diags := hclsyntax.ParseExpression([]byte(src), "main.tftest.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	return &configs.CheckRule{Condition: expr}
+}
+
+func TestReport(t *testing.T) {
+	config := testLoadConfig(t)
+
+	report := NewReport()
+	report.AddConfig(config)
+	report.AddConfig(config) // objects must not be duplicated
+
+	resourceA := addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_resource", Name: "a"}
+	dataB := addrs.Resource{Mode: addrs.DataResourceMode, Type: "test_data", Name: "b"}
+
+	report.Planned(config, resourceA.InModule(addrs.RootModule))
+	report.Applied(config, states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			dataB.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{Status: states.ObjectReady, AttrsJSON: []byte(`{}`)},
+			addrs.AbsProviderConfig{Provider: addrs.NewDefaultProvider("test"), Module: addrs.RootModule},
+			addrs.NoKey)
+	}))
+
+	results := &states.CheckResults{ConfigResults: addrs.MakeMap[addrs.ConfigCheckable, *states.CheckResultAggregate]()}
+	resourceResults := &states.CheckResultAggregate{
+		Status:        checks.StatusPass,
+		ObjectResults: addrs.MakeMap[addrs.Checkable, *states.CheckResultObject](),
+	}
+	resourceResults.ObjectResults.Put(resourceA.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance), &states.CheckResultObject{Status: checks.StatusPass})
+	results.ConfigResults.Put(resourceA.InModule(addrs.RootModule), resourceResults)
+	variableResults := &states.CheckResultAggregate{
+		Status:        checks.StatusUnknown,
+		ObjectResults: addrs.MakeMap[addrs.Checkable, *states.CheckResultObject](),
+	}
+	variableResults.ObjectResults.Put(addrs.InputVariable{Name: "name"}.Absolute(addrs.RootModuleInstance), &states.CheckResultObject{Status: checks.StatusUnknown})
+	results.ConfigResults.Put(addrs.InputVariable{Name: "name"}.InModule(addrs.RootModule), variableResults)
+	report.Checked(config, results)
+
+	report.Asserted(config, []*configs.CheckRule{
+		testAssertion(t, `output.out == "a"`),
+		testAssertion(t, `check.c != null && var.name != ""`),
+	})
+
+	report.ExpectedFailures(config, []hcl.Traversal{
+		{hcl.TraverseRoot{Name: "check"}, hcl.TraverseAttr{Name: "c"}},
+	})
+
+	type result struct {
+		Address   string
+		Exercised bool
+		Checked   bool
+		Rules     int
+	}
+	var got []result
+	for _, obj := range report.Objects() {
+		got = append(got, result{
+			Address:   obj.Address,
+			Exercised: obj.Exercised(),
+			Checked:   obj.Checked,
+			Rules:     len(obj.Rules),
+		})
+	}
+	want := []result{
+		{Address: "var.name", Exercised: false, Checked: false, Rules: 1},
+		{Address: "test_resource.a", Exercised: true, Checked: true, Rules: 2},
+		{Address: "test_resource.unused", Exercised: false},
+		{Address: "data.test_data.b", Exercised: true},
+		{Address: "output.out", Exercised: true},
+		{Address: "check.c", Exercised: true, Checked: true, Rules: 1},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong coverage\n%s", diff)
+	}
+
+	wantSummary := Summary{
+		Objects:          5,
+		ExercisedObjects: 4,
+	}
+	if diff := cmp.Diff(wantSummary, report.Summary()); diff != "" {
+		t.Errorf("wrong summary\n%s", diff)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	config := testLoadConfig(t)
+
+	report := NewReport()
+	report.AddConfig(config)
+	report.Planned(config, addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_resource", Name: "a"}.InModule(addrs.RootModule))
+
+	src, err := Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		FormatVersion string `json:"format_version"`
+		Summary       struct {
+			Objects          int `json:"objects"`
+			ExercisedObjects int `json:"exercised_objects"`
+		} `json:"summary"`
+		Files map[string][]struct {
+			Address   string `json:"address"`
+			Exercised bool   `json:"exercised"`
+			Range     struct {
+				Start struct {
+					Line int `json:"line"`
+				} `json:"start"`
+			} `json:"range"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(src, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.FormatVersion != "1.0" {
+		t.Errorf("wrong format version %q", got.FormatVersion)
+	}
+	if got.Summary.Objects != 5 || got.Summary.ExercisedObjects != 1 {
+		t.Errorf("wrong summary %+v", got.Summary)
+	}
+	objects := got.Files["main.tf"]
+	if len(objects) != 6 {
+		t.Fatalf("wrong number of objects in main.tf: %d", len(objects))
+	}
+	if obj := objects[1]; obj.Address != "test_resource.a" || !obj.Exercised || obj.Range.Start.Line != 11 {
+		t.Errorf("wrong object %+v", obj)
+	}
+}
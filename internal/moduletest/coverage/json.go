@@ -0,0 +1,97 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package coverage
+
+import (
+	"encoding/json"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+const formatVersion = "1.0"
+
+type reportJSON struct {
+	FormatVersion string                `json:"format_version"`
+	Summary       summaryJSON           `json:"summary"`
+	Files         map[string][]*objJSON `json:"files"`
+}
+
+type summaryJSON struct {
+	Objects          int `json:"objects"`
+	ExercisedObjects int `json:"exercised_objects"`
+}
+
+type objJSON struct {
+	Kind      ObjectKind  `json:"kind"`
+	Address   string      `json:"address"`
+	Range     rangeJSON   `json:"range"`
+	Exercised bool        `json:"exercised"`
+	Planned   bool        `json:"planned,omitempty"`
+	Applied   bool        `json:"applied,omitempty"`
+	Asserted  bool        `json:"asserted,omitempty"`
+	Checked   bool        `json:"checked,omitempty"`
+	Rules     []*ruleJSON `json:"rules,omitempty"`
+}
+
+type ruleJSON struct {
+	Kind  RuleKind  `json:"kind"`
+	Index int       `json:"index"`
+	Range rangeJSON `json:"range"`
+}
+
+type rangeJSON struct {
+	Start posJSON `json:"start"`
+	End   posJSON `json:"end"`
+}
+
+type posJSON struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+func newRangeJSON(rng hcl.Range) rangeJSON {
+	return rangeJSON{
+		Start: posJSON{Line: rng.Start.Line, Column: rng.Start.Column, Byte: rng.Start.Byte},
+		End:   posJSON{Line: rng.End.Line, Column: rng.End.Column, Byte: rng.End.Byte},
+	}
+}
+
+// Marshal returns a JSON document describing the coverage of every object in
+// the given report, grouped by the file that declares it and ordered by its
+// location within the file.
+func Marshal(report *Report) ([]byte, error) {
+	summary := report.Summary()
+	ret := reportJSON{
+		FormatVersion: formatVersion,
+		Summary: summaryJSON{
+			Objects:          summary.Objects,
+			ExercisedObjects: summary.ExercisedObjects,
+		},
+		Files: make(map[string][]*objJSON),
+	}
+
+	for _, obj := range report.Objects() {
+		o := &objJSON{
+			Kind:      obj.Kind,
+			Address:   obj.Address,
+			Range:     newRangeJSON(obj.Range),
+			Exercised: obj.Exercised(),
+			Planned:   obj.Planned,
+			Applied:   obj.Applied,
+			Asserted:  obj.Asserted,
+			Checked:   obj.Checked,
+		}
+		for _, rule := range obj.Rules {
+			o.Rules = append(o.Rules, &ruleJSON{
+				Kind:  rule.Kind,
+				Index: rule.Index,
+				Range: newRangeJSON(rule.Range),
+			})
+		}
+		ret.Files[obj.Range.Filename] = append(ret.Files[obj.Range.Filename], o)
+	}
+
+	return json.MarshalIndent(ret, "", "  ")
+}
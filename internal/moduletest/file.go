@@ -6,6 +6,9 @@
 package moduletest
 
 import (
+	"path/filepath"
+	"time"
+
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
@@ -18,5 +21,17 @@ type File struct {
 
 	Runs []*Run
 
+	// Duration is how long it took to execute the file, including cleaning
+	// up any infrastructure that it created.
+	Duration time.Duration
+
 	Diagnostics tfdiags.Diagnostics
 }
+
+// RunFilename returns the path of a JSON file holding data for the named run
+// block within the given test file. The files are kept in the given directory
+// next to the test file, in a subdirectory named after the test file.
+func RunFilename(testFile string, dir string, run string) string {
+	base := configs.TrimTestFileExt(filepath.Base(testFile))
+	return filepath.Join(filepath.Dir(testFile), dir, base, run+".json")
+}
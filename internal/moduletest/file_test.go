@@ -0,0 +1,25 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package moduletest
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRunFilename(t *testing.T) {
+	tcs := map[string]string{
+		"main.tftest.hcl":          filepath.Join("fixtures", "main", "setup.json"),
+		"main.tofutest.hcl":        filepath.Join("fixtures", "main", "setup.json"),
+		"tests/main.tftest.json":   filepath.Join("tests", "fixtures", "main", "setup.json"),
+		"tests/main.tftest.yaml":   filepath.Join("tests", "fixtures", "main", "setup.json"),
+		"tests/main.tofutest.yml":  filepath.Join("tests", "fixtures", "main", "setup.json"),
+		"tests/other.tftest.hcl.x": filepath.Join("tests", "fixtures", "other.tftest.hcl.x", "setup.json"),
+	}
+	for file, want := range tcs {
+		if got := RunFilename(file, "fixtures", "setup"); got != want {
+			t.Errorf("wrong filename for %s: got %s, want %s", file, got, want)
+		}
+	}
+}
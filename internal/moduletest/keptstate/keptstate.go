@@ -0,0 +1,232 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package keptstate stores the states created by the run blocks of a test
+// file on disk, for when "tofu test" is asked to keep the infrastructure it
+// created rather than destroy it.
+//
+// The states for each test file are written as regular state files, so they
+// can be inspected with commands like "tofu state list -state=PATH", along
+// with a manifest recording which run block last updated each state. The
+// manifest allows the test command to destroy the kept infrastructure later
+// using the same configuration as it would have used during its own cleanup.
+package keptstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+)
+
+// DefaultDir is the directory, relative to the working directory, that the
+// test command keeps states in.
+const DefaultDir = ".terraform/test-states"
+
+const (
+	formatVersion = "1.0"
+	manifestName  = "manifest.json"
+)
+
+// State is a single state kept for a test file.
+type State struct {
+	// Key identifies the configuration the state belongs to. It is empty for
+	// the main configuration under test, and otherwise the source of the
+	// module that the run blocks loaded.
+	Key string
+
+	// Run is the name of the run block that last updated the state.
+	Run string
+
+	// Path is the path of the state file, which is only set for states that
+	// have been written or read.
+	Path string
+
+	State *states.State
+}
+
+type manifestJSON struct {
+	FormatVersion string       `json:"format_version"`
+	File          string       `json:"file"`
+	States        []*stateJSON `json:"states"`
+}
+
+type stateJSON struct {
+	Key  string `json:"key"`
+	Run  string `json:"run"`
+	Path string `json:"path"`
+}
+
+// Write writes the given states for the named test file into dir, replacing
+// any states previously kept for the file. The Path of each state is updated
+// to the file it was written to.
+//
+// The states are written to a temporary directory that then replaces the
+// directory for the file, so the previously kept states are left untouched
+// if any of the new states can't be written.
+func Write(dir string, file string, kept []*State) error {
+	fileDir := filepath.Join(dir, file)
+	parent, base := filepath.Split(fileDir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(parent, "."+base+".*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sort.Slice(kept, func(i, j int) bool {
+		return kept[i].Key < kept[j].Key
+	})
+
+	manifest := manifestJSON{
+		FormatVersion: formatVersion,
+		File:          file,
+	}
+	for ix, state := range kept {
+		name := "main.tfstate"
+		if state.Key != "" {
+			name = fmt.Sprintf("module_%d.tfstate", ix)
+		}
+		if err := writeStateFile(filepath.Join(tmpDir, name), state.State); err != nil {
+			return err
+		}
+		manifest.States = append(manifest.States, &stateJSON{
+			Key:  state.Key,
+			Run:  state.Run,
+			Path: name,
+		})
+	}
+
+	src, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, manifestName), append(src, '\n'), 0644); err != nil {
+		return err
+	}
+
+	if err := replaceDir(fileDir, tmpDir); err != nil {
+		return err
+	}
+	for ix, state := range kept {
+		state.Path = filepath.Join(fileDir, manifest.States[ix].Path)
+	}
+	return nil
+}
+
+// replaceDir replaces the directory at path with the directory at newDir,
+// which must be in the same parent directory.
+func replaceDir(path string, newDir string) error {
+	oldDir := newDir + ".old"
+	if err := os.Rename(path, oldDir); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return os.Rename(newDir, path)
+	}
+	if err := os.Rename(newDir, path); err != nil {
+		// Put the previous states back, so that they are not lost.
+		return errors.Join(err, os.Rename(oldDir, path))
+	}
+	return os.RemoveAll(oldDir)
+}
+
+// Read returns the states kept for the named test file in dir.
+func Read(dir string, file string) ([]*State, error) {
+	fileDir := filepath.Join(dir, file)
+	src, err := os.ReadFile(filepath.Join(fileDir, manifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest manifestJSON
+	if err := json.Unmarshal(src, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.FormatVersion != formatVersion {
+		return nil, fmt.Errorf("unsupported manifest format version %q", manifest.FormatVersion)
+	}
+
+	var kept []*State
+	for _, state := range manifest.States {
+		path := filepath.Join(fileDir, state.Path)
+		f, err := readStateFile(path)
+		if err != nil {
+			return nil, err
+		}
+		kept = append(kept, &State{
+			Key:   state.Key,
+			Run:   state.Run,
+			Path:  path,
+			State: f,
+		})
+	}
+	return kept, nil
+}
+
+// Files returns the names of the test files that have states kept in dir, in
+// alphabetical order.
+func Files(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != dir && strings.HasPrefix(d.Name(), ".") {
+			// Skip the temporary directories left behind by an interrupted
+			// Write.
+			return filepath.SkipDir
+		}
+		if d.IsDir() || d.Name() != manifestName {
+			return nil
+		}
+		file, err := filepath.Rel(dir, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(file))
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	sort.Strings(files)
+	return files, err
+}
+
+// Remove deletes the states kept for the named test file in dir.
+func Remove(dir string, file string) error {
+	return os.RemoveAll(filepath.Join(dir, file))
+}
+
+func writeStateFile(path string, state *states.State) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return statefile.Write(statefile.New(state, "", 0), f, encryption.StateEncryptionDisabled())
+}
+
+func readStateFile(path string) (*states.State, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sf, err := statefile.Read(f, encryption.StateEncryptionDisabled())
+	if err != nil {
+		return nil, err
+	}
+	return sf.State, nil
+}
@@ -0,0 +1,145 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package keptstate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestWriteRead(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "test-states")
+
+	resource := addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_resource", Name: "a"}
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			resource.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{Status: states.ObjectReady, AttrsJSON: []byte(`{"id":"a"}`)},
+			addrs.AbsProviderConfig{Provider: addrs.NewDefaultProvider("test"), Module: addrs.RootModule},
+			addrs.NoKey)
+	})
+
+	if err := Write(dir, "tests/main.tftest.hcl", []*State{
+		{Key: "./setup", Run: "setup", State: states.NewState()},
+		{Key: "", Run: "second", State: state},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(dir, "other.tftest.hcl", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := Files(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"other.tftest.hcl", "tests/main.tftest.hcl"}, files); diff != "" {
+		t.Errorf("wrong files\n%s", diff)
+	}
+
+	kept, err := Read(dir, "tests/main.tftest.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 2 {
+		t.Fatalf("expected 2 states, got %d", len(kept))
+	}
+	if got := kept[0]; got.Key != "" || got.Run != "second" || got.Path != filepath.Join(dir, "tests", "main.tftest.hcl", "main.tfstate") {
+		t.Errorf("wrong main state %+v", got)
+	}
+	if !kept[0].State.HasManagedResourceInstanceObjects() {
+		t.Errorf("main state lost its resources")
+	}
+	if got := kept[1]; got.Key != "./setup" || got.Run != "setup" || got.Path != filepath.Join(dir, "tests", "main.tftest.hcl", "module_1.tfstate") {
+		t.Errorf("wrong module state %+v", got)
+	}
+
+	if err := Remove(dir, "tests/main.tftest.hcl"); err != nil {
+		t.Fatal(err)
+	}
+	files, err = Files(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"other.tftest.hcl"}, files); diff != "" {
+		t.Errorf("wrong files after removal\n%s", diff)
+	}
+}
+
+func TestFiles_missingDir(t *testing.T) {
+	files, err := Files(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("expected no files, got %v", files)
+	}
+}
+
+func TestWrite_replace(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "test-states")
+
+	if err := Write(dir, "main.tftest.hcl", []*State{
+		{Key: "", Run: "first", State: states.NewState()},
+		{Key: "./setup", Run: "setup", State: states.NewState()},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	kept := []*State{
+		{Key: "", Run: "second", State: states.NewState()},
+	}
+	if err := Write(dir, "main.tftest.hcl", kept); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := kept[0].Path, filepath.Join(dir, "main.tftest.hcl", "main.tfstate"); got != want {
+		t.Errorf("wrong path %q, want %q", got, want)
+	}
+
+	// The previous states are replaced, and no temporary directories are
+	// left behind.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "main.tftest.hcl" {
+		t.Errorf("unexpected entries in %s: %v", dir, entries)
+	}
+	entries, err = os.ReadDir(filepath.Join(dir, "main.tftest.hcl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if diff := cmp.Diff([]string{"main.tfstate", "manifest.json"}, names); diff != "" {
+		t.Errorf("wrong files\n%s", diff)
+	}
+}
+
+func TestFiles_skipsTemporaryDirs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "test-states")
+
+	if err := Write(dir, "main.tftest.hcl", nil); err != nil {
+		t.Fatal(err)
+	}
+	// A directory left behind by an interrupted Write.
+	if err := Write(dir, ".main.tftest.hcl.123", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := Files(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"main.tftest.hcl"}, files); diff != "" {
+		t.Errorf("wrong files\n%s", diff)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package recording
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	ctymsgpack "github.com/zclconf/go-cty/cty/msgpack"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// value is the encoding of a cty.Value in a fixture file.
+//
+// Values that are wholly known are encoded as JSON so that the fixture files
+// are readable. JSON can't represent unknown values, so any other values are
+// encoded using MessagePack instead.
+type value struct {
+	Type    json.RawMessage `json:"type"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	MsgPack []byte          `json:"msgpack,omitempty"`
+}
+
+// pathStep is the encoding of a single step of a cty.Path in a fixture file.
+// Exactly one of the fields is set.
+type pathStep struct {
+	Attr  string `json:"attr,omitempty"`
+	Index *value `json:"index,omitempty"`
+}
+
+// diagnostic is the encoding of a diagnostic returned by a provider in a
+// fixture file.
+//
+// Only the description of the diagnostic is recorded. When replayed, the
+// diagnostic is attached to the whole configuration of the resource instead
+// of the specific attribute that the provider reported.
+type diagnostic struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+type readResourceRequest struct {
+	PriorState   *value `json:"prior_state"`
+	Private      []byte `json:"private,omitempty"`
+	ProviderMeta *value `json:"provider_meta,omitempty"`
+}
+
+type readResourceResponse struct {
+	NewState    *value       `json:"new_state"`
+	Private     []byte       `json:"private,omitempty"`
+	Diagnostics []diagnostic `json:"diagnostics,omitempty"`
+}
+
+type planResourceChangeRequest struct {
+	PriorState       *value `json:"prior_state"`
+	ProposedNewState *value `json:"proposed_new_state"`
+	Config           *value `json:"config"`
+	PriorPrivate     []byte `json:"prior_private,omitempty"`
+	ProviderMeta     *value `json:"provider_meta,omitempty"`
+}
+
+type planResourceChangeResponse struct {
+	PlannedState     *value       `json:"planned_state"`
+	RequiresReplace  [][]pathStep `json:"requires_replace,omitempty"`
+	PlannedPrivate   []byte       `json:"planned_private,omitempty"`
+	Diagnostics      []diagnostic `json:"diagnostics,omitempty"`
+	LegacyTypeSystem bool         `json:"legacy_type_system,omitempty"`
+}
+
+type applyResourceChangeRequest struct {
+	PriorState     *value `json:"prior_state"`
+	PlannedState   *value `json:"planned_state"`
+	Config         *value `json:"config"`
+	PlannedPrivate []byte `json:"planned_private,omitempty"`
+	ProviderMeta   *value `json:"provider_meta,omitempty"`
+}
+
+type applyResourceChangeResponse struct {
+	NewState         *value       `json:"new_state"`
+	Private          []byte       `json:"private,omitempty"`
+	Diagnostics      []diagnostic `json:"diagnostics,omitempty"`
+	LegacyTypeSystem bool         `json:"legacy_type_system,omitempty"`
+}
+
+type readDataSourceRequest struct {
+	Config       *value `json:"config"`
+	ProviderMeta *value `json:"provider_meta,omitempty"`
+}
+
+type readDataSourceResponse struct {
+	State       *value       `json:"state"`
+	Diagnostics []diagnostic `json:"diagnostics,omitempty"`
+}
+
+// encoder encodes values for a fixture file, keeping the first error it
+// encounters so that a whole request or response can be encoded before
+// checking for errors.
+type encoder struct {
+	err error
+}
+
+func (e *encoder) value(v cty.Value) *value {
+	if e.err != nil || v == cty.NilVal {
+		return nil
+	}
+
+	// Providers never receive marked values, but we'll make sure.
+	v, _ = v.UnmarkDeep()
+
+	ty, err := ctyjson.MarshalType(v.Type())
+	if err != nil {
+		e.err = err
+		return nil
+	}
+
+	ret := &value{Type: ty}
+	if v.IsWhollyKnown() {
+		ret.Value, err = ctyjson.Marshal(v, v.Type())
+	} else {
+		ret.MsgPack, err = ctymsgpack.Marshal(v, v.Type())
+	}
+	if err != nil {
+		e.err = err
+		return nil
+	}
+	return ret
+}
+
+func (e *encoder) paths(paths []cty.Path) [][]pathStep {
+	var ret [][]pathStep
+	for _, path := range paths {
+		steps := make([]pathStep, 0, len(path))
+		for _, step := range path {
+			switch step := step.(type) {
+			case cty.GetAttrStep:
+				steps = append(steps, pathStep{Attr: step.Name})
+			case cty.IndexStep:
+				steps = append(steps, pathStep{Index: e.value(step.Key)})
+			default:
+				if e.err == nil {
+					e.err = fmt.Errorf("unsupported path step %T", step)
+				}
+			}
+		}
+		ret = append(ret, steps)
+	}
+	return ret
+}
+
+func (e *encoder) diagnostics(diags tfdiags.Diagnostics) []diagnostic {
+	var ret []diagnostic
+	for _, diag := range diags {
+		desc := diag.Description()
+		severity := "error"
+		if diag.Severity() == tfdiags.Warning {
+			severity = "warning"
+		}
+		ret = append(ret, diagnostic{
+			Severity: severity,
+			Summary:  desc.Summary,
+			Detail:   desc.Detail,
+		})
+	}
+	return ret
+}
+
+// decoder decodes values from a fixture file, keeping the first error it
+// encounters.
+type decoder struct {
+	err error
+}
+
+func (d *decoder) value(v *value) cty.Value {
+	if d.err != nil || v == nil {
+		return cty.NilVal
+	}
+
+	ty, err := ctyjson.UnmarshalType(v.Type)
+	if err != nil {
+		d.err = err
+		return cty.NilVal
+	}
+
+	var ret cty.Value
+	if v.MsgPack != nil {
+		ret, err = ctymsgpack.Unmarshal(v.MsgPack, ty)
+	} else {
+		ret, err = ctyjson.Unmarshal(v.Value, ty)
+	}
+	if err != nil {
+		d.err = err
+		return cty.NilVal
+	}
+	return ret
+}
+
+func (d *decoder) paths(paths [][]pathStep) []cty.Path {
+	var ret []cty.Path
+	for _, steps := range paths {
+		var path cty.Path
+		for _, step := range steps {
+			if step.Index != nil {
+				path = path.Index(d.value(step.Index))
+			} else {
+				path = path.GetAttr(step.Attr)
+			}
+		}
+		ret = append(ret, path)
+	}
+	return ret
+}
+
+func (d *decoder) diagnostics(diags []diagnostic) tfdiags.Diagnostics {
+	var ret tfdiags.Diagnostics
+	for _, diag := range diags {
+		severity := tfdiags.Error
+		if diag.Severity == "warning" {
+			severity = tfdiags.Warning
+		}
+		ret = ret.Append(tfdiags.WholeContainingBody(severity, diag.Summary, diag.Detail))
+	}
+	return ret
+}
@@ -0,0 +1,254 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package recording implements recording the interactions between OpenTofu
+// and its providers while executing the run blocks of a test file, and
+// replaying those interactions later without calling the real providers.
+//
+// Only the calls that read or change remote objects are recorded. All other
+// calls, such as fetching the provider schema or validating configuration,
+// are still handled by the real provider, which is never configured when
+// replaying and so doesn't need any credentials.
+package recording
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+const formatVersion = "1.0"
+
+const (
+	methodReadResource        = "ReadResource"
+	methodPlanResourceChange  = "PlanResourceChange"
+	methodApplyResourceChange = "ApplyResourceChange"
+	methodReadDataSource      = "ReadDataSource"
+)
+
+// Fixture holds the interactions with providers that were recorded while
+// executing a single run block, including the interactions made while
+// destroying the infrastructure that the run block created.
+//
+// A Fixture is safe for concurrent use, as providers are called concurrently
+// while walking the graph.
+type Fixture struct {
+	filename string
+
+	mu           sync.Mutex
+	interactions []*interaction
+
+	// used tracks which of the interactions have already been replayed, so
+	// each recorded response is only replayed once.
+	used []bool
+}
+
+type fixtureJSON struct {
+	FormatVersion string         `json:"format_version"`
+	Interactions  []*interaction `json:"interactions"`
+}
+
+// interaction is a single call to a provider along with its response. The
+// request and response are encoded according to the method that was called.
+type interaction struct {
+	Provider string          `json:"provider"`
+	Method   string          `json:"method"`
+	TypeName string          `json:"type_name"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response"`
+}
+
+// NewFixture returns an empty fixture that will be saved to the given file.
+func NewFixture(filename string) *Fixture {
+	return &Fixture{
+		filename: filename,
+	}
+}
+
+// LoadFixture reads a previously saved fixture from the given file.
+func LoadFixture(filename string) (*Fixture, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw fixtureJSON
+	if err := json.Unmarshal(src, &raw); err != nil {
+		return nil, fmt.Errorf("invalid fixture file %s: %w", filename, err)
+	}
+	if raw.FormatVersion != formatVersion {
+		return nil, fmt.Errorf("fixture file %s has unsupported format version %q", filename, raw.FormatVersion)
+	}
+
+	for _, i := range raw.Interactions {
+		// The saved file is indented, but we compare requests against the
+		// compact encoding.
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, i.Request); err != nil {
+			return nil, fmt.Errorf("invalid fixture file %s: %w", filename, err)
+		}
+		i.Request = buf.Bytes()
+	}
+
+	return &Fixture{
+		filename:     filename,
+		interactions: raw.Interactions,
+		used:         make([]bool, len(raw.Interactions)),
+	}, nil
+}
+
+// Filename returns the file that the fixture is saved to or loaded from.
+func (f *Fixture) Filename() string {
+	return f.filename
+}
+
+// Save writes all of the recorded interactions to the fixture file, creating
+// its directory if necessary.
+func (f *Fixture) Save() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	interactions := f.interactions
+	if interactions == nil {
+		interactions = []*interaction{}
+	}
+	src, err := json.MarshalIndent(fixtureJSON{
+		FormatVersion: formatVersion,
+		Interactions:  interactions,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(f.filename, append(src, '\n'), 0644)
+}
+
+func (f *Fixture) record(provider addrs.Provider, method string, typeName string, req any, resp any) error {
+	rawReq, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	rawResp, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.interactions = append(f.interactions, &interaction{
+		Provider: provider.String(),
+		Method:   method,
+		TypeName: typeName,
+		Request:  rawReq,
+		Response: rawResp,
+	})
+	return nil
+}
+
+// replay finds the first recorded interaction that hasn't been replayed yet
+// and has exactly the same request, and decodes its response into resp.
+//
+// The interactions aren't replayed in the order they were recorded, because
+// OpenTofu calls providers concurrently and so the order of the calls can
+// differ between executions.
+func (f *Fixture) replay(provider addrs.Provider, method string, typeName string, req any, resp any) error {
+	rawReq, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var candidate *interaction
+	for ix, i := range f.interactions {
+		if f.used[ix] || i.Provider != provider.String() || i.Method != method || i.TypeName != typeName {
+			continue
+		}
+		if bytes.Equal(i.Request, rawReq) {
+			f.used[ix] = true
+			return json.Unmarshal(i.Response, resp)
+		}
+		if candidate == nil {
+			candidate = i
+		}
+	}
+
+	if candidate == nil {
+		return &NotRecordedError{
+			Filename: f.filename,
+			Method:   method,
+			TypeName: typeName,
+		}
+	}
+	return &MismatchError{
+		Filename: f.filename,
+		Method:   method,
+		TypeName: typeName,
+		Fields:   differentFields(candidate.Request, rawReq),
+	}
+}
+
+// differentFields returns the names of the top-level fields that are
+// different between the two encoded requests.
+func differentFields(a, b json.RawMessage) []string {
+	var fieldsA, fieldsB map[string]json.RawMessage
+	if json.Unmarshal(a, &fieldsA) != nil || json.Unmarshal(b, &fieldsB) != nil {
+		return nil
+	}
+
+	var ret []string
+	for name, value := range fieldsA {
+		if !bytes.Equal(value, fieldsB[name]) {
+			ret = append(ret, name)
+		}
+	}
+	for name := range fieldsB {
+		if _, exists := fieldsA[name]; !exists {
+			ret = append(ret, name)
+		}
+	}
+	slices.Sort(ret)
+	return ret
+}
+
+// NotRecordedError is returned when replaying a request that there are no
+// remaining recorded interactions for.
+type NotRecordedError struct {
+	Filename string
+	Method   string
+	TypeName string
+}
+
+func (err *NotRecordedError) Error() string {
+	return fmt.Sprintf("no %s request for %s remains in %s", err.Method, err.TypeName, err.Filename)
+}
+
+// MismatchError is returned when replaying a request that doesn't match any
+// of the recorded requests for the same method and type.
+type MismatchError struct {
+	Filename string
+	Method   string
+	TypeName string
+
+	// Fields are the names of the fields of the request that differ from
+	// the first unused recorded request.
+	Fields []string
+}
+
+func (err *MismatchError) Error() string {
+	if len(err.Fields) == 0 {
+		return fmt.Sprintf("the %s request for %s does not match any request in %s", err.Method, err.TypeName, err.Filename)
+	}
+	return fmt.Sprintf("the %s request for %s does not match any request in %s; the closest recorded request has different %s", err.Method, err.TypeName, err.Filename, strings.Join(err.Fields, ", "))
+}
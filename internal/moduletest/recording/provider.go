@@ -0,0 +1,397 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// Record returns a factory for providers that forward every call to the
+// providers created by the given factory, recording the calls that read or
+// change remote objects into the fixture.
+func Record(addr addrs.Provider, factory providers.Factory, fixture *Fixture) providers.Factory {
+	return func() (providers.Interface, error) {
+		provider, err := factory()
+		if err != nil {
+			return nil, err
+		}
+		return &recorder{
+			Interface: provider,
+			addr:      addr,
+			fixture:   fixture,
+		}, nil
+	}
+}
+
+// Replay returns a factory for providers that serve the calls that read or
+// change remote objects from the fixture instead of calling the providers
+// created by the given factory. The real providers are still used for the
+// calls that don't depend on any remote objects, such as fetching the schema,
+// but they are never configured.
+func Replay(addr addrs.Provider, factory providers.Factory, fixture *Fixture) providers.Factory {
+	return func() (providers.Interface, error) {
+		provider, err := factory()
+		if err != nil {
+			return nil, err
+		}
+		return &replayer{
+			internal: provider,
+			addr:     addr,
+			fixture:  fixture,
+		}, nil
+	}
+}
+
+var _ providers.Interface = (*recorder)(nil)
+
+// recorder embeds the real provider, so all the calls it doesn't record are
+// forwarded unchanged.
+type recorder struct {
+	providers.Interface
+
+	addr    addrs.Provider
+	fixture *Fixture
+}
+
+func (p *recorder) ReadResource(ctx context.Context, req providers.ReadResourceRequest) providers.ReadResourceResponse {
+	resp := p.Interface.ReadResource(ctx, req)
+
+	var enc encoder
+	recReq := readResourceRequest{
+		PriorState:   enc.value(req.PriorState),
+		Private:      req.Private,
+		ProviderMeta: enc.value(req.ProviderMeta),
+	}
+	recResp := readResourceResponse{
+		NewState:    enc.value(resp.NewState),
+		Private:     resp.Private,
+		Diagnostics: enc.diagnostics(resp.Diagnostics),
+	}
+	resp.Diagnostics = resp.Diagnostics.Append(p.record(methodReadResource, req.TypeName, recReq, recResp, enc.err))
+	return resp
+}
+
+func (p *recorder) PlanResourceChange(ctx context.Context, req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+	resp := p.Interface.PlanResourceChange(ctx, req)
+
+	var enc encoder
+	recReq := planResourceChangeRequest{
+		PriorState:       enc.value(req.PriorState),
+		ProposedNewState: enc.value(req.ProposedNewState),
+		Config:           enc.value(req.Config),
+		PriorPrivate:     req.PriorPrivate,
+		ProviderMeta:     enc.value(req.ProviderMeta),
+	}
+	recResp := planResourceChangeResponse{
+		PlannedState:     enc.value(resp.PlannedState),
+		RequiresReplace:  enc.paths(resp.RequiresReplace),
+		PlannedPrivate:   resp.PlannedPrivate,
+		Diagnostics:      enc.diagnostics(resp.Diagnostics),
+		LegacyTypeSystem: resp.LegacyTypeSystem,
+	}
+	resp.Diagnostics = resp.Diagnostics.Append(p.record(methodPlanResourceChange, req.TypeName, recReq, recResp, enc.err))
+	return resp
+}
+
+func (p *recorder) ApplyResourceChange(ctx context.Context, req providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+	resp := p.Interface.ApplyResourceChange(ctx, req)
+
+	var enc encoder
+	recReq := applyResourceChangeRequest{
+		PriorState:     enc.value(req.PriorState),
+		PlannedState:   enc.value(req.PlannedState),
+		Config:         enc.value(req.Config),
+		PlannedPrivate: req.PlannedPrivate,
+		ProviderMeta:   enc.value(req.ProviderMeta),
+	}
+	recResp := applyResourceChangeResponse{
+		NewState:         enc.value(resp.NewState),
+		Private:          resp.Private,
+		Diagnostics:      enc.diagnostics(resp.Diagnostics),
+		LegacyTypeSystem: resp.LegacyTypeSystem,
+	}
+	resp.Diagnostics = resp.Diagnostics.Append(p.record(methodApplyResourceChange, req.TypeName, recReq, recResp, enc.err))
+	return resp
+}
+
+func (p *recorder) ReadDataSource(ctx context.Context, req providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+	resp := p.Interface.ReadDataSource(ctx, req)
+
+	var enc encoder
+	recReq := readDataSourceRequest{
+		Config:       enc.value(req.Config),
+		ProviderMeta: enc.value(req.ProviderMeta),
+	}
+	recResp := readDataSourceResponse{
+		State:       enc.value(resp.State),
+		Diagnostics: enc.diagnostics(resp.Diagnostics),
+	}
+	resp.Diagnostics = resp.Diagnostics.Append(p.record(methodReadDataSource, req.TypeName, recReq, recResp, enc.err))
+	return resp
+}
+
+func (p *recorder) record(method string, typeName string, req any, resp any, err error) tfdiags.Diagnostics {
+	if err == nil {
+		err = p.fixture.record(p.addr, method, typeName, req, resp)
+	}
+
+	var diags tfdiags.Diagnostics
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to record provider interaction",
+			fmt.Sprintf("OpenTofu could not record the %s call for %s in %s: %s.", method, typeName, p.fixture.Filename(), err)))
+	}
+	return diags
+}
+
+var _ providers.Interface = (*replayer)(nil)
+
+type replayer struct {
+	// providers.Interface is not embedded so that every new method must be
+	// considered explicitly, as forwarding a call that changes remote objects
+	// to the real provider would break the guarantees of replaying.
+	internal providers.Interface
+
+	addr    addrs.Provider
+	fixture *Fixture
+}
+
+func (p *replayer) ReadResource(_ context.Context, req providers.ReadResourceRequest) providers.ReadResourceResponse {
+	var resp providers.ReadResourceResponse
+
+	var enc encoder
+	recReq := readResourceRequest{
+		PriorState:   enc.value(req.PriorState),
+		Private:      req.Private,
+		ProviderMeta: enc.value(req.ProviderMeta),
+	}
+
+	var recResp readResourceResponse
+	if diags := p.replay(methodReadResource, req.TypeName, recReq, &recResp, enc.err); diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+
+	var dec decoder
+	resp.NewState = dec.value(recResp.NewState)
+	resp.Private = recResp.Private
+	resp.Diagnostics = dec.diagnostics(recResp.Diagnostics)
+	resp.Diagnostics = resp.Diagnostics.Append(p.decodeErr(methodReadResource, req.TypeName, dec.err))
+	return resp
+}
+
+func (p *replayer) PlanResourceChange(_ context.Context, req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+	var resp providers.PlanResourceChangeResponse
+
+	var enc encoder
+	recReq := planResourceChangeRequest{
+		PriorState:       enc.value(req.PriorState),
+		ProposedNewState: enc.value(req.ProposedNewState),
+		Config:           enc.value(req.Config),
+		PriorPrivate:     req.PriorPrivate,
+		ProviderMeta:     enc.value(req.ProviderMeta),
+	}
+
+	var recResp planResourceChangeResponse
+	if diags := p.replay(methodPlanResourceChange, req.TypeName, recReq, &recResp, enc.err); diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+
+	var dec decoder
+	resp.PlannedState = dec.value(recResp.PlannedState)
+	resp.RequiresReplace = dec.paths(recResp.RequiresReplace)
+	resp.PlannedPrivate = recResp.PlannedPrivate
+	resp.LegacyTypeSystem = recResp.LegacyTypeSystem
+	resp.Diagnostics = dec.diagnostics(recResp.Diagnostics)
+	resp.Diagnostics = resp.Diagnostics.Append(p.decodeErr(methodPlanResourceChange, req.TypeName, dec.err))
+	return resp
+}
+
+func (p *replayer) ApplyResourceChange(_ context.Context, req providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+	var resp providers.ApplyResourceChangeResponse
+
+	var enc encoder
+	recReq := applyResourceChangeRequest{
+		PriorState:     enc.value(req.PriorState),
+		PlannedState:   enc.value(req.PlannedState),
+		Config:         enc.value(req.Config),
+		PlannedPrivate: req.PlannedPrivate,
+		ProviderMeta:   enc.value(req.ProviderMeta),
+	}
+
+	var recResp applyResourceChangeResponse
+	if diags := p.replay(methodApplyResourceChange, req.TypeName, recReq, &recResp, enc.err); diags.HasErrors() {
+		// We can't know what happened, so we'll keep the prior state.
+		resp.NewState = req.PriorState
+		resp.Diagnostics = diags
+		return resp
+	}
+
+	var dec decoder
+	resp.NewState = dec.value(recResp.NewState)
+	resp.Private = recResp.Private
+	resp.LegacyTypeSystem = recResp.LegacyTypeSystem
+	resp.Diagnostics = dec.diagnostics(recResp.Diagnostics)
+	resp.Diagnostics = resp.Diagnostics.Append(p.decodeErr(methodApplyResourceChange, req.TypeName, dec.err))
+	return resp
+}
+
+func (p *replayer) ReadDataSource(_ context.Context, req providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+	var resp providers.ReadDataSourceResponse
+
+	var enc encoder
+	recReq := readDataSourceRequest{
+		Config:       enc.value(req.Config),
+		ProviderMeta: enc.value(req.ProviderMeta),
+	}
+
+	var recResp readDataSourceResponse
+	if diags := p.replay(methodReadDataSource, req.TypeName, recReq, &recResp, enc.err); diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+
+	var dec decoder
+	resp.State = dec.value(recResp.State)
+	resp.Diagnostics = dec.diagnostics(recResp.Diagnostics)
+	resp.Diagnostics = resp.Diagnostics.Append(p.decodeErr(methodReadDataSource, req.TypeName, dec.err))
+	return resp
+}
+
+func (p *replayer) replay(method string, typeName string, req any, resp any, err error) tfdiags.Diagnostics {
+	if err == nil {
+		err = p.fixture.replay(p.addr, method, typeName, req, resp)
+	}
+
+	var diags tfdiags.Diagnostics
+	var notRecorded *NotRecordedError
+	var mismatch *MismatchError
+	switch {
+	case err == nil:
+	case errors.As(err, &notRecorded):
+		diags = diags.Append(tfdiags.WholeContainingBody(
+			tfdiags.Error,
+			"Provider request was not recorded",
+			fmt.Sprintf("OpenTofu made a %s request for %s that was not recorded in %s. The configuration or the test file has probably changed since the interactions were recorded, so they must be recorded again using -record-providers.", method, typeName, p.fixture.Filename())))
+	case errors.As(err, &mismatch):
+		detail := fmt.Sprintf("The %s request for %s does not match any request recorded in %s.", method, typeName, p.fixture.Filename())
+		if len(mismatch.Fields) > 0 {
+			detail += fmt.Sprintf(" The closest recorded request has a different %s.", strings.Join(mismatch.Fields, ", "))
+		}
+		detail += " The configuration or the test file has probably changed since the interactions were recorded, so they must be recorded again using -record-providers."
+		diags = diags.Append(tfdiags.WholeContainingBody(tfdiags.Error, "Provider request does not match the recording", detail))
+	default:
+		diags = diags.Append(tfdiags.WholeContainingBody(
+			tfdiags.Error,
+			"Failed to replay provider interaction",
+			fmt.Sprintf("OpenTofu could not replay the %s request for %s from %s: %s.", method, typeName, p.fixture.Filename(), err)))
+	}
+	return diags
+}
+
+func (p *replayer) decodeErr(method string, typeName string, err error) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if err != nil {
+		diags = diags.Append(tfdiags.WholeContainingBody(
+			tfdiags.Error,
+			"Failed to replay provider interaction",
+			fmt.Sprintf("OpenTofu could not decode the recorded response to the %s request for %s from %s: %s.", method, typeName, p.fixture.Filename(), err)))
+	}
+	return diags
+}
+
+func (p *replayer) GetProviderSchema(ctx context.Context) providers.GetProviderSchemaResponse {
+	return p.internal.GetProviderSchema(ctx)
+}
+
+// The validation calls don't depend on any remote objects, so the real
+// provider can still make them.
+
+func (p *replayer) ValidateProviderConfig(ctx context.Context, req providers.ValidateProviderConfigRequest) providers.ValidateProviderConfigResponse {
+	return p.internal.ValidateProviderConfig(ctx, req)
+}
+
+func (p *replayer) ValidateResourceConfig(ctx context.Context, req providers.ValidateResourceConfigRequest) providers.ValidateResourceConfigResponse {
+	return p.internal.ValidateResourceConfig(ctx, req)
+}
+
+func (p *replayer) ValidateDataResourceConfig(ctx context.Context, req providers.ValidateDataResourceConfigRequest) providers.ValidateDataResourceConfigResponse {
+	return p.internal.ValidateDataResourceConfig(ctx, req)
+}
+
+func (p *replayer) ValidateEphemeralConfig(ctx context.Context, req providers.ValidateEphemeralConfigRequest) providers.ValidateEphemeralConfigResponse {
+	return p.internal.ValidateEphemeralConfig(ctx, req)
+}
+
+func (p *replayer) UpgradeResourceState(ctx context.Context, req providers.UpgradeResourceStateRequest) providers.UpgradeResourceStateResponse {
+	return p.internal.UpgradeResourceState(ctx, req)
+}
+
+// ConfigureProvider doesn't configure the real provider, so that replaying
+// never needs any credentials.
+func (p *replayer) ConfigureProvider(context.Context, providers.ConfigureProviderRequest) providers.ConfigureProviderResponse {
+	return providers.ConfigureProviderResponse{}
+}
+
+func (p *replayer) MoveResourceState(_ context.Context, req providers.MoveResourceStateRequest) providers.MoveResourceStateResponse {
+	var resp providers.MoveResourceStateResponse
+	resp.Diagnostics = resp.Diagnostics.Append(p.unsupported("Moving resources between types", req.TargetTypeName))
+	return resp
+}
+
+func (p *replayer) ImportResourceState(_ context.Context, req providers.ImportResourceStateRequest) providers.ImportResourceStateResponse {
+	var resp providers.ImportResourceStateResponse
+	resp.Diagnostics = resp.Diagnostics.Append(p.unsupported("Importing resources", req.TypeName))
+	return resp
+}
+
+func (p *replayer) OpenEphemeralResource(_ context.Context, req providers.OpenEphemeralResourceRequest) providers.OpenEphemeralResourceResponse {
+	var resp providers.OpenEphemeralResourceResponse
+	resp.Diagnostics = resp.Diagnostics.Append(p.unsupported("Ephemeral resources", req.TypeName))
+	return resp
+}
+
+func (p *replayer) RenewEphemeralResource(_ context.Context, req providers.RenewEphemeralResourceRequest) providers.RenewEphemeralResourceResponse {
+	var resp providers.RenewEphemeralResourceResponse
+	resp.Diagnostics = resp.Diagnostics.Append(p.unsupported("Ephemeral resources", req.TypeName))
+	return resp
+}
+
+func (p *replayer) CloseEphemeralResource(_ context.Context, req providers.CloseEphemeralResourceRequest) providers.CloseEphemeralResourceResponse {
+	var resp providers.CloseEphemeralResourceResponse
+	resp.Diagnostics = resp.Diagnostics.Append(p.unsupported("Ephemeral resources", req.TypeName))
+	return resp
+}
+
+func (p *replayer) unsupported(what string, typeName string) tfdiags.Diagnostic {
+	return tfdiags.WholeContainingBody(
+		tfdiags.Error,
+		"Unsupported provider request",
+		fmt.Sprintf("%s is not supported when replaying recorded provider interactions, so OpenTofu can't replay the request for %s.", what, typeName))
+}
+
+func (p *replayer) GetFunctions(ctx context.Context) providers.GetFunctionsResponse {
+	return p.internal.GetFunctions(ctx)
+}
+
+func (p *replayer) CallFunction(ctx context.Context, req providers.CallFunctionRequest) providers.CallFunctionResponse {
+	return p.internal.CallFunction(ctx, req)
+}
+
+func (p *replayer) Stop(ctx context.Context) error {
+	return p.internal.Stop(ctx)
+}
+
+func (p *replayer) Close(ctx context.Context) error {
+	return p.internal.Close(ctx)
+}
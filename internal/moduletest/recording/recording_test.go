@@ -0,0 +1,192 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package recording
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// fakeProvider implements only the calls that are recorded, and counts how
+// many times it was called.
+type fakeProvider struct {
+	providers.Interface
+
+	calls int
+}
+
+func (p *fakeProvider) PlanResourceChange(_ context.Context, req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+	p.calls++
+	planned := req.ProposedNewState.AsValueMap()
+	planned["id"] = cty.UnknownVal(cty.String)
+	return providers.PlanResourceChangeResponse{
+		PlannedState:    cty.ObjectVal(planned),
+		RequiresReplace: []cty.Path{cty.GetAttrPath("value"), cty.GetAttrPath("tags").IndexString("name")},
+		PlannedPrivate:  []byte("private"),
+	}
+}
+
+func (p *fakeProvider) ApplyResourceChange(_ context.Context, req providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+	p.calls++
+	state := req.PlannedState.AsValueMap()
+	state["id"] = cty.StringVal("abc123")
+	return providers.ApplyResourceChangeResponse{
+		NewState: cty.ObjectVal(state),
+	}
+}
+
+func (p *fakeProvider) ReadResource(_ context.Context, req providers.ReadResourceRequest) providers.ReadResourceResponse {
+	p.calls++
+	return providers.ReadResourceResponse{
+		NewState: req.PriorState,
+	}
+}
+
+func (p *fakeProvider) ReadDataSource(_ context.Context, req providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+	p.calls++
+	var diags tfdiags.Diagnostics
+	diags = diags.Append(tfdiags.SimpleWarning("Deprecated data source"))
+	return providers.ReadDataSourceResponse{
+		State:       req.Config,
+		Diagnostics: diags,
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	ctx := context.Background()
+	addr := addrs.NewDefaultProvider("test")
+	filename := filepath.Join(t.TempDir(), "fixtures", "main", "setup.json")
+
+	config := cty.ObjectVal(map[string]cty.Value{
+		"id":    cty.NullVal(cty.String),
+		"value": cty.StringVal("hello"),
+		"tags":  cty.MapVal(map[string]cty.Value{"name": cty.StringVal("web")}),
+	})
+	planReq := providers.PlanResourceChangeRequest{
+		TypeName:         "test_resource",
+		PriorState:       cty.NullVal(config.Type()),
+		ProposedNewState: config,
+		Config:           config,
+	}
+	readReq := providers.ReadDataSourceRequest{
+		TypeName: "test_data",
+		Config:   cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("web")}),
+	}
+
+	// First we record the interactions with the real provider.
+	real := &fakeProvider{}
+	fixture := NewFixture(filename)
+	provider, err := Record(addr, providers.FactoryFixed(real), fixture)()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recordedPlan := provider.PlanResourceChange(ctx, planReq)
+	if recordedPlan.Diagnostics.HasErrors() {
+		t.Fatal(recordedPlan.Diagnostics.Err())
+	}
+	applyReq := providers.ApplyResourceChangeRequest{
+		TypeName:       "test_resource",
+		PriorState:     planReq.PriorState,
+		PlannedState:   recordedPlan.PlannedState,
+		Config:         config,
+		PlannedPrivate: recordedPlan.PlannedPrivate,
+	}
+	recordedApply := provider.ApplyResourceChange(ctx, applyReq)
+	recordedRead := provider.ReadDataSource(ctx, readReq)
+	if err := fixture.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if real.calls != 3 {
+		t.Fatalf("expected 3 calls to the real provider, got %d", real.calls)
+	}
+
+	// Then we replay them, without ever calling the real provider.
+	fixture, err = LoadFixture(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, err = Replay(addr, providers.FactoryFixed(real), fixture)()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The calls can be replayed in a different order.
+	read := provider.ReadDataSource(ctx, readReq)
+	if !read.State.RawEquals(recordedRead.State) {
+		t.Errorf("wrong data source state\ngot:  %#v\nwant: %#v", read.State, recordedRead.State)
+	}
+	if len(read.Diagnostics) != 1 || read.Diagnostics[0].Severity() != tfdiags.Warning || read.Diagnostics[0].Description().Summary != "Deprecated data source" {
+		t.Errorf("wrong diagnostics: %#v", read.Diagnostics)
+	}
+
+	plan := provider.PlanResourceChange(ctx, planReq)
+	if plan.Diagnostics.HasErrors() {
+		t.Fatal(plan.Diagnostics.Err())
+	}
+	if !plan.PlannedState.RawEquals(recordedPlan.PlannedState) {
+		t.Errorf("wrong planned state\ngot:  %#v\nwant: %#v", plan.PlannedState, recordedPlan.PlannedState)
+	}
+	if len(plan.RequiresReplace) != 2 || !plan.RequiresReplace[1].Equals(recordedPlan.RequiresReplace[1]) {
+		t.Errorf("wrong requires replace: %#v", plan.RequiresReplace)
+	}
+	if string(plan.PlannedPrivate) != "private" {
+		t.Errorf("wrong planned private: %q", plan.PlannedPrivate)
+	}
+
+	apply := provider.ApplyResourceChange(ctx, applyReq)
+	if !apply.NewState.RawEquals(recordedApply.NewState) {
+		t.Errorf("wrong new state\ngot:  %#v\nwant: %#v", apply.NewState, recordedApply.NewState)
+	}
+
+	if real.calls != 3 {
+		t.Errorf("expected no more calls to the real provider, got %d", real.calls-3)
+	}
+
+	// Each interaction is only replayed once.
+	again := provider.ReadDataSource(ctx, readReq)
+	if got, want := again.Diagnostics.Err().Error(), "Provider request was not recorded"; !strings.Contains(got, want) {
+		t.Errorf("wrong error %q; want %q", got, want)
+	}
+}
+
+func TestReplay_mismatch(t *testing.T) {
+	ctx := context.Background()
+	addr := addrs.NewDefaultProvider("test")
+
+	fixture := NewFixture("setup.json")
+	provider, _ := Record(addr, providers.FactoryFixed(&fakeProvider{}), fixture)()
+	provider.ReadResource(ctx, providers.ReadResourceRequest{
+		TypeName:   "test_resource",
+		PriorState: cty.ObjectVal(map[string]cty.Value{"value": cty.StringVal("hello")}),
+		Private:    []byte("private"),
+	})
+
+	fixture.used = make([]bool, len(fixture.interactions))
+	provider, _ = Replay(addr, providers.FactoryFixed(&fakeProvider{}), fixture)()
+	resp := provider.ReadResource(ctx, providers.ReadResourceRequest{
+		TypeName:   "test_resource",
+		PriorState: cty.ObjectVal(map[string]cty.Value{"value": cty.StringVal("goodbye")}),
+		Private:    []byte("private"),
+	})
+
+	if len(resp.Diagnostics) != 1 {
+		t.Fatalf("expected one diagnostic, got %#v", resp.Diagnostics)
+	}
+	desc := resp.Diagnostics[0].Description()
+	if desc.Summary != "Provider request does not match the recording" {
+		t.Errorf("wrong summary %q", desc.Summary)
+	}
+	if want := "The closest recorded request has a different prior_state."; !strings.Contains(desc.Detail, want) {
+		t.Errorf("detail %q does not include %q", desc.Detail, want)
+	}
+}
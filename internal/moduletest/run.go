@@ -7,6 +7,8 @@ package moduletest
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 
@@ -28,6 +30,10 @@ type Run struct {
 	Index  int
 	Status Status
 
+	// Duration is how long it took to execute the run block, or zero if it
+	// was not executed.
+	Duration time.Duration
+
 	Diagnostics tfdiags.Diagnostics
 }
 
@@ -341,3 +347,201 @@ func (run *Run) BuildExpectedFailuresAndSourceMaps() (addrs.Map[addrs.Referencea
 	}
 	return expectedFailures, sourceRanges
 }
+
+// ExpectedDiagnostic tracks whether one of the expect_diagnostic blocks of a
+// run block has been matched by a diagnostic.
+type ExpectedDiagnostic struct {
+	Config *configs.TestRunExpectedDiagnostic
+
+	// Object is the checkable object referenced by the expect_diagnostic
+	// block, or nil if it doesn't reference one.
+	Object addrs.Referenceable
+
+	// Found is true once at least one diagnostic has matched.
+	Found bool
+}
+
+// BuildExpectedDiagnostics captures the diagnostics that are expected by the
+// expect_diagnostic blocks of the run block.
+func (run *Run) BuildExpectedDiagnostics() []*ExpectedDiagnostic {
+	var expected []*ExpectedDiagnostic
+	for _, config := range run.Config.ExpectDiagnostics {
+		diagnostic := &ExpectedDiagnostic{
+			Config: config,
+		}
+		if config.Object != nil {
+			// As with the expected failures, these references will have been
+			// checked earlier by the validate stage.
+			reference, _ := addrs.ParseRefFromTestingScope(config.Object)
+			diagnostic.Object = reference.Subject
+		}
+		expected = append(expected, diagnostic)
+	}
+	return expected
+}
+
+// ValidateExpectedDiagnostics steps through the provided diagnostics (which
+// should be the result of a plan or an apply operation), and removes any that
+// match the expected diagnostics. It should be called before
+// ValidateExpectedFailures, as it also marks the expected failures that are
+// satisfied by the diagnostics it removes.
+//
+// Failed check block assertions are reported by OpenTofu as warnings, but are
+// considered to be errors here as they are by ValidateExpectedFailures.
+// Similarly, errors from data sources nested in check blocks are considered
+// with their original severity.
+func (run *Run) ValidateExpectedDiagnostics(expected []*ExpectedDiagnostic, expectedFailures addrs.Map[addrs.Referenceable, bool], originals tfdiags.Diagnostics) tfdiags.Diagnostics {
+	if len(expected) == 0 {
+		return originals
+	}
+
+	var diags tfdiags.Diagnostics
+	for _, diag := range originals {
+		severity := diag.Severity()
+		var objects []addrs.Referenceable
+		if rule, ok := addrs.DiagnosticOriginatesFromCheckRule(diag); ok {
+			switch rule.Type {
+			case addrs.CheckAssertion:
+				if rule.Container.CheckableKind() == addrs.CheckableCheck {
+					severity = tfdiags.Error
+				}
+			case addrs.CheckDataResource:
+				severity = tfdiags.UndoOverride(diag).Severity()
+			}
+			objects = checkRuleObjects(rule)
+		}
+
+		matched := false
+		for _, expectation := range expected {
+			if expectation.matches(diag, severity, objects) {
+				expectation.Found = true
+				matched = true
+			}
+		}
+		if !matched {
+			diags = diags.Append(diag)
+			continue
+		}
+
+		if severity == tfdiags.Error {
+			// The failure of the checkable object has been expected in more
+			// detail, so it also satisfies any expected failure.
+			for _, object := range objects {
+				if expectedFailures.Has(object) {
+					expectedFailures.Put(object, true)
+				}
+			}
+		}
+	}
+	return diags
+}
+
+// MissingExpectedDiagnostics returns an error for each of the expected
+// diagnostics that were not matched.
+func (run *Run) MissingExpectedDiagnostics(expected []*ExpectedDiagnostic) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	for _, expectation := range expected {
+		if expectation.Found {
+			continue
+		}
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Missing expected diagnostic",
+			Detail:   fmt.Sprintf("Expected %s, but no matching diagnostic was reported.", expectation.describe()),
+			Subject:  expectation.Config.DeclRange.Ptr(),
+		})
+	}
+	return diags
+}
+
+func (expectation *ExpectedDiagnostic) matches(diag tfdiags.Diagnostic, severity tfdiags.Severity, objects []addrs.Referenceable) bool {
+	config := expectation.Config
+	if severity != config.Severity {
+		return false
+	}
+
+	if expectation.Object != nil {
+		found := false
+		for _, object := range objects {
+			if object.String() == expectation.Object.String() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	desc := diag.Description()
+	if len(config.Summary) > 0 && desc.Summary != config.Summary {
+		return false
+	}
+	if config.SummaryRegex != nil && !config.SummaryRegex.MatchString(desc.Summary) {
+		return false
+	}
+	if len(config.Detail) > 0 && desc.Detail != config.Detail {
+		return false
+	}
+	if config.DetailRegex != nil && !config.DetailRegex.MatchString(desc.Detail) {
+		return false
+	}
+	return true
+}
+
+func (expectation *ExpectedDiagnostic) describe() string {
+	config := expectation.Config
+
+	var buf strings.Builder
+	switch config.Severity {
+	case tfdiags.Warning:
+		buf.WriteString("a warning")
+	default:
+		buf.WriteString("an error")
+	}
+	if expectation.Object != nil {
+		fmt.Fprintf(&buf, " from %s", expectation.Object)
+	}
+
+	var conditions []string
+	if len(config.Summary) > 0 {
+		conditions = append(conditions, fmt.Sprintf("the summary %q", config.Summary))
+	}
+	if config.SummaryRegex != nil {
+		conditions = append(conditions, fmt.Sprintf("a summary matching %q", config.SummaryRegex))
+	}
+	if len(config.Detail) > 0 {
+		conditions = append(conditions, fmt.Sprintf("the detail %q", config.Detail))
+	}
+	if config.DetailRegex != nil {
+		conditions = append(conditions, fmt.Sprintf("a detail matching %q", config.DetailRegex))
+	}
+	if len(conditions) > 0 {
+		fmt.Fprintf(&buf, " with %s", strings.Join(conditions, " and "))
+	}
+	return buf.String()
+}
+
+// checkRuleObjects returns the checkable objects in the root module that
+// expected failures and diagnostics can refer to for the given check rule.
+func checkRuleObjects(rule addrs.CheckRule) []addrs.Referenceable {
+	switch addr := rule.Container.(type) {
+	case addrs.AbsOutputValue:
+		if addr.Module.IsRoot() {
+			return []addrs.Referenceable{addr.OutputValue}
+		}
+	case addrs.AbsInputVariableInstance:
+		if addr.Module.IsRoot() {
+			return []addrs.Referenceable{addr.Variable}
+		}
+	case addrs.AbsResourceInstance:
+		if addr.Module.IsRoot() {
+			return []addrs.Referenceable{addr.Resource, addr.Resource.Resource}
+		}
+	case addrs.AbsCheck:
+		if addr.Module.IsRoot() {
+			return []addrs.Referenceable{addr.Check}
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@
 package moduletest
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -802,3 +803,197 @@ func createDiagnostics(populate func(diags tfdiags.Diagnostics) tfdiags.Diagnost
 	diags = populate(diags)
 	return diags
 }
+
+func TestRun_ValidateExpectedDiagnostics(t *testing.T) {
+
+	type output struct {
+		Description tfdiags.Description
+		Severity    tfdiags.Severity
+	}
+
+	variableValidation := func(name string, summary string, detail string) tfdiags.Diagnostic {
+		var diags tfdiags.Diagnostics
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  summary,
+			Detail:   detail,
+			Extra: &addrs.CheckRuleDiagnosticExtra{
+				CheckRule: addrs.NewCheckRule(addrs.AbsInputVariableInstance{
+					Module:   addrs.RootModuleInstance,
+					Variable: addrs.InputVariable{Name: name},
+				}, addrs.InputValidation, 0),
+			},
+		})[0]
+	}
+
+	checkAssertion := func(name string, detail string) tfdiags.Diagnostic {
+		var diags tfdiags.Diagnostics
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Check block assertion failed",
+			Detail:   detail,
+			Extra: &addrs.CheckRuleDiagnosticExtra{
+				CheckRule: addrs.NewCheckRule(addrs.AbsCheck{
+					Module: addrs.RootModuleInstance,
+					Check:  addrs.Check{Name: name},
+				}, addrs.CheckAssertion, 0),
+			},
+		})[0]
+	}
+
+	tcs := map[string]struct {
+		ExpectedFailures    []string
+		ExpectedDiagnostics []*configs.TestRunExpectedDiagnostic
+		Input               tfdiags.Diagnostics
+		Output              []output
+	}{
+		"no expectations": {
+			Input: tfdiags.Diagnostics{
+				tfdiags.Sourceless(tfdiags.Warning, "simple warning", "want to see this in the returned set"),
+			},
+			Output: []output{
+				{
+					Description: tfdiags.Description{
+						Summary: "simple warning",
+						Detail:  "want to see this in the returned set",
+					},
+					Severity: tfdiags.Warning,
+				},
+			},
+		},
+		"exact match satisfies expected failure": {
+			ExpectedFailures: []string{"var.input"},
+			ExpectedDiagnostics: []*configs.TestRunExpectedDiagnostic{
+				{
+					Severity: tfdiags.Error,
+					Object:   parseTraversal(t, "var.input"),
+					Summary:  "Invalid value for variable",
+					Detail:   "The input must be lowercase.",
+				},
+			},
+			Input: tfdiags.Diagnostics{
+				variableValidation("input", "Invalid value for variable", "The input must be lowercase."),
+			},
+		},
+		"regex match on warnings": {
+			ExpectedDiagnostics: []*configs.TestRunExpectedDiagnostic{
+				{
+					Severity:     tfdiags.Warning,
+					SummaryRegex: regexp.MustCompile("^Deprecated"),
+				},
+			},
+			Input: tfdiags.Diagnostics{
+				tfdiags.Sourceless(tfdiags.Warning, "Deprecated value used", "first"),
+				tfdiags.Sourceless(tfdiags.Warning, "Deprecated value used", "second"),
+				tfdiags.Sourceless(tfdiags.Warning, "Something else", "should remain"),
+			},
+			Output: []output{
+				{
+					Description: tfdiags.Description{
+						Summary: "Something else",
+						Detail:  "should remain",
+					},
+					Severity: tfdiags.Warning,
+				},
+			},
+		},
+		"check assertions are errors": {
+			ExpectedDiagnostics: []*configs.TestRunExpectedDiagnostic{
+				{
+					Severity:    tfdiags.Error,
+					Object:      parseTraversal(t, "check.expected"),
+					DetailRegex: regexp.MustCompile("must be positive"),
+				},
+			},
+			Input: tfdiags.Diagnostics{
+				checkAssertion("expected", "The value must be positive."),
+				checkAssertion("unexpected", "The value must be positive."),
+			},
+			Output: []output{
+				{
+					Description: tfdiags.Description{
+						Summary: "Check block assertion failed",
+						Detail:  "The value must be positive.",
+					},
+					Severity: tfdiags.Error,
+				},
+			},
+		},
+		"mismatched diagnostics are reported": {
+			ExpectedFailures: []string{"var.input"},
+			ExpectedDiagnostics: []*configs.TestRunExpectedDiagnostic{
+				{
+					Severity: tfdiags.Error,
+					Object:   parseTraversal(t, "var.input"),
+					Detail:   "The input must be uppercase.",
+				},
+				{
+					Severity: tfdiags.Warning,
+					Summary:  "Invalid value for variable",
+				},
+			},
+			Input: tfdiags.Diagnostics{
+				variableValidation("input", "Invalid value for variable", "The input must be lowercase."),
+			},
+			Output: []output{
+				{
+					Description: tfdiags.Description{
+						Summary: "Missing expected diagnostic",
+						Detail:  "Expected an error from var.input with the detail \"The input must be uppercase.\", but no matching diagnostic was reported.",
+					},
+					Severity: tfdiags.Error,
+				},
+				{
+					Description: tfdiags.Description{
+						Summary: "Missing expected diagnostic",
+						Detail:  "Expected a warning with the summary \"Invalid value for variable\", but no matching diagnostic was reported.",
+					},
+					Severity: tfdiags.Error,
+				},
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			var traversals []hcl.Traversal
+			for _, ef := range tc.ExpectedFailures {
+				traversals = append(traversals, parseTraversal(t, ef))
+			}
+
+			run := Run{
+				Config: &configs.TestRun{
+					ExpectFailures:    traversals,
+					ExpectDiagnostics: tc.ExpectedDiagnostics,
+				},
+			}
+			expectedFailures, sourceRanges := run.BuildExpectedFailuresAndSourceMaps()
+			expectedDiagnostics := run.BuildExpectedDiagnostics()
+
+			out := run.ValidateExpectedDiagnostics(expectedDiagnostics, expectedFailures, tc.Input)
+			out = run.ValidateExpectedFailures(expectedFailures, sourceRanges, out)
+			out = out.Append(run.MissingExpectedDiagnostics(expectedDiagnostics))
+
+			var actual []output
+			for _, diag := range out {
+				actual = append(actual, output{
+					Description: diag.Description(),
+					Severity:    diag.Severity(),
+				})
+			}
+			if diff := cmp.Diff(tc.Output, actual); len(diff) > 0 {
+				t.Errorf("wrong diagnostics:\n%s", diff)
+			}
+		})
+	}
+}
+
+func parseTraversal(t *testing.T, addr string) hcl.Traversal {
+	t.Helper()
+
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(addr), "main.tftest.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("invalid address %s: %s", addr, diags.Error())
+	}
+	return traversal
+}
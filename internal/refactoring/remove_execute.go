@@ -0,0 +1,57 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package refactoring
+
+import (
+	"log"
+	"sort"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+// ApplyRemoves modifies in-place the given state object so that any existing
+// resources that are matched by the "from" argument of one of the remove
+// statements are no longer tracked in the state, without destroying the
+// remote objects they represent.
+//
+// The result is the addresses of all of the resource instances that were
+// removed, in the same order as addrs.AbsResourceInstance.Less sorts them.
+//
+// ApplyRemoves ignores the Destroy and Provisioners fields of the statements,
+// because destroying objects requires a plan. Callers that cannot plan should
+// reject statements that request destruction before calling ApplyRemoves.
+//
+// Like ApplyMoves, ApplyRemoves expects exclusive access to the given state
+// while it's running.
+func ApplyRemoves(stmts []*RemoveStatement, state *states.State) []addrs.AbsResourceInstance {
+	var removed []addrs.AbsResourceInstance
+	var resources []addrs.AbsResource
+
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			for _, stmt := range stmts {
+				if !stmt.From.TargetContains(rs.Addr) {
+					continue
+				}
+				log.Printf("[TRACE] refactoring.ApplyRemoves: resource %s is removed by the statement at %s", rs.Addr, stmt.DeclRange.StartString())
+				for key := range rs.Instances {
+					removed = append(removed, rs.Addr.Instance(key))
+				}
+				resources = append(resources, rs.Addr)
+				break
+			}
+		}
+	}
+
+	ss := state.SyncWrapper()
+	for _, addr := range resources {
+		ss.RemoveResource(addr)
+	}
+
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].Less(removed[j])
+	})
+	return removed
+}
@@ -0,0 +1,110 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package refactoring
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestApplyRemoves(t *testing.T) {
+	providerAddr := addrs.AbsProviderConfig{
+		Module:   addrs.RootModule,
+		Provider: addrs.MustParseProviderSourceString("example.com/foo/bar"),
+	}
+
+	buildState := func(addrStrs ...string) *states.State {
+		return states.BuildState(func(s *states.SyncState) {
+			for _, addrStr := range addrStrs {
+				addr, err := addrs.ParseAbsResourceInstanceStr(addrStr)
+				if err != nil {
+					t.Fatal(err)
+				}
+				s.SetResourceInstanceCurrent(
+					addr,
+					&states.ResourceInstanceObjectSrc{
+						Status:    states.ObjectReady,
+						AttrsJSON: []byte(`{}`),
+					},
+					providerAddr,
+					addrs.NoKey,
+				)
+			}
+		})
+	}
+
+	tests := map[string]struct {
+		Stmts []*RemoveStatement
+		State *states.State
+
+		WantRemoved       []string
+		WantInstanceAddrs []string
+	}{
+		"no removes": {
+			nil,
+			buildState("foo.a"),
+			nil,
+			[]string{"foo.a"},
+		},
+		"resource with count": {
+			[]*RemoveStatement{
+				{From: mustConfigResourceAddr("foo.a")},
+			},
+			buildState("foo.a[0]", "foo.a[1]", "foo.b"),
+			[]string{"foo.a[0]", "foo.a[1]"},
+			[]string{"foo.b"},
+		},
+		"resource in every instance of a module": {
+			[]*RemoveStatement{
+				{From: mustConfigResourceAddr("module.child.foo.a")},
+			},
+			buildState("module.child[0].foo.a", "module.child[1].foo.a", "module.child[1].foo.b", "foo.a"),
+			[]string{"module.child[0].foo.a", "module.child[1].foo.a"},
+			[]string{"foo.a", "module.child[1].foo.b"},
+		},
+		"module and its descendants": {
+			[]*RemoveStatement{
+				{From: addrs.Module{"child"}},
+			},
+			buildState("module.child.foo.a", "module.child.module.grandchild.foo.a", "module.other.foo.a"),
+			[]string{"module.child.foo.a", "module.child.module.grandchild.foo.a"},
+			[]string{"module.other.foo.a"},
+		},
+		"no matches": {
+			[]*RemoveStatement{
+				{From: mustConfigResourceAddr("foo.missing")},
+			},
+			buildState("foo.a"),
+			nil,
+			[]string{"foo.a"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotRemoved []string
+			for _, addr := range ApplyRemoves(test.Stmts, test.State) {
+				gotRemoved = append(gotRemoved, addr.String())
+			}
+			if diff := cmp.Diff(test.WantRemoved, gotRemoved); diff != "" {
+				t.Errorf("wrong removed addresses\n%s", diff)
+			}
+
+			gotAddrs := allResourceInstanceAddrsInState(test.State)
+			if diff := cmp.Diff(test.WantInstanceAddrs, gotAddrs); diff != "" {
+				t.Errorf("wrong resource instances in final state\n%s", diff)
+			}
+
+			for _, ms := range test.State.Modules {
+				if !ms.Addr.IsRoot() && len(ms.Resources) == 0 {
+					t.Errorf("empty module %s was not pruned", ms.Addr)
+				}
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ package remote
 
 import (
 	"context"
+	"time"
 
 	"github.com/opentofu/opentofu/internal/states/statemgr"
 )
@@ -43,6 +44,33 @@ type OptionalClientLocker interface {
 	IsLockingEnabled() bool
 }
 
+// ClientVersioner is an optional interface that allows a remote state
+// backend whose storage retains earlier versions of the state, such as a
+// versioned bucket, to expose them for inspection and rollback.
+type ClientVersioner interface {
+	Client
+
+	// Versions returns the versions of the state that are retained in
+	// storage, newest first. The current version is included. At most limit
+	// versions are returned, or all of them if limit is zero.
+	Versions(ctx context.Context, limit int) ([]*PayloadVersion, error)
+
+	// GetVersion returns the state data of the version with the given ID,
+	// or nil if there is no such version.
+	GetVersion(ctx context.Context, id string) (*Payload, error)
+}
+
+// PayloadVersion describes a single version of the state retained by a
+// ClientVersioner.
+type PayloadVersion struct {
+	// ID is the storage-specific identifier of the version, such as an
+	// object version ID or generation number.
+	ID string
+
+	// Time is when the version was written.
+	Time time.Time
+}
+
 // Payload is the return value from the remote state storage.
 type Payload struct {
 	MD5  []byte
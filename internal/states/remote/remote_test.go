@@ -134,3 +134,30 @@ func (c *mockClientForcePusher) appendLog(method string, content []byte) {
 	}
 	c.log = append(c.log, mockClientRequest{method, contentVal})
 }
+
+// mockClientVersioner is like nilClient, but also implements
+// ClientVersioner by serving the given versions, which are newest first.
+type mockClientVersioner struct {
+	nilClient
+	versions []*PayloadVersion
+	data     map[string][]byte
+
+	// fetched records the IDs passed to GetVersion, in order.
+	fetched []string
+}
+
+func (c *mockClientVersioner) Versions(_ context.Context, limit int) ([]*PayloadVersion, error) {
+	if limit > 0 && len(c.versions) > limit {
+		return c.versions[:limit], nil
+	}
+	return c.versions, nil
+}
+
+func (c *mockClientVersioner) GetVersion(_ context.Context, id string) (*Payload, error) {
+	c.fetched = append(c.fetched, id)
+	data, ok := c.data[id]
+	if !ok {
+		return nil, nil
+	}
+	return &Payload{Data: data}, nil
+}
@@ -57,6 +57,8 @@ type State struct {
 var _ statemgr.Full = (*State)(nil)
 var _ statemgr.Migrator = (*State)(nil)
 var _ statemgr.PersistentMeta = (*State)(nil)
+var _ statemgr.SelectiveOutputReader = (*State)(nil)
+var _ statemgr.History = (*State)(nil)
 var _ local.IntermediateStateConditionalPersister = (*State)(nil)
 
 func NewState(client Client, enc encryption.StateEncryption) *State {
@@ -91,6 +93,119 @@ func (s *State) GetRootOutputValues(ctx context.Context) (map[string]*states.Out
 	return state.RootModule().OutputValues, nil
 }
 
+// GetRootOutputValuesByName is part of our implementation of
+// statemgr.SelectiveOutputReader.
+func (s *State) GetRootOutputValuesByName(ctx context.Context, names []string) (map[string]*states.OutputValue, error) {
+	payload, err := s.Client.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load state: %w", err)
+	}
+	if payload == nil {
+		return nil, statefile.ErrNoState
+	}
+	return statefile.ReadRootOutputs(bytes.NewReader(payload.Data), s.encryption, names)
+}
+
+// StateHistory is part of our implementation of statemgr.History. It
+// returns statemgr.ErrHistoryNotSupported unless the client implements
+// ClientVersioner.
+func (s *State) StateHistory(ctx context.Context, limit int) ([]*statemgr.SnapshotVersion, error) {
+	versioner, ok := s.Client.(ClientVersioner)
+	if !ok {
+		return nil, statemgr.ErrHistoryNotSupported
+	}
+
+	versions, err := versioner.Versions(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list state versions: %w", err)
+	}
+
+	// The lineage and serial are only recorded inside the snapshots, so we
+	// must fetch every version to report them. This is why the number of
+	// versions is limited by the client, before any are fetched.
+	var ret []*statemgr.SnapshotVersion
+	for _, version := range versions {
+		stateFile, err := s.stateVersion(ctx, versioner, version.ID)
+		if err != nil {
+			log.Printf("[WARN] states/remote: ignoring state version %s that cannot be read: %s", version.ID, err)
+			continue
+		}
+		if stateFile == nil {
+			continue
+		}
+		ret = append(ret, &statemgr.SnapshotVersion{
+			ID:      version.ID,
+			Lineage: stateFile.Lineage,
+			Serial:  stateFile.Serial,
+			Time:    version.Time,
+		})
+	}
+	return ret, nil
+}
+
+// StateVersion is part of our implementation of statemgr.History.
+func (s *State) StateVersion(ctx context.Context, id string) (*statefile.File, error) {
+	versioner, ok := s.Client.(ClientVersioner)
+	if !ok {
+		return nil, statemgr.ErrHistoryNotSupported
+	}
+	stateFile, err := s.stateVersion(ctx, versioner, id)
+	if err != nil {
+		return nil, err
+	}
+	if stateFile == nil {
+		return nil, fmt.Errorf("no state version %q", id)
+	}
+	return stateFile, nil
+}
+
+// FindStateVersion is part of our implementation of statemgr.History. Only
+// the list of versions is requested up front, and the versions are then
+// fetched one at a time until one matches.
+func (s *State) FindStateVersion(ctx context.Context, lineage string, serial uint64) (*statemgr.SnapshotVersion, *statefile.File, error) {
+	versioner, ok := s.Client.(ClientVersioner)
+	if !ok {
+		return nil, nil, statemgr.ErrHistoryNotSupported
+	}
+
+	versions, err := versioner.Versions(ctx, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to list state versions: %w", err)
+	}
+	for _, version := range versions {
+		stateFile, err := s.stateVersion(ctx, versioner, version.ID)
+		if err != nil {
+			log.Printf("[WARN] states/remote: ignoring state version %s that cannot be read: %s", version.ID, err)
+			continue
+		}
+		if stateFile == nil || stateFile.Serial != serial || (lineage != "" && stateFile.Lineage != lineage) {
+			continue
+		}
+		return &statemgr.SnapshotVersion{
+			ID:      version.ID,
+			Lineage: stateFile.Lineage,
+			Serial:  stateFile.Serial,
+			Time:    version.Time,
+		}, stateFile, nil
+	}
+	return nil, nil, nil
+}
+
+func (s *State) stateVersion(ctx context.Context, versioner ClientVersioner, id string) (*statefile.File, error) {
+	payload, err := versioner.GetVersion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		return nil, nil
+	}
+	stateFile, err := statefile.Read(bytes.NewReader(payload.Data), s.encryption)
+	if err == statefile.ErrNoState {
+		return nil, nil
+	}
+	return stateFile, err
+}
+
 // StateForMigration is part of our implementation of statemgr.Migrator.
 func (s *State) StateForMigration() *statefile.File {
 	s.mu.Lock()
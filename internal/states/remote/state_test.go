@@ -7,9 +7,11 @@ package remote
 
 import (
 	"context"
+	"errors"
 	"log"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -423,6 +425,119 @@ func TestState_GetRootOutputValues(t *testing.T) {
 	}
 }
 
+func TestState_history(t *testing.T) {
+	now := time.Now()
+	mgr := NewState(
+		&mockClientVersioner{
+			versions: []*PayloadVersion{
+				{ID: "v3", Time: now},
+				{ID: "v2", Time: now.Add(-time.Minute)},
+				{ID: "v1", Time: now.Add(-2 * time.Minute)},
+			},
+			data: map[string][]byte{
+				"v3": []byte(`{"version": 4, "lineage": "mock-lineage", "serial": 3, "terraform_version": "0.0.0", "outputs": {}, "resources": []}`),
+				"v2": []byte(`not a state`),
+				"v1": []byte(`{"version": 4, "lineage": "mock-lineage", "serial": 1, "terraform_version": "0.0.0", "outputs": {}, "resources": []}`),
+			},
+		},
+		encryption.StateEncryptionDisabled(),
+	)
+
+	history, err := mgr.StateHistory(t.Context(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*statemgr.SnapshotVersion{
+		{ID: "v3", Lineage: "mock-lineage", Serial: 3, Time: now},
+		{ID: "v1", Lineage: "mock-lineage", Serial: 1, Time: now.Add(-2 * time.Minute)},
+	}
+	if diff := cmp.Diff(want, history); diff != "" {
+		t.Errorf("wrong history\n%s", diff)
+	}
+
+	// The limit applies to the versions in storage, including any that
+	// can't be read.
+	history, err = mgr.StateHistory(t.Context(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want[:1], history); diff != "" {
+		t.Errorf("wrong limited history\n%s", diff)
+	}
+
+	f, err := mgr.StateVersion(t.Context(), "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Serial != 1 {
+		t.Errorf("wrong serial %d", f.Serial)
+	}
+	if _, err := mgr.StateVersion(t.Context(), "v4"); err == nil {
+		t.Error("expected error for a missing version")
+	}
+
+	unversioned := NewState(nilClient{}, encryption.StateEncryptionDisabled())
+	if _, err := unversioned.StateHistory(t.Context(), 0); !errors.Is(err, statemgr.ErrHistoryNotSupported) {
+		t.Errorf("wrong error for a client without versions: %v", err)
+	}
+}
+
+func TestState_findStateVersion(t *testing.T) {
+	now := time.Now()
+	client := &mockClientVersioner{
+		versions: []*PayloadVersion{
+			{ID: "v4", Time: now},
+			{ID: "v3", Time: now.Add(-time.Minute)},
+			{ID: "v2", Time: now.Add(-2 * time.Minute)},
+			{ID: "v1", Time: now.Add(-3 * time.Minute)},
+		},
+		data: map[string][]byte{
+			"v4": []byte(`{"version": 4, "lineage": "mock-lineage", "serial": 3, "terraform_version": "0.0.0", "outputs": {}, "resources": []}`),
+			"v3": []byte(`{"version": 4, "lineage": "other-lineage", "serial": 2, "terraform_version": "0.0.0", "outputs": {}, "resources": []}`),
+			"v2": []byte(`{"version": 4, "lineage": "mock-lineage", "serial": 2, "terraform_version": "0.0.0", "outputs": {}, "resources": []}`),
+			"v1": []byte(`{"version": 4, "lineage": "mock-lineage", "serial": 1, "terraform_version": "0.0.0", "outputs": {}, "resources": []}`),
+		},
+	}
+	mgr := NewState(client, encryption.StateEncryptionDisabled())
+
+	v, f, err := mgr.FindStateVersion(t.Context(), "mock-lineage", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &statemgr.SnapshotVersion{ID: "v2", Lineage: "mock-lineage", Serial: 2, Time: now.Add(-2 * time.Minute)}
+	if diff := cmp.Diff(want, v); diff != "" {
+		t.Errorf("wrong version\n%s", diff)
+	}
+	if f == nil || f.Serial != 2 || f.Lineage != "mock-lineage" {
+		t.Errorf("wrong state file %#v", f)
+	}
+	// The versions older than the match must not be fetched.
+	if diff := cmp.Diff([]string{"v4", "v3", "v2"}, client.fetched); diff != "" {
+		t.Errorf("wrong versions fetched\n%s", diff)
+	}
+
+	v, _, err = mgr.FindStateVersion(t.Context(), "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v == nil || v.ID != "v3" {
+		t.Errorf("wrong version for any lineage %#v", v)
+	}
+
+	v, f, err = mgr.FindStateVersion(t.Context(), "mock-lineage", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != nil || f != nil {
+		t.Errorf("unexpected version %#v", v)
+	}
+
+	unversioned := NewState(nilClient{}, encryption.StateEncryptionDisabled())
+	if _, _, err := unversioned.FindStateVersion(t.Context(), "", 1); !errors.Is(err, statemgr.ErrHistoryNotSupported) {
+		t.Errorf("wrong error for a client without versions: %v", err)
+	}
+}
+
 type migrationTestCase struct {
 	name string
 	// A function to generate a statefile
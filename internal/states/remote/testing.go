@@ -50,6 +50,56 @@ func TestClient(t *testing.T, c Client) {
 	}
 }
 
+// TestClientVersions is a generic function to test any client that
+// implements ClientVersioner, using storage that retains earlier versions.
+func TestClientVersions(t *testing.T, c ClientVersioner) {
+	var versions [][]byte
+	for serial := uint64(1); serial <= 2; serial++ {
+		var buf bytes.Buffer
+		sf := statefile.New(statemgr.TestFullInitialState(), "stub-lineage", serial)
+		if err := statefile.Write(sf, &buf, encryption.StateEncryptionDisabled()); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if err := c.Put(t.Context(), buf.Bytes()); err != nil {
+			t.Fatalf("put: %s", err)
+		}
+		versions = append(versions, buf.Bytes())
+	}
+
+	got, err := c.Versions(t.Context(), 0)
+	if err != nil {
+		t.Fatalf("versions: %s", err)
+	}
+	if len(got) < 2 {
+		t.Fatalf("expected at least 2 versions, got %d", len(got))
+	}
+
+	limited, err := c.Versions(t.Context(), 1)
+	if err != nil {
+		t.Fatalf("versions: %s", err)
+	}
+	if len(limited) != 1 || limited[0].ID != got[0].ID {
+		t.Fatalf("wrong limited versions: %v", limited)
+	}
+	if got[0].Time.Before(got[1].Time) {
+		t.Fatalf("versions are not newest first: %s is before %s", got[0].Time, got[1].Time)
+	}
+
+	for i, want := range []string{string(versions[1]), string(versions[0])} {
+		p, err := c.GetVersion(t.Context(), got[i].ID)
+		if err != nil {
+			t.Fatalf("get version %s: %s", got[i].ID, err)
+		}
+		if p == nil || string(p.Data) != want {
+			t.Fatalf("wrong data for version %s\nwant: %q", got[i].ID, want)
+		}
+	}
+
+	if err := c.Delete(t.Context()); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+}
+
 // Test the lock implementation for a remote.Client.
 // This test requires 2 client instances, in order to have multiple remote
 // clients since some implementations may tie the client to the lock, or may
@@ -11,10 +11,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 
 	version "github.com/hashicorp/go-version"
 
 	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	tfversion "github.com/opentofu/opentofu/version"
 )
@@ -51,10 +53,95 @@ func (e *ErrUnusableState) Unwrap() error {
 // Otherwise, the returned error might be a wrapper around tfdiags.Diagnostics
 // potentially describing multiple errors.
 func Read(r io.Reader, enc encryption.StateEncryption) (*File, error) {
+	decrypted, status, err := readDecrypted(r, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := readState(decrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	if state == nil {
+		// Should never happen
+		panic("readState returned nil state with no errors")
+	}
+
+	state.EncryptionStatus = status
+
+	return state, nil
+}
+
+// ReadRootOutputs reads the root module output values with the given names
+// from a state in the given reader. Names that are not present in the state
+// are ignored, and a nil names slice selects all of the root module outputs.
+//
+// For the current state format version only the selected outputs are decoded,
+// which makes this much cheaper than Read for callers that need just a few
+// outputs from a large state. Legacy state format versions are fully decoded
+// before selecting the outputs.
+//
+// If the state file is empty, the special error value ErrNoState is returned.
+func ReadRootOutputs(r io.Reader, enc encryption.StateEncryption, names []string) (map[string]*states.OutputValue, error) {
+	decrypted, _, err := readDecrypted(r, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Anything other than the current format version, including invalid
+	// state files, takes the full decoding path so that it's upgraded or
+	// reported in the same way as for Read.
+	version, diags := sniffJSONStateVersion(decrypted)
+	if looksLikeVersion0(decrypted) || diags.HasErrors() || version != 4 {
+		state, err := readState(decrypted)
+		if err != nil {
+			return nil, err
+		}
+		ret := make(map[string]*states.OutputValue)
+		for name, output := range state.State.RootModule().OutputValues {
+			if names == nil || slices.Contains(names, name) {
+				ret[name] = output
+			}
+		}
+		return ret, nil
+	}
+
+	// Only the outputs are unmarshalled here, so the potentially-large
+	// resource instance objects are skipped over by the JSON decoder.
+	var sV4 struct {
+		RootOutputs map[string]outputStateV4 `json:"outputs"`
+	}
+	if err := json.Unmarshal(decrypted, &sV4); err != nil {
+		diags = diags.Append(jsonUnmarshalDiags(err))
+		return nil, errUnusable(diags.Err())
+	}
+
+	ret := make(map[string]*states.OutputValue)
+	for name, fos := range sV4.RootOutputs {
+		if names != nil && !slices.Contains(names, name) {
+			continue
+		}
+		output, outputDiags := decodeOutputStateV4(name, fos)
+		diags = diags.Append(outputDiags)
+		if outputDiags.HasErrors() {
+			continue
+		}
+		ret[name] = output
+	}
+	if diags.HasErrors() {
+		return nil, errUnusable(diags.Err())
+	}
+	return ret, nil
+}
+
+// readDecrypted reads the whole of the given reader and decrypts it, returning
+// the plaintext state file source.
+func readDecrypted(r io.Reader, enc encryption.StateEncryption) ([]byte, encryption.EncryptionStatus, error) {
 	// Some callers provide us a "typed nil" *os.File here, which would
 	// cause us to panic below if we tried to use it.
 	if f, ok := r.(*os.File); ok && f == nil {
-		return nil, ErrNoState
+		return nil, encryption.StatusUnknown, ErrNoState
 	}
 
 	var diags tfdiags.Diagnostics
@@ -69,31 +156,14 @@ func Read(r io.Reader, enc encryption.StateEncryption) (*File, error) {
 			"Failed to read state file",
 			fmt.Sprintf("The state file could not be read: %s", err),
 		))
-		return nil, diags.Err()
+		return nil, encryption.StatusUnknown, diags.Err()
 	}
 
 	if len(src) == 0 {
-		return nil, ErrNoState
+		return nil, encryption.StatusUnknown, ErrNoState
 	}
 
-	decrypted, status, err := enc.DecryptState(src)
-	if err != nil {
-		return nil, err
-	}
-
-	state, err := readState(decrypted)
-	if err != nil {
-		return nil, err
-	}
-
-	if state == nil {
-		// Should never happen
-		panic("readState returned nil state with no errors")
-	}
-
-	state.EncryptionStatus = status
-
-	return state, diags.Err()
+	return enc.DecryptState(src)
 }
 
 func readState(src []byte) (*File, error) {
@@ -8,8 +8,11 @@ import (
 	"os"
 	"testing"
 
+	"github.com/zclconf/go-cty/cty"
+
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/encryption/enctest"
+	"github.com/opentofu/opentofu/internal/states"
 )
 
 func TestReadErrNoState_emptyFile(t *testing.T) {
@@ -53,3 +56,40 @@ func TestReadEmptyJsonWithEncryption(t *testing.T) {
 		t.Fatalf("expected encryption error, got %v", err)
 	}
 }
+
+func TestReadRootOutputs(t *testing.T) {
+	state := states.NewState()
+	root := state.RootModule()
+	root.SetOutputValue("foo", cty.StringVal("bar"), false, "")
+	root.SetOutputValue("secret", cty.NumberIntVal(1), true, "")
+	root.SetOutputValue("list", cty.ListVal([]cty.Value{cty.True}), false, "")
+
+	var buf bytes.Buffer
+	if err := Write(New(state, "lineage", 1), &buf, encryption.StateEncryptionDisabled()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadRootOutputs(bytes.NewReader(buf.Bytes()), encryption.StateEncryptionDisabled(), []string{"secret", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 output, got %d", len(got))
+	}
+	if output := got["secret"]; output == nil || !output.Sensitive || !output.Value.RawEquals(cty.NumberIntVal(1)) {
+		t.Errorf("wrong output %#v", output)
+	}
+
+	got, err = ReadRootOutputs(bytes.NewReader(buf.Bytes()), encryption.StateEncryptionDisabled(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 outputs, got %d", len(got))
+	}
+
+	_, err = ReadRootOutputs(bytes.NewBufferString(""), encryption.StateEncryptionDisabled(), nil)
+	if !errors.Is(err, ErrNoState) {
+		t.Fatalf("expected ErrNoState, got %T", err)
+	}
+}
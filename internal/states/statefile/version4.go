@@ -311,38 +311,11 @@ func prepareStateV4(sV4 *stateV4) (*File, tfdiags.Diagnostics) {
 	{
 		rootModule := state.RootModule()
 		for name, fos := range sV4.RootOutputs {
-			os := &states.OutputValue{
-				Addr: addrs.AbsOutputValue{
-					OutputValue: addrs.OutputValue{
-						Name: name,
-					},
-				},
-			}
-			os.Sensitive = fos.Sensitive
-
-			os.Deprecated = fos.Deprecated
-
-			ty, err := ctyjson.UnmarshalType([]byte(fos.ValueTypeRaw))
-			if err != nil {
-				diags = diags.Append(tfdiags.Sourceless(
-					tfdiags.Error,
-					"Invalid output value type in state",
-					fmt.Sprintf("The state file has an invalid type specification for output %q: %s.", name, err),
-				))
+			os, osDiags := decodeOutputStateV4(name, fos)
+			diags = diags.Append(osDiags)
+			if osDiags.HasErrors() {
 				continue
 			}
-
-			val, err := ctyjson.Unmarshal([]byte(fos.ValueRaw), ty)
-			if err != nil {
-				diags = diags.Append(tfdiags.Sourceless(
-					tfdiags.Error,
-					"Invalid output value saved in state",
-					fmt.Sprintf("The state file has an invalid value for output %q: %s.", name, err),
-				))
-				continue
-			}
-
-			os.Value = val
 			rootModule.OutputValues[name] = os
 		}
 	}
@@ -772,6 +745,46 @@ func (s *stateV4) normalize() {
 	}
 }
 
+// decodeOutputStateV4 decodes the root module output value with the given
+// name from its representation in the state file.
+func decodeOutputStateV4(name string, fos outputStateV4) (*states.OutputValue, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	os := &states.OutputValue{
+		Addr: addrs.AbsOutputValue{
+			OutputValue: addrs.OutputValue{
+				Name: name,
+			},
+		},
+	}
+	os.Sensitive = fos.Sensitive
+
+	os.Deprecated = fos.Deprecated
+
+	ty, err := ctyjson.UnmarshalType([]byte(fos.ValueTypeRaw))
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid output value type in state",
+			fmt.Sprintf("The state file has an invalid type specification for output %q: %s.", name, err),
+		))
+		return nil, diags
+	}
+
+	val, err := ctyjson.Unmarshal([]byte(fos.ValueRaw), ty)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid output value saved in state",
+			fmt.Sprintf("The state file has an invalid value for output %q: %s.", name, err),
+		))
+		return nil, diags
+	}
+
+	os.Value = val
+	return os, diags
+}
+
 type outputStateV4 struct {
 	ValueRaw     json.RawMessage `json:"value"`
 	ValueTypeRaw json.RawMessage `json:"type"`
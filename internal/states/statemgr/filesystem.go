@@ -14,6 +14,9 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -68,9 +71,11 @@ type Filesystem struct {
 }
 
 var (
-	_ Full           = (*Filesystem)(nil)
-	_ PersistentMeta = (*Filesystem)(nil)
-	_ Migrator       = (*Filesystem)(nil)
+	_ Full                  = (*Filesystem)(nil)
+	_ PersistentMeta        = (*Filesystem)(nil)
+	_ Migrator              = (*Filesystem)(nil)
+	_ SelectiveOutputReader = (*Filesystem)(nil)
+	_ History               = (*Filesystem)(nil)
 )
 
 // NewFilesystem creates a filesystem-based state manager that reads and writes
@@ -297,6 +302,175 @@ func (s *Filesystem) GetRootOutputValues(ctx context.Context) (map[string]*state
 	return state.RootModule().OutputValues, nil
 }
 
+// GetRootOutputValuesByName is part of our implementation of
+// SelectiveOutputReader.
+func (s *Filesystem) GetRootOutputValuesByName(_ context.Context, names []string) (map[string]*states.OutputValue, error) {
+	defer s.mutex()()
+
+	// Once a snapshot has been read or written we must use it, as for
+	// refreshState, but until then we can read the outputs directly from
+	// the initial snapshot file.
+	if s.file != nil || s.stateFileOut != nil {
+		if err := s.refreshState(); err != nil {
+			return nil, err
+		}
+		if s.file == nil {
+			return nil, statefile.ErrNoState
+		}
+		ret := make(map[string]*states.OutputValue)
+		for name, output := range s.file.State.RootModule().OutputValues {
+			if names == nil || slices.Contains(names, name) {
+				ret[name] = output
+			}
+		}
+		return ret, nil
+	}
+
+	f, err := os.Open(s.readPath)
+	if os.IsNotExist(err) {
+		return nil, statefile.ErrNoState
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return statefile.ReadRootOutputs(f, s.encryption, names)
+}
+
+// StateHistory is part of our implementation of History, returning the
+// snapshot at the state path along with any backups of it that are still on
+// disk: the configured backup path and any other files in the same directory
+// that are named after the state file with a ".backup" suffix, such as the
+// timestamped backups created by the state commands.
+func (s *Filesystem) StateHistory(_ context.Context, limit int) ([]*SnapshotVersion, error) {
+	defer s.mutex()()
+
+	paths, err := s.historyPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*SnapshotVersion
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := s.readHistoryFile(path)
+		if err != nil {
+			log.Printf("[WARN] statemgr.Filesystem: ignoring snapshot %s that cannot be read: %s", path, err)
+			continue
+		}
+		if f == nil {
+			continue
+		}
+		ret = append(ret, &SnapshotVersion{
+			ID:      path,
+			Lineage: f.Lineage,
+			Serial:  f.Serial,
+			Time:    info.ModTime(),
+		})
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		if !ret[i].Time.Equal(ret[j].Time) {
+			return ret[i].Time.After(ret[j].Time)
+		}
+		return ret[i].Serial > ret[j].Serial
+	})
+	if limit > 0 && len(ret) > limit {
+		ret = ret[:limit]
+	}
+	return ret, nil
+}
+
+// StateVersion is part of our implementation of History. The ID is the path
+// of one of the files returned by StateHistory.
+func (s *Filesystem) StateVersion(_ context.Context, id string) (*statefile.File, error) {
+	defer s.mutex()()
+
+	paths, err := s.historyPaths()
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(paths, id) {
+		return nil, fmt.Errorf("no state snapshot %q", id)
+	}
+	return s.readHistoryFile(id)
+}
+
+// FindStateVersion is part of our implementation of History. The snapshots
+// are local files, so they're all read to order them as StateHistory does.
+func (s *Filesystem) FindStateVersion(ctx context.Context, lineage string, serial uint64) (*SnapshotVersion, *statefile.File, error) {
+	versions, err := s.StateHistory(ctx, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := FindSnapshotVersion(versions, lineage, serial)
+	if v == nil {
+		return nil, nil, nil
+	}
+	f, err := s.StateVersion(ctx, v.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, f, nil
+}
+
+// historyPaths returns the paths of the existing files that StateHistory
+// considers.
+func (s *Filesystem) historyPaths() ([]string, error) {
+	dir, base := filepath.Split(s.path)
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		if name == base || (strings.HasPrefix(name, base+".") && strings.HasSuffix(name, ".backup")) {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	if s.backupPath != "" && !slices.Contains(paths, filepath.Clean(s.backupPath)) {
+		if _, err := os.Stat(s.backupPath); err == nil {
+			paths = append(paths, filepath.Clean(s.backupPath))
+		}
+	}
+	return paths, nil
+}
+
+// readHistoryFile reads one of the files returned by historyPaths, returning
+// nil if it is empty.
+func (s *Filesystem) readHistoryFile(path string) (*statefile.File, error) {
+	var reader io.Reader
+	if filepath.Clean(path) == filepath.Clean(s.path) && s.stateFileOut != nil {
+		// The state file may be locked, in which case we must read it through
+		// the handle we already have open.
+		if _, err := s.stateFileOut.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		reader = s.stateFileOut
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	f, err := statefile.Read(reader, s.encryption)
+	if err == statefile.ErrNoState {
+		return nil, nil
+	}
+	return f, err
+}
+
 func (s *Filesystem) refreshState() error {
 	var reader io.Reader
 
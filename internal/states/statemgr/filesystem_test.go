@@ -6,12 +6,14 @@
 package statemgr
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	version "github.com/hashicorp/go-version"
@@ -446,6 +448,90 @@ func TestFilesystem_GetRootOutputValues(t *testing.T) {
 	}
 }
 
+func TestFilesystem_history(t *testing.T) {
+	workDir := t.TempDir()
+	statePath := filepath.Join(workDir, "terraform.tfstate")
+	now := time.Now()
+
+	writeSnapshot := func(name string, serial uint64, age time.Duration) {
+		t.Helper()
+		path := filepath.Join(workDir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = statefile.Write(statefile.New(states.NewState(), "lineage", serial), f, encryption.StateEncryptionDisabled())
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeSnapshot("terraform.tfstate", 3, 0)
+	writeSnapshot("terraform.tfstate.backup", 2, time.Hour)
+	writeSnapshot("terraform.tfstate.1700000000.backup", 1, 2*time.Hour)
+	writeSnapshot("other.tfstate", 7, 0)
+	if err := os.WriteFile(filepath.Join(workDir, "terraform.tfstate.1700000001.backup"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := NewFilesystem(statePath, encryption.StateEncryptionDisabled())
+	history, err := fs.StateHistory(t.Context(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limited, err := fs.StateHistory(t.Context(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limited) != 1 || limited[0].Serial != 3 {
+		t.Errorf("wrong limited history %v", limited)
+	}
+
+	var got []string
+	for _, v := range history {
+		got = append(got, fmt.Sprintf("%s %s %d", filepath.Base(v.ID), v.Lineage, v.Serial))
+	}
+	want := []string{
+		"terraform.tfstate lineage 3",
+		"terraform.tfstate.backup lineage 2",
+		"terraform.tfstate.1700000000.backup lineage 1",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong history\n%s", diff)
+	}
+
+	v := FindSnapshotVersion(history, "lineage", 1)
+	if v == nil {
+		t.Fatal("serial 1 not found")
+	}
+	f, err := fs.StateVersion(t.Context(), v.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Serial != 1 {
+		t.Errorf("wrong serial %d", f.Serial)
+	}
+
+	if _, err := fs.StateVersion(t.Context(), filepath.Join(workDir, "other.tfstate")); err == nil {
+		t.Error("expected error reading a file that is not part of the history")
+	}
+
+	v, f, err = fs.FindStateVersion(t.Context(), "lineage", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v == nil || filepath.Base(v.ID) != "terraform.tfstate.backup" || f == nil || f.Serial != 2 {
+		t.Errorf("wrong snapshot for serial 2: %#v", v)
+	}
+	if v, _, err := fs.FindStateVersion(t.Context(), "other-lineage", 2); err != nil || v != nil {
+		t.Errorf("unexpected snapshot in another lineage: %#v, %v", v, err)
+	}
+}
+
 func testOverrideVersion(t *testing.T, v string) func() {
 	oldVersionStr := tfversion.Version
 	oldPrereleaseStr := tfversion.Prerelease
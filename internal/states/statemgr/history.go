@@ -0,0 +1,71 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package statemgr
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/states/statefile"
+)
+
+// ErrHistoryNotSupported is returned by the methods of History when the
+// storage behind a manager that implements the interface turns out not to
+// retain earlier snapshots, such as a remote backend without versioning.
+var ErrHistoryNotSupported = errors.New("the state storage does not retain earlier state snapshots")
+
+// History is an optional interface for managers whose persistent storage
+// retains earlier state snapshots, such as backup files or a versioned
+// object store, allowing them to be inspected and restored.
+type History interface {
+	// StateHistory returns the snapshots that are retained in storage,
+	// newest first. The latest persistent snapshot is included. At most limit
+	// snapshots are returned, or all of them if limit is zero.
+	//
+	// Snapshots that cannot be decoded, for example because they were
+	// encrypted with a different key, are omitted, so fewer than limit
+	// snapshots may be returned even if more are retained.
+	StateHistory(ctx context.Context, limit int) ([]*SnapshotVersion, error)
+
+	// StateVersion returns the snapshot with the given version ID, as
+	// returned in the ID field of a SnapshotVersion by StateHistory.
+	StateVersion(ctx context.Context, id string) (*statefile.File, error)
+
+	// FindStateVersion returns the newest retained snapshot with the given
+	// lineage and serial along with its content, or nil if there is none. An
+	// empty lineage matches snapshots of any lineage.
+	//
+	// Unlike StateHistory, the snapshots are only read, newest first, until
+	// one matches, so that finding a recent snapshot doesn't require reading
+	// every snapshot that is retained.
+	FindStateVersion(ctx context.Context, lineage string, serial uint64) (*SnapshotVersion, *statefile.File, error)
+}
+
+// SnapshotVersion describes a single snapshot retained by a History.
+type SnapshotVersion struct {
+	// ID identifies the snapshot within the storage. Its format depends on
+	// the storage and it is only meaningful to the manager that returned it.
+	ID string
+
+	// Lineage and Serial are the values recorded in the snapshot itself,
+	// with the same meaning as in SnapshotMeta.
+	Lineage string
+	Serial  uint64
+
+	// Time is when the snapshot was written to storage.
+	Time time.Time
+}
+
+// FindSnapshotVersion returns the newest of the given snapshots that has the
+// given lineage and serial, or nil if there is none. An empty lineage matches
+// snapshots of any lineage.
+func FindSnapshotVersion(versions []*SnapshotVersion, lineage string, serial uint64) *SnapshotVersion {
+	for _, v := range versions {
+		if v.Serial == serial && (lineage == "" || v.Lineage == lineage) {
+			return v
+		}
+	}
+	return nil
+}
@@ -38,6 +38,19 @@ type OutputReader interface {
 	GetRootOutputValues(context.Context) (map[string]*states.OutputValue, error)
 }
 
+// SelectiveOutputReader is an optional interface for managers that can fetch
+// only some of the root module output values without decoding the rest of the
+// latest state snapshot, which is much cheaper for large states.
+//
+// Unlike RefreshState, fetching outputs this way doesn't change the snapshot
+// returned by the manager's State method.
+type SelectiveOutputReader interface {
+	// GetRootOutputValuesByName fetches the root module output values with
+	// the given names, ignoring any that are not present. If there is no
+	// state snapshot at all then the error is statefile.ErrNoState.
+	GetRootOutputValuesByName(ctx context.Context, names []string) (map[string]*states.OutputValue, error)
+}
+
 // Refresher is the interface for managers that can read snapshots from
 // persistent storage.
 //